@@ -17,7 +17,7 @@ _fabric() {
    fi
 
   # Define all possible options/flags
-  local opts="--pattern -p --variable -v --context -C --session --attachment -a --setup -S --temperature -t --topp -T --stream -s --presencepenalty -P --raw -r --frequencypenalty -F --listpatterns -l --readpattern --listmodels -L --listcontexts -x --listsessions -X --updatepatterns -U --copy -c --model -m --vendor -V --modelContextLength --output -o --output-session --latest -n --changeDefaultModel -d --youtube -y --playlist --transcript --transcript-with-timestamps --visual --visual-sensitivity --visual-fps --comments --metadata --yt-dlp-args --language -g --scrape_url -u --scrape_question -q --seed -e --thinking --wipecontext -w --wipesession -W --printcontext --printsession --readability --input-has-vars --no-variable-replacement --dry-run --serve --serveOllama --address --api-key --config --search --search-location --image-file --image-size --image-quality --image-compression --image-background --suppress-think --think-start-tag --think-end-tag --disable-responses-api --transcribe-file --transcribe-model --split-media-file --voice --list-gemini-voices --notification --notification-command --debug --version --listextensions --addextension --rmextension --strategy --liststrategies --listvendors --shell-complete-list --help -h"
+  local opts="--pattern -p --variable -v --context -C --session --meta-file --attachment -a --setup -S --temperature -t --topp -T --stream -s --presencepenalty -P --raw -r --frequencypenalty -F --listpatterns -l --tag --search-patterns --pattern-variables --readpattern --listmodels -L --verbose --listcontexts -x --listsessions -X --updatepatterns -U --copy -c --model -m --vendor -V --modelContextLength --output -o --project-root --confirm-changes --output-session --latest -n --changeDefaultModel -d --youtube -y --playlist --transcript --transcript-with-timestamps --visual --visual-sensitivity --visual-fps --comments --metadata --yt-dlp-args --context-separator --language -g --scrape_url -u --scrape_question -q --seed -e --stop --logit-bias --thinking --wipecontext -w --wipesession -W --printcontext --printsession --export-session --export-meta --import-session --name --force --readability --subtitles --input-has-vars --no-variable-replacement --dry-run --cache --cache-ttl --cache-stream --serve --serveOllama --address --api-key --rate-limit --serve-log-bodies --config --search --search-location --search-domains --search-recency --image-file --image-size --image-quality --image-compression --image-background --pattern-temperature-hint --max-output-sentences --max-repeat-chunks --auto-summarize-overflow --overflow-summary-pattern --truncate-context --suppress-think --strip-think --think-start-tag --think-end-tag --developer-role --disable-responses-api --transcribe-file --transcribe-model --split-media-file --voice --list-gemini-voices --notification --notification-command --show-raw-response --dump-raw --stats --output-json --extract --extract-regex --model-fallback --stream-to-file --batch --concurrency --timeout --no-color --debug --version --listextensions --addextension --rmextension --strategy --liststrategies --describe-strategy --listvendors --shell-complete-list --help -h"
 
   # Helper function for dynamic completions
   _fabric_get_list() {
@@ -26,7 +26,7 @@ _fabric() {
 
   # Handle completions based on the previous word
   case "${prev}" in
-  -p | --pattern | --readpattern)
+  -p | --pattern | --readpattern | --pattern-variables | --overflow-summary-pattern)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listpatterns)" -- "${cur}"))
     return 0
     ;;
@@ -58,7 +58,7 @@ _fabric() {
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listcontexts)" -- "${cur}"))
     return 0
     ;;
-  --printsession)
+  --printsession | --export-session)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listsessions)" -- "${cur}"))
     return 0
     ;;
@@ -66,11 +66,19 @@ _fabric() {
     COMPREPLY=($(compgen -W "off low medium high" -- "${cur}"))
     return 0
     ;;
+  --search-recency)
+    COMPREPLY=($(compgen -W "month week day hour" -- "${cur}"))
+    return 0
+    ;;
   --rmextension)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listextensions)" -- "${cur}"))
     return 0
     ;;
-  --strategy)
+  --extract)
+    COMPREPLY=($(compgen -W "code json" -- "${cur}"))
+    return 0
+    ;;
+  --strategy | --describe-strategy)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --liststrategies)" -- "${cur}"))
     return 0
     ;;
@@ -87,7 +95,7 @@ _fabric() {
     return 0
     ;;
   # Options requiring file/directory paths
-  -a | --attachment | -o | --output | --config | --addextension | --image-file | --transcribe-file)
+  -a | --attachment | -o | --output | --project-root | --config | --addextension | --image-file | --transcribe-file | --import-session | --meta-file | --dump-raw)
     _filedir
     return 0
     ;;
@@ -105,7 +113,7 @@ _fabric() {
     return 0
     ;;
   # Options requiring simple arguments (no specific completion logic here)
-  -v | --variable | -t | --temperature | -T | --topp | -P | --presencepenalty | -F | --frequencypenalty | --modelContextLength | -n | --latest | -y | --youtube | --visual-sensitivity | --visual-fps | --yt-dlp-args | -g | --language | -u | --scrape_url | -q | --scrape_question | -e | --seed | --address | --api-key | --search-location | --image-compression | --think-start-tag | --think-end-tag | --notification-command)
+  -v | --variable | -t | --temperature | -T | --topp | -P | --presencepenalty | -F | --frequencypenalty | --modelContextLength | -n | --latest | -y | --youtube | --visual-sensitivity | --visual-fps | --yt-dlp-args | --context-separator | -g | --language | -u | --scrape_url | -q | --scrape_question | -e | --seed | --stop | --logit-bias | --cache-ttl | --address | --api-key | --rate-limit | --search-location | --search-domains | --name | --tag | --search-patterns | --image-compression | --max-output-sentences | --max-repeat-chunks | --think-start-tag | --think-end-tag | --notification-command | --batch | --concurrency | --timeout | --model-fallback | --extract-regex)
     # No specific completion suggestions, user types the value
     return 0
     ;;
@@ -59,10 +59,12 @@ function __fabric_register_completions
 
         # Flag completions with arguments
         complete -c $cmd -s p -l pattern -d "Choose a pattern from the available patterns" -a "(__fabric_get_patterns)"
+        complete -c $cmd -l pattern-variables -d "List the variables the named pattern expects" -a "(__fabric_get_patterns)"
         complete -c $cmd -l readpattern -d "Print the contents of the named pattern to the terminal" -a "(__fabric_get_patterns)"
         complete -c $cmd -s v -l variable -d "Values for pattern variables, e.g. -v=#role:expert -v=#points:30"
         complete -c $cmd -s C -l context -d "Choose a context from the available contexts" -a "(__fabric_get_contexts)"
         complete -c $cmd -l session -d "Choose a session from the available sessions" -a "(__fabric_get_sessions)"
+        complete -c $cmd -l meta-file -d "Read the session meta message from a file" -r
         complete -c $cmd -s a -l attachment -d "Attachment path or URL (e.g. for OpenAI image recognition messages)" -r
         complete -c $cmd -s t -l temperature -d "Set temperature (default: 0.7)"
         complete -c $cmd -s T -l topp -d "Set top P (default: 0.9)"
@@ -72,23 +74,37 @@ function __fabric_register_completions
         complete -c $cmd -s V -l vendor -d "Specify vendor for chosen model (e.g., -V \"LM Studio\" -m openai/gpt-oss-20b)" -a "(__fabric_get_vendors)"
         complete -c $cmd -l modelContextLength -d "Model context length (only affects ollama)"
         complete -c $cmd -s o -l output -d "Output to file" -r
+        complete -c $cmd -l project-root -d "Directory to apply create_coding_feature file changes under (default: current directory)" -a "(__fish_complete_directories)"
+        complete -c $cmd -l confirm-changes -d "Preview create_coding_feature file changes and ask before applying them"
         complete -c $cmd -s n -l latest -d "Number of latest patterns to list (default: 0)"
         complete -c $cmd -s y -l youtube -d "YouTube video or play list URL to grab transcript, comments from it"
         complete -c $cmd -l visual-sensitivity -d "Tolerance for FFmpeg scene detection (0.0 - 1.0)"
         complete -c $cmd -l visual-fps -d "Extract a specific number of frames per second instead of using scene detection"
+        complete -c $cmd -l context-separator -d "Separator between context and pattern in the system prompt" -r
         complete -c $cmd -s g -l language -d "Specify the Language Code for the chat, e.g. -g=en -g=zh"
         complete -c $cmd -s u -l scrape_url -d "Scrape website URL to markdown using Jina AI"
         complete -c $cmd -s q -l scrape_question -d "Search question using Jina AI"
         complete -c $cmd -s e -l seed -d "Seed to be used for LMM generation"
+        complete -c $cmd -l stop -d "Stop generating when the model produces this sequence; repeat for several sequences"
+        complete -c $cmd -l logit-bias -d "Bias an OpenAI token as token_id=value, with value from -100 to 100; repeat for several tokens"
         complete -c $cmd -l thinking -d "Set reasoning/thinking level" -a "off low medium high"
         complete -c $cmd -s w -l wipecontext -d "Wipe context" -a "(__fabric_get_contexts)"
         complete -c $cmd -s W -l wipesession -d "Wipe session" -a "(__fabric_get_sessions)"
         complete -c $cmd -l printcontext -d "Print context" -a "(__fabric_get_contexts)"
         complete -c $cmd -l printsession -d "Print session" -a "(__fabric_get_sessions)"
+        complete -c $cmd -l export-session -d "Export session as a Markdown transcript, written to --output when given" -a "(__fabric_get_sessions)"
+        complete -c $cmd -l export-meta -d "Include meta messages as blockquotes in --export-session output"
+        complete -c $cmd -l import-session -d "Import a session from a JSON file in the saved session format" -r -a "*.json"
+        complete -c $cmd -l name -d "Session name for --import-session (defaults to the file name)"
+        complete -c $cmd -l force -d "Overwrite an existing session with --import-session, or existing outputs with --batch"
         complete -c $cmd -l address -d "The address to bind the REST API (default: :8080)"
         complete -c $cmd -l api-key -d "API key used to secure server routes"
+        complete -c $cmd -l rate-limit -d "Requests per minute the REST API allows from each client IP (0 disables the limit)"
+        complete -c $cmd -l serve-log-bodies -d "With --serve and --debug 2 or higher, log truncated pattern endpoint request and response bodies"
         complete -c $cmd -l config -d "Path to YAML config file" -r -a "*.yaml *.yml"
         complete -c $cmd -l search-location -d "Set location for web search results (e.g., 'America/Los_Angeles')"
+        complete -c $cmd -l search-domains -d "Comma-separated domains to limit Perplexity search to; prefix a domain with '-' to exclude it"
+        complete -c $cmd -l search-recency -d "Limit Perplexity search results to the last month, week, day or hour" -a "month week day hour"
         complete -c $cmd -l image-file -d "Save generated image to specified file path (e.g., 'output.png')" -r -a "*.png *.webp *.jpeg *.jpg"
         complete -c $cmd -l image-size -d "Image dimensions: 1024x1024, 1536x1024, 1024x1536, auto (default: auto)" -a "1024x1024 1536x1024 1024x1536 auto"
         complete -c $cmd -l image-quality -d "Image quality: low, medium, high, auto (default: auto)" -a "low medium high auto"
@@ -102,6 +118,18 @@ function __fabric_register_completions
         complete -c $cmd -l voice -d "TTS voice name for supported models (e.g., Kore, Charon, Puck)" -a "(__fabric_get_gemini_voices)"
         complete -c $cmd -l transcribe-file -d "Audio or video file to transcribe" -r -a "*.mp3 *.mp4 *.mpeg *.mpga *.m4a *.wav *.webm"
         complete -c $cmd -l transcribe-model -d "Model to use for transcription (separate from chat model)" -a "(__fabric_get_transcription_models)"
+        complete -c $cmd -l show-raw-response -d "Print the vendor response body, as received, to stderr"
+        complete -c $cmd -l stats -d "Print the token usage of the request to stderr"
+        complete -c $cmd -l output-json -d "Print the result as a JSON object"
+        complete -c $cmd -l extract -d "Print only part of the response" -a "code json" -r
+        complete -c $cmd -l extract-regex -d "Print only the first capture group of this regular expression" -r
+        complete -c $cmd -l model-fallback -d "Comma-separated models to try when the model is rate-limited or unavailable" -r
+        complete -c $cmd -l dump-raw -d "Append each raw vendor response body, before parsing, to this file" -r
+        complete -c $cmd -l stream-to-file -d "With --stream and --output, write each chunk to the output file as it arrives"
+        complete -c $cmd -l batch -d "Run the pattern over every file matching this glob" -r
+        complete -c $cmd -l concurrency -d "Maximum number of batch inputs processed at once" -r
+        complete -c $cmd -l timeout -d "Fail a vendor request that gets no response within this time (e.g. 30s, 2m)" -r
+        complete -c $cmd -l no-color -d "Disable colored warnings and errors on stderr"
         complete -c $cmd -l debug -d "Set debug level (0=off, 1=basic, 2=detailed, 3=trace, 4=wire)" -a "0 1 2 3 4"
         complete -c $cmd -l notification-command -d "Custom command to run for notifications (overrides built-in notifications)"
 
@@ -110,7 +138,10 @@ function __fabric_register_completions
         complete -c $cmd -s s -l stream -d "Stream"
         complete -c $cmd -s r -l raw -d "Use the defaults of the model without sending chat options. Only affects OpenAI-compatible providers. Anthropic models always use smart parameter selection to comply with model-specific requirements."
         complete -c $cmd -s l -l listpatterns -d "List all patterns"
+        complete -c $cmd -l tag -d "Only list patterns with this frontmatter tag" -r
+        complete -c $cmd -l search-patterns -d "List patterns whose system prompt contains this text" -r
         complete -c $cmd -s L -l listmodels -d "List all available models"
+        complete -c $cmd -l verbose -d "With --listmodels, show models grouped by vendor with their streaming and search support"
         complete -c $cmd -s x -l listcontexts -d "List all contexts"
         complete -c $cmd -s X -l listsessions -d "List all sessions"
         complete -c $cmd -s U -l updatepatterns -d "Update patterns"
@@ -125,19 +156,32 @@ function __fabric_register_completions
         complete -c $cmd -l metadata -d "Output video metadata"
         complete -c $cmd -l yt-dlp-args -d "Additional arguments to pass to yt-dlp (e.g. '--cookies-from-browser brave')"
         complete -c $cmd -l readability -d "Convert HTML input into a clean, readable view"
+        complete -c $cmd -l subtitles -d "Convert SRT or VTT subtitle input into deduplicated plain text"
         complete -c $cmd -l input-has-vars -d "Apply variables to user input"
         complete -c $cmd -l no-variable-replacement -d "Disable pattern variable replacement"
         complete -c $cmd -l dry-run -d "Show what would be sent to the model without actually sending it"
+        complete -c $cmd -l cache -d "Reuse cached responses for identical requests"
+        complete -c $cmd -l cache-ttl -d "How long cached responses stay valid (e.g. 30m, 24h; 0 = never expire)" -r
+        complete -c $cmd -l cache-stream -d "Also use the response cache when streaming"
         complete -c $cmd -l search -d "Enable web search tool for supported models (Anthropic, OpenAI, Gemini)"
         complete -c $cmd -l serve -d "Serve the Fabric Rest API"
         complete -c $cmd -l serveOllama -d "Serve the Fabric Rest API with ollama endpoints"
         complete -c $cmd -l version -d "Print current version"
         complete -c $cmd -l listextensions -d "List all registered extensions"
         complete -c $cmd -l liststrategies -d "List all strategies"
+        complete -c $cmd -l describe-strategy -d "Print the prompt of the named strategy" -a "(__fabric_get_strategies)"
         complete -c $cmd -l listvendors -d "List all vendors"
         complete -c $cmd -l list-gemini-voices -d "List all available Gemini TTS voices"
         complete -c $cmd -l shell-complete-list -d "Output raw list without headers/formatting (for shell completion)"
+        complete -c $cmd -l pattern-temperature-hint -d "Use the temperature suggested by the pattern text unless --temperature is set"
+        complete -c $cmd -l max-output-sentences -d "Truncate the response to at most N sentences (0 = unlimited)" -r
+        complete -c $cmd -l max-repeat-chunks -d "Abort streaming when the same chunk repeats more than N consecutive times (0 = disabled)" -r
+        complete -c $cmd -l auto-summarize-overflow -d "On a context-length error, summarize earlier messages and retry once"
+        complete -c $cmd -l overflow-summary-pattern -d "Pattern used to summarize earlier messages" -a "(__fabric_get_patterns)" -r
+        complete -c $cmd -l truncate-context -d "Drop the oldest messages until the prompt fits the context length"
         complete -c $cmd -l suppress-think -d "Suppress text enclosed in thinking tags"
+        complete -c $cmd -l strip-think -d "Show text enclosed in thinking tags but remove it from the saved session"
+        complete -c $cmd -l developer-role -d "Send the system prompt with the developer role on OpenAI APIs"
         complete -c $cmd -l disable-responses-api -d "Disable OpenAI Responses API (default: false)"
         complete -c $cmd -l split-media-file -d "Split audio/video files larger than 25MB using ffmpeg"
         complete -c $cmd -l notification -d "Send desktop notification when command completes"
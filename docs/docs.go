@@ -24,11 +24,6 @@ const docTemplate = `{
     "paths": {
         "/chat": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
                 "description": "Stream AI responses using Server-Sent Events (SSE)",
                 "consumes": [
                     "application/json"
@@ -67,16 +62,176 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/models/names": {
+        "/chat/stream": {
+            "post": {
+                "description": "Run a single pattern against the given input and stream the response using Server-Sent Events (SSE)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Run a pattern and stream the result",
+                "parameters": [
+                    {
+                        "description": "Pattern, model, input and options",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/restapi.PatternStreamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Streaming response",
+                        "schema": {
+                            "$ref": "#/definitions/restapi.StreamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/contexts": {
+            "get": {
+                "description": "Retrieve the names of all available contexts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contexts"
+                ],
+                "summary": "List contexts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/contexts/{name}": {
             "get": {
+                "description": "Retrieve a context and its content by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contexts"
+                ],
+                "summary": "Get a context",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Context name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/fsdb.Context"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
+                ]
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Report that the server is running. This endpoint does not require an API key.",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/models/names": {
+            "get": {
                 "description": "Get a list of all available AI models grouped by vendor",
                 "produces": [
                     "application/json"
@@ -102,16 +257,16 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/patterns/{name}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/patterns/{name}": {
+            "get": {
                 "description": "Retrieve a pattern by name",
                 "consumes": [
                     "application/json"
@@ -148,16 +303,16 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/patterns/{name}/apply": {
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/patterns/{name}/apply": {
+            "post": {
                 "description": "Apply a pattern with variable substitution",
                 "consumes": [
                     "application/json"
@@ -212,16 +367,122 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/youtube/transcript": {
+        "/patterns/{name}/render": {
             "post": {
+                "description": "Preview the system prompt a pattern produces with the given variables and input, without calling a model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patterns"
+                ],
+                "summary": "Render a pattern",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pattern name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pattern variables and input",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/restapi.PatternApplyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/restapi.PatternRenderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
+                ]
+            }
+        },
+        "/vendors/health": {
+            "get": {
+                "description": "List models from every configured vendor concurrently and report per-vendor status (ok, unconfigured or error) with the elapsed time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vendors"
+                ],
+                "summary": "Check AI vendor health",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Per-vendor timeout as a Go duration (default 5s, max 30s)",
+                        "name": "timeout",
+                        "in": "query"
+                    }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/restapi.VendorsHealthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/youtube/transcript": {
+            "post": {
                 "description": "Retrieves the transcript of a YouTube video along with video metadata (title and description)",
                 "consumes": [
                     "application/json"
@@ -269,11 +530,173 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         }
     },
     "definitions": {
+        "domain.ChatOptions": {
+            "type": "object",
+            "properties": {
+                "audioFormat": {
+                    "type": "string"
+                },
+                "audioOutput": {
+                    "type": "boolean"
+                },
+                "autoSummarizeOnOverflow": {
+                    "type": "boolean"
+                },
+                "frequencyPenalty": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "imageBackground": {
+                    "type": "string"
+                },
+                "imageCompression": {
+                    "type": "integer"
+                },
+                "imageFile": {
+                    "type": "string"
+                },
+                "imageQuality": {
+                    "type": "string"
+                },
+                "imageSize": {
+                    "type": "string"
+                },
+                "maxRepeatChunks": {
+                    "type": "integer"
+                },
+                "maxSentences": {
+                    "type": "integer"
+                },
+                "maxTokens": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "modelContextLength": {
+                    "type": "integer"
+                },
+                "notification": {
+                    "type": "boolean"
+                },
+                "notificationCommand": {
+                    "type": "string"
+                },
+                "overflowSummaryPattern": {
+                    "type": "string"
+                },
+                "presencePenalty": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "quiet": {
+                    "type": "boolean"
+                },
+                "raw": {
+                    "type": "boolean"
+                },
+                "returnRawResponse": {
+                    "type": "boolean"
+                },
+                "search": {
+                    "type": "boolean"
+                },
+                "searchDomains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "searchLocation": {
+                    "type": "string"
+                },
+                "searchRecency": {
+                    "type": "string"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "showMetadata": {
+                    "type": "boolean"
+                },
+                "stripThinkFromSaved": {
+                    "type": "boolean"
+                },
+                "suppressThink": {
+                    "type": "boolean"
+                },
+                "temperature": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "thinkEndTag": {
+                    "type": "string"
+                },
+                "thinkStartTag": {
+                    "type": "string"
+                },
+                "thinkTagPairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ThinkTagPair"
+                    }
+                },
+                "thinking": {
+                    "$ref": "#/definitions/domain.ThinkingLevel"
+                },
+                "topP": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "useDeveloperRole": {
+                    "type": "boolean"
+                },
+                "usePatternTemperatureHint": {
+                    "type": "boolean"
+                },
+                "voice": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FileChangeProgress": {
+            "type": "object",
+            "properties": {
+                "index": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ThinkTagPair": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.ThinkingLevel": {
             "type": "string",
             "enum": [
@@ -303,17 +726,41 @@ const docTemplate = `{
                 }
             }
         },
+        "fsdb.Context": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "fsdb.Pattern": {
             "type": "object",
             "properties": {
                 "description": {
                     "type": "string"
                 },
+                "language": {
+                    "description": "Language is the response language declared by the pattern's\n\"language:\" frontmatter field, if any.",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Metadata holds the pattern's YAML frontmatter, such as title, tags and\ndescription. It is nil for patterns without frontmatter.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
                 "name": {
                     "type": "string"
                 },
                 "pattern": {
                     "type": "string"
+                },
+                "suggested_temperature": {
+                    "description": "SuggestedTemperature is an advisory temperature derived from phrases in\nthe pattern text, such as \"be precise\" or \"be creative\".",
+                    "type": "number"
                 }
             }
         },
@@ -326,6 +773,9 @@ const docTemplate = `{
                 "audioOutput": {
                     "type": "boolean"
                 },
+                "autoSummarizeOnOverflow": {
+                    "type": "boolean"
+                },
                 "frequencyPenalty": {
                     "type": "number",
                     "format": "float64"
@@ -346,9 +796,14 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "language": {
-                    "description": "Add Language field to bind from request",
                     "type": "string"
                 },
+                "maxRepeatChunks": {
+                    "type": "integer"
+                },
+                "maxSentences": {
+                    "type": "integer"
+                },
                 "maxTokens": {
                     "type": "integer"
                 },
@@ -364,6 +819,9 @@ const docTemplate = `{
                 "notificationCommand": {
                     "type": "string"
                 },
+                "overflowSummaryPattern": {
+                    "type": "string"
+                },
                 "presencePenalty": {
                     "type": "number",
                     "format": "float64"
@@ -380,18 +838,33 @@ const docTemplate = `{
                 "raw": {
                     "type": "boolean"
                 },
+                "returnRawResponse": {
+                    "type": "boolean"
+                },
                 "search": {
                     "type": "boolean"
                 },
+                "searchDomains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "searchLocation": {
                     "type": "string"
                 },
+                "searchRecency": {
+                    "type": "string"
+                },
                 "seed": {
                     "type": "integer"
                 },
                 "showMetadata": {
                     "type": "boolean"
                 },
+                "stripThinkFromSaved": {
+                    "type": "boolean"
+                },
                 "suppressThink": {
                     "type": "boolean"
                 },
@@ -405,6 +878,12 @@ const docTemplate = `{
                 "thinkStartTag": {
                     "type": "string"
                 },
+                "thinkTagPairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ThinkTagPair"
+                    }
+                },
                 "thinking": {
                     "$ref": "#/definitions/domain.ThinkingLevel"
                 },
@@ -412,8 +891,11 @@ const docTemplate = `{
                     "type": "number",
                     "format": "float64"
                 },
-                "updateChan": {
-                    "type": "object"
+                "useDeveloperRole": {
+                    "type": "boolean"
+                },
+                "usePatternTemperatureHint": {
+                    "type": "boolean"
                 },
                 "voice": {
                     "type": "string"
@@ -434,6 +916,49 @@ const docTemplate = `{
                 }
             }
         },
+        "restapi.PatternRenderResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "prompt": {
+                    "type": "string"
+                }
+            }
+        },
+        "restapi.PatternStreamRequest": {
+            "type": "object",
+            "properties": {
+                "input": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "modelContextLength": {
+                    "type": "integer"
+                },
+                "options": {
+                    "$ref": "#/definitions/domain.ChatOptions"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "vendor": {
+                    "type": "string"
+                }
+            }
+        },
         "restapi.PromptRequest": {
             "type": "object",
             "properties": {
@@ -475,12 +1000,15 @@ const docTemplate = `{
                 "content": {
                     "type": "string"
                 },
+                "file_change": {
+                    "$ref": "#/definitions/domain.FileChangeProgress"
+                },
                 "format": {
                     "description": "\"markdown\", \"mermaid\", \"plain\"",
                     "type": "string"
                 },
                 "type": {
-                    "description": "\"content\", \"usage\", \"error\", \"complete\"",
+                    "description": "\"content\", \"usage\", \"error\", \"file_change\", \"complete\"",
                     "type": "string"
                 },
                 "usage": {
@@ -488,6 +1016,35 @@ const docTemplate = `{
                 }
             }
         },
+        "restapi.VendorHealth": {
+            "type": "object",
+            "properties": {
+                "elapsedMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"ok\", \"unconfigured\", \"error\"",
+                    "type": "string"
+                }
+            }
+        },
+        "restapi.VendorsHealthResponse": {
+            "type": "object",
+            "properties": {
+                "vendors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/restapi.VendorHealth"
+                    }
+                }
+            }
+        },
         "restapi.YouTubeRequest": {
             "type": "object",
             "required": [
@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// batchOutputSuffix is appended to each input path to name its output file
+// when no --output template is given.
+const batchOutputSuffix = ".out"
+
+// Placeholders expanded in a batch --output template.
+const (
+	batchNamePlaceholder = "{name}"
+	batchExtPlaceholder  = "{ext}"
+)
+
+// batchResultWriter stores the result of one batch input.
+type batchResultWriter func(input string, chatter *core.Chatter, session *fsdb.Session, duration time.Duration) error
+
+// handleBatchProcessing runs the chat request once for every file matching the
+// --batch glob and writes each result next to its input, or where the --output
+// template puts it.
+func handleBatchProcessing(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	if currentFlags.Concurrency < 1 {
+		return errors.New(i18n.T("batch_invalid_concurrency"))
+	}
+	if currentFlags.Session != "" {
+		return errors.New(i18n.T("batch_session_not_supported"))
+	}
+	if currentFlags.Output != "" && !strings.Contains(currentFlags.Output, batchNamePlaceholder) {
+		return errors.New(i18n.T("batch_output_requires_name"))
+	}
+
+	var inputs []string
+	if inputs, err = filepath.Glob(currentFlags.Batch); err != nil {
+		return fmt.Errorf(i18n.T("batch_invalid_pattern"), currentFlags.Batch, err)
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf(i18n.T("batch_no_inputs"), currentFlags.Batch)
+	}
+
+	// Streaming is disabled: concurrent responses would interleave on stdout
+	var chatter *core.Chatter
+	if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
+		currentFlags.Vendor, false, currentFlags.DryRun); err != nil {
+		return
+	}
+	chatter.FallbackModels = splitList(currentFlags.ModelFallback)
+	if currentFlags.Cache {
+		chatter.Cache = core.NewResponseCache(filepath.Join(registry.Db.Dir, core.ResponseCacheDirName), currentFlags.CacheTTL)
+	}
+
+	var chatOptions *domain.ChatOptions
+	if chatOptions, err = currentFlags.BuildChatOptions(); err != nil {
+		return
+	}
+
+	var extractor *domain.Extractor
+	if currentFlags.Extract != "" || currentFlags.ExtractRegex != "" {
+		if extractor, err = domain.NewExtractor(currentFlags.Extract, currentFlags.ExtractRegex); err != nil {
+			return
+		}
+	}
+
+	buildRequest := func(input string) (*domain.ChatRequest, error) {
+		content, readErr := os.ReadFile(input)
+		if readErr != nil {
+			return nil, readErr
+		}
+		inputFlags := *currentFlags
+		inputFlags.AppendMessage(string(content))
+		chatReq, buildErr := inputFlags.BuildChatRequest(strings.Join(os.Args[1:], " "))
+		if buildErr != nil {
+			return nil, buildErr
+		}
+		chatReq.DefaultLanguage = registry.Language.DefaultLanguage.Value
+		return chatReq, nil
+	}
+
+	writeResult := newBatchResultWriter(currentFlags.Output, inputs, extractor, currentFlags.OutputJSON, currentFlags.Force)
+	ctx, stop := interruptContext()
+	defer stop()
+	errs := runBatch(ctx, chatter, inputs, currentFlags.Concurrency, buildRequest, writeResult, chatOptions)
+	for _, batchErr := range errs {
+		debuglog.Error("%s\n", batchErr)
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", fmt.Sprintf(i18n.T("batch_summary"), len(inputs)-len(errs), len(errs)))
+	if len(errs) > 0 {
+		return fmt.Errorf(i18n.T("batch_inputs_failed"), len(errs), len(inputs))
+	}
+	return
+}
+
+// runBatch sends one request per input with at most concurrency requests in
+// flight and stores each result with writeResult. A failing input does not
+// stop the others; the returned errors, one per failed input, are in input
+// order.
+func runBatch(ctx context.Context, chatter *core.Chatter, inputs []string, concurrency int,
+	buildRequest func(input string) (*domain.ChatRequest, error), writeResult batchResultWriter, opts *domain.ChatOptions) []error {
+	results := make([]error, len(inputs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := runBatchInput(ctx, chatter, input, buildRequest, writeResult, opts); err != nil {
+				results[i] = fmt.Errorf("%s: %w", input, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func runBatchInput(ctx context.Context, chatter *core.Chatter, input string,
+	buildRequest func(input string) (*domain.ChatRequest, error), writeResult batchResultWriter, opts *domain.ChatOptions) error {
+	chatReq, err := buildRequest(input)
+	if err != nil {
+		return err
+	}
+	// Each input gets its own copy so concurrent sends do not share state
+	inputOpts := *opts
+	start := time.Now()
+	session, err := chatter.Send(ctx, chatReq, &inputOpts)
+	if err != nil {
+		return err
+	}
+	return writeResult(input, chatter, session, time.Since(start))
+}
+
+// newBatchResultWriter returns a writer that stores the result of each of
+// inputs at the path batchOutputPath gives, as a JSON envelope when outputJSON
+// is set or as plain text otherwise. A non-nil extractor is applied to each
+// result first. Parent directories are created; an existing file is only
+// replaced when overwrite is set.
+func newBatchResultWriter(outputTemplate string, inputs []string, extractor *domain.Extractor, outputJSON, overwrite bool) batchResultWriter {
+	names := batchOutputNames(inputs)
+	return func(input string, chatter *core.Chatter, session *fsdb.Session, duration time.Duration) (err error) {
+		content := session.GetLastMessage().Content
+		if extractor != nil {
+			if content, err = extractor.Extract(content); err != nil {
+				return
+			}
+		}
+		path := batchOutputPath(outputTemplate, input, names[input], outputJSON)
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return
+		}
+		if _, statErr := os.Stat(path); statErr == nil {
+			if !overwrite {
+				return fmt.Errorf(i18n.T("batch_output_exists"), path)
+			}
+			if err = os.Remove(path); err != nil {
+				return
+			}
+		}
+		if !outputJSON {
+			return CreateOutputFile(content, path)
+		}
+		var file *os.File
+		if file, err = createOutputFile(path); err != nil {
+			return
+		}
+		defer file.Close()
+		return writeOutputJSON(file, chatter, session, content, duration)
+	}
+}
+
+// batchOutputPath returns the output path for input: the input path plus
+// batchOutputSuffix without a template, otherwise the template with {name}
+// replaced by name, as batchOutputNames gives it, and {ext} by "json" or
+// "txt".
+func batchOutputPath(outputTemplate, input, name string, outputJSON bool) string {
+	if outputTemplate == "" {
+		return input + batchOutputSuffix
+	}
+	ext := "txt"
+	if outputJSON {
+		ext = "json"
+	}
+	return strings.NewReplacer(batchNamePlaceholder, name, batchExtPlaceholder, ext).Replace(outputTemplate)
+}
+
+// batchOutputNames returns the {name} of each input: its file name without the
+// extension, or, for inputs that would share that name, its whole path.
+// Names still shared after that get a numeric suffix. Path separators and dot
+// names are escaped so an input cannot move the output outside the template's
+// directory.
+func batchOutputNames(inputs []string) map[string]string {
+	counts := make(map[string]int, len(inputs))
+	for _, input := range inputs {
+		counts[batchBaseName(input)]++
+	}
+
+	ret := make(map[string]string, len(inputs))
+	used := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		name := batchBaseName(input)
+		if counts[name] > 1 {
+			name = escapeBatchName(filepath.ToSlash(filepath.Clean(input)))
+		}
+		unique := name
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s_%d", name, i)
+		}
+		used[unique] = true
+		ret[input] = unique
+	}
+	return ret
+}
+
+// batchBaseName returns the escaped file name of input without its extension.
+func batchBaseName(input string) string {
+	base := filepath.Base(input)
+	return escapeBatchName(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// escapeBatchName replaces path separators and names made only of dots.
+func escapeBatchName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if strings.Trim(name, ".") == "" {
+		name = strings.Repeat("_", max(len(name), 1))
+	}
+	return name
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools"
+)
+
+// batchVendor upper-cases its input, failing inputs that contain "fail", and
+// records the highest number of requests it served at once.
+type batchVendor struct {
+	envelopeVendor
+	mu        sync.Mutex
+	active    int
+	maxActive int
+}
+
+func (v *batchVendor) Send(_ context.Context, msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+	v.mu.Lock()
+	v.active++
+	v.maxActive = max(v.maxActive, v.active)
+	v.mu.Unlock()
+	defer func() {
+		v.mu.Lock()
+		v.active--
+		v.mu.Unlock()
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	content := msgs[len(msgs)-1].Content
+	if strings.Contains(content, "fail") {
+		return "", errors.New("vendor failure")
+	}
+	return strings.ToUpper(content), nil
+}
+
+func TestRunBatch(t *testing.T) {
+	vendor := &batchVendor{}
+	vm := ai.NewVendorsManager()
+	vm.AddVendors(vendor)
+	registry := &core.PluginRegistry{
+		Db:            fsdb.NewDb(t.TempDir()),
+		VendorManager: vm,
+		Defaults: &tools.Defaults{
+			PluginBase:         &plugins.PluginBase{},
+			Vendor:             &plugins.Setting{Value: "MockVendor"},
+			Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "mock-model"}},
+			ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+		},
+	}
+	chatter, err := registry.GetChatter("", 0, "", false, false)
+	if err != nil {
+		t.Fatalf("GetChatter returned error: %v", err)
+	}
+
+	dir := t.TempDir()
+	var inputs []string
+	for _, name := range []string{"a", "b", "c", "fail", "d", "e"} {
+		input := filepath.Join(dir, name+".md")
+		if err = os.WriteFile(input, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, input)
+	}
+	buildRequest := func(input string) (*domain.ChatRequest, error) {
+		content, readErr := os.ReadFile(input)
+		if readErr != nil {
+			return nil, readErr
+		}
+		return &domain.ChatRequest{Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: string(content)}}, nil
+	}
+
+	errs := runBatch(context.Background(), chatter, inputs, 2, buildRequest, newBatchResultWriter("", inputs, nil, false, false), &domain.ChatOptions{})
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "fail.md") {
+		t.Fatalf("expected a single error for fail.md, got %v", errs)
+	}
+	if vendor.maxActive != 2 {
+		t.Errorf("expected at most 2 concurrent requests with the bound reached, got %d", vendor.maxActive)
+	}
+	for _, input := range inputs {
+		output, readErr := os.ReadFile(input + batchOutputSuffix)
+		if strings.HasSuffix(input, "fail.md") {
+			if readErr == nil {
+				t.Errorf("expected no output for the failed input, got %q", output)
+			}
+			continue
+		}
+		if readErr != nil {
+			t.Fatalf("expected output for %s: %v", input, readErr)
+		}
+		want := strings.ToUpper(strings.TrimSuffix(filepath.Base(input), ".md")) + "\n"
+		if string(output) != want {
+			t.Errorf("output for %s = %q, want %q", input, output, want)
+		}
+	}
+}
+
+func TestBatchOutputPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		input      string
+		outputJSON bool
+		want       string
+	}{
+		{name: "no template", input: "docs/a.md", want: "docs/a.md.out"},
+		{name: "text", template: "results/{name}.{ext}", input: "docs/report.md", want: "results/report.txt"},
+		{name: "json", template: "results/{name}.{ext}", input: "docs/report.md", outputJSON: true, want: "results/report.json"},
+		{name: "repeated placeholders", template: "{name}/{name}-summary.{ext}", input: "a.b.md", want: "a.b/a.b-summary.txt"},
+		{name: "backslash escaped", template: "results/{name}.{ext}", input: `docs/..\..\evil.md`, want: "results/.._.._evil.txt"},
+		{name: "dot name escaped", template: "results/{name}/out.{ext}", input: "docs/...", want: "results/__/out.txt"},
+		{name: "parent name escaped", template: "results/{name}/out.{ext}", input: "..", want: "results/_/out.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := batchOutputPath(tt.template, tt.input, batchOutputNames([]string{tt.input})[tt.input], tt.outputJSON)
+			if got != tt.want {
+				t.Errorf("batchOutputPath(%q, %q) = %q, want %q", tt.template, tt.input, got, tt.want)
+			}
+			if tt.template != "" {
+				if rel, err := filepath.Rel("results", got); strings.HasPrefix(tt.template, "results/") && (err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+					t.Errorf("output %q escapes the template directory", got)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchOutputNames(t *testing.T) {
+	inputs := []string{"a/notes.md", "b/notes.md", "b/notes.txt", "a_notes.md", "report.md"}
+	want := map[string]string{
+		"a/notes.md":  "a_notes.md",
+		"b/notes.md":  "b_notes.md",
+		"b/notes.txt": "b_notes.txt",
+		"a_notes.md":  "a_notes",
+		"report.md":   "report",
+	}
+	if got := batchOutputNames(inputs); !reflect.DeepEqual(got, want) {
+		t.Errorf("batchOutputNames() = %v, want %v", got, want)
+	}
+
+	// Paths that escape to the same name still get distinct names
+	got := batchOutputNames([]string{"x/a_b/notes.md", "x/a/b/notes.md"})
+	if got["x/a_b/notes.md"] != "x_a_b_notes.md" || got["x/a/b/notes.md"] != "x_a_b_notes.md_2" {
+		t.Errorf("expected distinct names, got %v", got)
+	}
+}
+
+func TestBatchResultWriterOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.md")
+	output := input + batchOutputSuffix
+	if err := os.WriteFile(output, []byte("old result\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session := &fsdb.Session{Messages: []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleAssistant, Content: "new result"}}}
+
+	err := newBatchResultWriter("", []string{input}, nil, false, false)(input, nil, session, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected an error suggesting --force, got %v", err)
+	}
+	if err = newBatchResultWriter("", []string{input}, nil, false, true)(input, nil, session, time.Second); err != nil {
+		t.Fatalf("writer returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "new result\n" {
+		t.Errorf("expected the old result to be replaced, got %q", data)
+	}
+}
+
+func TestBatchResultWriterExtract(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.md")
+	extractor, err := domain.NewExtractor("", `id: (\d+)`)
+	if err != nil {
+		t.Fatalf("NewExtractor returned error: %v", err)
+	}
+	writeResult := newBatchResultWriter("", []string{input}, extractor, false, false)
+
+	session := &fsdb.Session{Messages: []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleAssistant, Content: "The id: 42 was found"}}}
+	if err = writeResult(input, nil, session, time.Second); err != nil {
+		t.Fatalf("writer returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(input + batchOutputSuffix); string(data) != "42\n" {
+		t.Errorf("expected the extracted result, got %q", data)
+	}
+
+	session.Messages[0].Content = "nothing to extract"
+	if err = writeResult(input, nil, session, time.Second); err == nil {
+		t.Error("expected an error when nothing can be extracted")
+	}
+}
+
+func TestBatchResultWriterCreatesDirectories(t *testing.T) {
+	vm := ai.NewVendorsManager()
+	vm.AddVendors(&envelopeVendor{})
+	registry := &core.PluginRegistry{
+		Db:            fsdb.NewDb(t.TempDir()),
+		VendorManager: vm,
+		Defaults: &tools.Defaults{
+			PluginBase:         &plugins.PluginBase{},
+			Vendor:             &plugins.Setting{Value: "MockVendor"},
+			Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "mock-model"}},
+			ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+		},
+	}
+	chatter, err := registry.GetChatter("", 0, "", false, false)
+	if err != nil {
+		t.Fatalf("GetChatter returned error: %v", err)
+	}
+	request := &domain.ChatRequest{Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	dir := t.TempDir()
+	template := filepath.Join(dir, "results", "nested", "{name}.{ext}")
+	if err = newBatchResultWriter(template, []string{filepath.Join(dir, "input.md")}, nil, true, false)(filepath.Join(dir, "input.md"), chatter, session, time.Second); err != nil {
+		t.Fatalf("writer returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "results", "nested", "input.json"))
+	if err != nil {
+		t.Fatalf("expected JSON output file: %v", err)
+	}
+	var envelope outputEnvelope
+	if err = json.Unmarshal(data, &envelope); err != nil || envelope.Content != "mock reply" {
+		t.Errorf("unexpected JSON output %q (%v)", data, err)
+	}
+}
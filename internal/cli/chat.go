@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/core"
 	"github.com/danielmiessler/fabric/internal/domain"
@@ -36,11 +38,26 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 		}
 	}
 
+	var extractor *domain.Extractor
+	if currentFlags.Extract != "" || currentFlags.ExtractRegex != "" {
+		if extractor, err = domain.NewExtractor(currentFlags.Extract, currentFlags.ExtractRegex); err != nil {
+			return
+		}
+	}
+	stream := streamOutput(currentFlags, extractor)
+
 	var chatter *core.Chatter
 	if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
-		currentFlags.Vendor, currentFlags.Stream, currentFlags.DryRun); err != nil {
+		currentFlags.Vendor, stream, currentFlags.DryRun); err != nil {
 		return
 	}
+	chatter.ProjectRoot = currentFlags.ProjectRoot
+	chatter.ConfirmChanges = currentFlags.ConfirmChanges
+	chatter.FallbackModels = splitList(currentFlags.ModelFallback)
+	if currentFlags.Cache {
+		chatter.Cache = core.NewResponseCache(filepath.Join(registry.Db.Dir, core.ResponseCacheDirName), currentFlags.CacheTTL)
+		chatter.Cache.AllowStream = currentFlags.CacheStream
+	}
 
 	var session *fsdb.Session
 	var chatReq *domain.ChatRequest
@@ -48,9 +65,7 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 		return
 	}
 
-	if chatReq.Language == "" {
-		chatReq.Language = registry.Language.DefaultLanguage.Value
-	}
+	chatReq.DefaultLanguage = registry.Language.DefaultLanguage.Value
 	var chatOptions *domain.ChatOptions
 	if chatOptions, err = currentFlags.BuildChatOptions(); err != nil {
 		return
@@ -89,13 +104,64 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 		chatOptions.AudioFormat = "wav" // Default to WAV format
 	}
 
-	if session, err = chatter.Send(context.Background(), chatReq, chatOptions); err != nil {
+	// Write streamed chunks to the output file as they arrive instead of once
+	// the response is complete
+	var streamFile *os.File
+	if currentFlags.StreamToFile {
+		if !currentFlags.Stream || currentFlags.Output == "" {
+			err = errors.New(i18n.T("stream_to_file_requires_stream_output"))
+			return
+		}
+		if stream && !currentFlags.OutputSession && !isAudioOutput {
+			if streamFile, err = createOutputFile(currentFlags.Output); err != nil {
+				return
+			}
+			defer streamFile.Close()
+			chatter.StreamOutput = streamFile
+		}
+	}
+
+	if currentFlags.OutputJSON {
+		// The envelope replaces the human-formatted output, streamed or not
+		chatOptions.Quiet = true
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	start := time.Now()
+	if session, err = chatter.Send(ctx, chatReq, chatOptions); err != nil {
 		return
 	}
 
 	result := session.GetLastMessage().Content
+	if extractor != nil {
+		if result, err = extractor.Extract(result); err != nil {
+			return
+		}
+	}
 
-	if !currentFlags.Stream || currentFlags.SuppressThink {
+	if chatOptions.ReturnRawResponse {
+		if session.RawResponse != "" {
+			fmt.Fprintf(os.Stderr, "%s\n%s\n", i18n.T("raw_vendor_response_header"), session.RawResponse)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s\n", i18n.T("raw_vendor_response_unavailable"))
+		}
+	}
+
+	if currentFlags.Stats {
+		if session.Usage != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", fmt.Sprintf(i18n.T("token_usage_stats"), session.Usage.InputTokens, session.Usage.OutputTokens, session.Usage.TotalTokens))
+		} else {
+			fmt.Fprintf(os.Stderr, "%s\n", i18n.T("token_usage_unavailable"))
+		}
+	}
+
+	if currentFlags.OutputJSON {
+		if err = writeOutputJSON(os.Stdout, chatter, session, result, time.Since(start)); err != nil {
+			return
+		}
+	} else if !stream || currentFlags.SuppressThink {
 		// For TTS models with audio output, show a user-friendly message instead of raw data
 		if isTTSModel && isAudioOutput && strings.HasPrefix(result, "FABRIC_AUDIO_DATA:") {
 			fmt.Printf(i18n.T("tts_audio_generated_successfully"), currentFlags.Output)
@@ -114,7 +180,16 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 
 	// if the output flag is set, create an output file
 	if currentFlags.Output != "" {
-		if currentFlags.OutputSession {
+		if streamFile != nil {
+			if !strings.HasSuffix(result, "\n") {
+				_, err = streamFile.WriteString("\n")
+			}
+			if err != nil {
+				err = fmt.Errorf(i18n.T("error_writing_to_file"), err)
+			} else {
+				debuglog.Log("\n\n[Output also written to %s]\n", currentFlags.Output)
+			}
+		} else if currentFlags.OutputSession {
 			sessionAsString := session.String()
 			err = CreateOutputFile(sessionAsString, currentFlags.Output)
 		} else {
@@ -193,6 +268,13 @@ func sendNotification(options *domain.ChatOptions, patternName, result string) e
 	return notificationManager.Send(title, message)
 }
 
+// streamOutput reports whether the response can be printed as it arrives.
+// Extraction and sentence limits need the complete response, so they turn
+// streaming off.
+func streamOutput(currentFlags *Flags, extractor *domain.Extractor) bool {
+	return currentFlags.Stream && extractor == nil && currentFlags.MaxOutputSentences <= 0
+}
+
 // isTTSModel checks if the model is a text-to-speech model
 func isTTSModel(modelName string) bool {
 	lowerModel := strings.ToLower(modelName)
@@ -200,3 +282,14 @@ func isTTSModel(modelName string) bool {
 		strings.Contains(lowerModel, "preview-tts") ||
 		strings.Contains(lowerModel, "text-to-speech")
 }
+
+// interruptContext returns a context canceled by the first Ctrl-C. Signal
+// handling is then restored, so a second Ctrl-C terminates the process.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
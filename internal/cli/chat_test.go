@@ -8,6 +8,33 @@ import (
 	"github.com/danielmiessler/fabric/internal/domain"
 )
 
+func TestStreamOutput(t *testing.T) {
+	extractor, err := domain.NewExtractor(domain.ExtractCode, "")
+	if err != nil {
+		t.Fatalf("NewExtractor() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		flags     Flags
+		extractor *domain.Extractor
+		want      bool
+	}{
+		{name: "stream", flags: Flags{Stream: true}, want: true},
+		{name: "no stream", flags: Flags{}, want: false},
+		{name: "extract", flags: Flags{Stream: true}, extractor: extractor, want: false},
+		{name: "max output sentences", flags: Flags{Stream: true, MaxOutputSentences: 2}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamOutput(&tt.flags, tt.extractor); got != tt.want {
+				t.Errorf("streamOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSendNotification_SecurityEscaping(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -2,12 +2,16 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
 	"github.com/danielmiessler/fabric/internal/tools/converter"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
@@ -36,6 +40,10 @@ func Cli(version string) (err error) {
 		return
 	}
 
+	if currentFlags.Timeout <= 0 {
+		return errors.New(i18n.T("invalid_timeout"))
+	}
+
 	// Initialize database and registry
 	var registry, err2 = initializeFabric()
 	if err2 != nil {
@@ -52,6 +60,7 @@ func Cli(version string) (err error) {
 	// Configure OpenAI Responses API setting based on CLI flag
 	if registry != nil {
 		configureOpenAIResponsesAPI(registry, currentFlags.DisableResponsesAPI)
+		configureVendorTimeouts(registry, currentFlags.Timeout)
 	}
 
 	// Handle setup and server commands
@@ -80,6 +89,12 @@ func Cli(version string) (err error) {
 		return
 	}
 
+	// Run the pattern over every input file in batch mode
+	if currentFlags.Batch != "" {
+		err = handleBatchProcessing(currentFlags, registry)
+		return
+	}
+
 	// Handle transcription if specified
 	if currentFlags.TranscribeFile != "" {
 		var transcriptionMessage string
@@ -98,6 +113,13 @@ func Cli(version string) (err error) {
 		}
 	}
 
+	// Convert subtitle input into deduplicated plain text
+	if currentFlags.Subtitles {
+		if currentFlags.Message, err = domain.SubtitlesToText(currentFlags.Message); err != nil {
+			return
+		}
+	}
+
 	// Handle tool-based message processing
 	var messageTools string
 	if messageTools, err = handleToolProcessing(currentFlags, registry); err != nil {
@@ -201,3 +223,16 @@ func configureOpenAIResponsesAPI(registry *core.PluginRegistry, disableResponses
 		}
 	}
 }
+
+// configureVendorTimeouts applies the --timeout value to every vendor that
+// supports a request timeout.
+func configureVendorTimeouts(registry *core.PluginRegistry, timeout time.Duration) {
+	if registry == nil || registry.VendorsAll == nil {
+		return
+	}
+	for _, vendor := range registry.VendorsAll.Vendors {
+		if setter, ok := vendor.(ai.TimeoutSetter); ok {
+			setter.SetTimeout(timeout)
+		}
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
@@ -30,6 +32,7 @@ type Flags struct {
 	PatternVariables                map[string]string    `short:"v" long:"variable" description:"Values for pattern variables, e.g. -v=#role:expert -v=#points:30"`
 	Context                         string               `short:"C" long:"context" description:"Choose a context from the available contexts" default:""`
 	Session                         string               `long:"session" description:"Choose a session from the available sessions"`
+	MetaFile                        string               `long:"meta-file" description:"Read the session meta message from a file; the command line is appended after it"`
 	Attachments                     []string             `short:"a" long:"attachment" description:"Attachment path or URL (e.g. for OpenAI image recognition messages)"`
 	Setup                           bool                 `short:"S" long:"setup" description:"Run setup for all reconfigurable parts of fabric"`
 	Temperature                     float64              `short:"t" long:"temperature" yaml:"temperature" description:"Set temperature" default:"0.7"`
@@ -39,8 +42,12 @@ type Flags struct {
 	Raw                             bool                 `short:"r" long:"raw" yaml:"raw" description:"Use the defaults of the model without sending chat options (temperature, top_p, etc.). Only affects OpenAI-compatible providers. Anthropic models always use smart parameter selection to comply with model-specific requirements."`
 	FrequencyPenalty                float64              `short:"F" long:"frequencypenalty" yaml:"frequencypenalty" description:"Set frequency penalty" default:"0.0"`
 	ListPatterns                    bool                 `short:"l" long:"listpatterns" description:"List all patterns"`
+	PatternTag                      string               `long:"tag" description:"Only list patterns whose frontmatter declares this tag (use with --listpatterns)"`
+	SearchPatterns                  string               `long:"search-patterns" description:"List patterns whose system prompt contains this text, with the matching line"`
+	ListPatternVariables            string               `long:"pattern-variables" description:"List the variables the named pattern expects, excluding {{input}}"`
 	ReadPattern                     string               `long:"readpattern" description:"Print the contents of the named pattern to the terminal"`
 	ListAllModels                   bool                 `short:"L" long:"listmodels" description:"List all available models"`
+	Verbose                         bool                 `long:"verbose" description:"With --listmodels, show models grouped by vendor with their streaming and search support"`
 	ListAllContexts                 bool                 `short:"x" long:"listcontexts" description:"List all contexts"`
 	ListAllSessions                 bool                 `short:"X" long:"listsessions" description:"List all sessions"`
 	UpdatePatterns                  bool                 `short:"U" long:"updatepatterns" description:"Update patterns"`
@@ -50,6 +57,8 @@ type Flags struct {
 	Vendor                          string               `short:"V" long:"vendor" yaml:"vendor" description:"Specify vendor for the selected model (e.g., -V \"LM Studio\" -m openai/gpt-oss-20b)"`
 	ModelContextLength              int                  `long:"modelContextLength" yaml:"modelContextLength" description:"Model context length (only affects ollama)"`
 	Output                          string               `short:"o" long:"output" description:"Output to file" default:""`
+	ProjectRoot                     string               `long:"project-root" description:"Directory to apply create_coding_feature file changes under (default: current directory)"`
+	ConfirmChanges                  bool                 `long:"confirm-changes" description:"Preview create_coding_feature file changes and ask before applying them"`
 	OutputSession                   bool                 `long:"output-session" description:"Output the entire session (also a temporary one) to the output file"`
 	LatestPatterns                  string               `short:"n" long:"latest" description:"Number of latest patterns to list" default:"0"`
 	ChangeDefaultModel              bool                 `short:"d" long:"changeDefaultModel" description:"Change default model"`
@@ -64,41 +73,66 @@ type Flags struct {
 	YouTubeMetadata                 bool                 `long:"metadata" description:"Output video metadata"`
 	YtDlpArgs                       string               `long:"yt-dlp-args" yaml:"ytDlpArgs" description:"Additional arguments to pass to yt-dlp (e.g. '--cookies-from-browser brave')"`
 	Spotify                         string               `long:"spotify" description:"Spotify podcast or episode URL to grab metadata from and send to chat"`
+	ContextSeparator                string               `long:"context-separator" yaml:"contextSeparator" description:"Separator between context and pattern in the system prompt; \\n and \\t are expanded (default: blank line)"`
 	Language                        string               `short:"g" long:"language" description:"Specify the Language Code for the chat, e.g. -g=en -g=zh" default:""`
 	ScrapeURL                       string               `short:"u" long:"scrape_url" description:"Scrape website URL to markdown using Jina AI"`
 	ScrapeQuestion                  string               `short:"q" long:"scrape_question" description:"Search question using Jina AI"`
-	Seed                            int                  `short:"e" long:"seed" yaml:"seed" description:"Seed to be used for LMM generation"`
+	Seed                            *int                 `short:"e" long:"seed" yaml:"seed" description:"Seed to be used for LMM generation"`
+	StopSequences                   []string             `long:"stop" yaml:"stop" description:"Stop generating when the model produces this sequence; repeat for several sequences"`
+	LogitBias                       []string             `long:"logit-bias" yaml:"logitBias" description:"Bias an OpenAI token as token_id=value, with value from -100 to 100; repeat for several tokens"`
 	WipeContext                     string               `short:"w" long:"wipecontext" description:"Wipe context"`
 	WipeSession                     string               `short:"W" long:"wipesession" description:"Wipe session"`
 	PrintContext                    string               `long:"printcontext" description:"Print context"`
 	PrintSession                    string               `long:"printsession" description:"Print session"`
+	ExportSession                   string               `long:"export-session" description:"Export session as a Markdown transcript, written to --output when given"`
+	ExportMeta                      bool                 `long:"export-meta" description:"Include meta messages as blockquotes in --export-session output"`
+	ImportSession                   string               `long:"import-session" description:"Import a session from a JSON file in the saved session format"`
+	ImportSessionName               string               `long:"name" description:"Session name for --import-session (defaults to the file name)"`
+	Force                           bool                 `long:"force" description:"Overwrite an existing session with --import-session, or existing outputs with --batch"`
 	HtmlReadability                 bool                 `long:"readability" description:"Convert HTML input into a clean, readable view"`
+	Subtitles                       bool                 `long:"subtitles" description:"Convert SRT or VTT subtitle input into deduplicated plain text"`
 	InputHasVars                    bool                 `long:"input-has-vars" description:"Apply variables to user input"`
 	NoVariableReplacement           bool                 `long:"no-variable-replacement" description:"Disable pattern variable replacement"`
 	DryRun                          bool                 `long:"dry-run" description:"Show what would be sent to the model without actually sending it"`
+	Cache                           bool                 `long:"cache" yaml:"cache" description:"Reuse cached responses for identical requests"`
+	CacheTTL                        time.Duration        `long:"cache-ttl" yaml:"cacheTTL" description:"How long cached responses stay valid (e.g. 30m, 24h; 0 = never expire)" default:"24h"`
+	CacheStream                     bool                 `long:"cache-stream" yaml:"cacheStream" description:"Also use the response cache when streaming"`
 	Serve                           bool                 `long:"serve" description:"Serve the Fabric Rest API"`
 	ServeOllama                     bool                 `long:"serveOllama" description:"Serve the Fabric Rest API with ollama endpoints"`
 	ServeAddress                    string               `long:"address" description:"The address to bind the REST API" default:":8080"`
-	ServeAPIKey                     string               `long:"api-key" description:"API key used to secure server routes" default:""`
+	ServeAPIKey                     string               `long:"api-key" env:"FABRIC_API_KEY" description:"API key used to secure server routes" default:""`
+	ServeRateLimit                  int                  `long:"rate-limit" env:"FABRIC_RATE_LIMIT" description:"Requests per minute the REST API allows from each client IP (0 disables the limit)"`
+	ServeLogBodies                  bool                 `long:"serve-log-bodies" description:"With --serve and --debug 2 or higher, log truncated pattern endpoint request and response bodies"`
 	Config                          string               `long:"config" description:"Path to YAML config file"`
 	Version                         bool                 `long:"version" description:"Print current version"`
 	ListExtensions                  bool                 `long:"listextensions" description:"List all registered extensions"`
 	AddExtension                    string               `long:"addextension" description:"Register a new extension from config file path"`
 	RemoveExtension                 string               `long:"rmextension" description:"Remove a registered extension by name"`
-	Strategy                        string               `long:"strategy" description:"Choose a strategy from the available strategies" default:""`
+	Strategy                        string               `long:"strategy" description:"Choose a strategy from the available strategies; separate several with commas to combine them" default:""`
 	ListStrategies                  bool                 `long:"liststrategies" description:"List all strategies"`
+	DescribeStrategy                string               `long:"describe-strategy" description:"Print the prompt of the named strategy"`
 	ListVendors                     bool                 `long:"listvendors" description:"List all vendors"`
 	ShellCompleteOutput             bool                 `long:"shell-complete-list" description:"Output raw list without headers/formatting (for shell completion)"`
 	Search                          bool                 `long:"search" description:"Enable web search tool for supported models (Anthropic, OpenAI, Gemini, Grok)"`
 	SearchLocation                  string               `long:"search-location" description:"Set location for web search results (e.g., 'America/Los_Angeles')"`
+	SearchDomains                   string               `long:"search-domains" description:"Comma-separated domains to limit Perplexity search to; prefix a domain with '-' to exclude it"`
+	SearchRecency                   string               `long:"search-recency" description:"Limit Perplexity search results to the last month, week, day or hour"`
 	ImageFile                       string               `long:"image-file" description:"Save generated image to specified file path (e.g., 'output.png')"`
 	ImageSize                       string               `long:"image-size" description:"Image dimensions: 1024x1024, 1536x1024, 1024x1536, auto (default: auto)"`
 	ImageQuality                    string               `long:"image-quality" description:"Image quality: low, medium, high, auto (default: auto)"`
 	ImageCompression                int                  `long:"image-compression" description:"Compression level 0-100 for JPEG/WebP formats (default: not set)"`
 	ImageBackground                 string               `long:"image-background" description:"Background type: opaque, transparent (default: opaque, only for PNG/WebP)"`
+	PatternTemperatureHint          bool                 `long:"pattern-temperature-hint" yaml:"patternTemperatureHint" description:"Use the temperature suggested by the pattern text (e.g. 'be precise') unless --temperature is set"`
+	MaxOutputSentences              int                  `long:"max-output-sentences" yaml:"maxOutputSentences" description:"Truncate the response to at most N sentences (0 = unlimited)" default:"0"`
+	MaxRepeatChunks                 int                  `long:"max-repeat-chunks" yaml:"maxRepeatChunks" description:"Abort streaming when the same chunk repeats more than N consecutive times (0 = disabled)" default:"0"`
+	AutoSummarizeOverflow           bool                 `long:"auto-summarize-overflow" yaml:"autoSummarizeOverflow" description:"On a context-length error, summarize earlier messages and retry once"`
+	OverflowSummaryPattern          string               `long:"overflow-summary-pattern" yaml:"overflowSummaryPattern" description:"Pattern used to summarize earlier messages with --auto-summarize-overflow" default:"summarize"`
+	TruncateContext                 bool                 `long:"truncate-context" yaml:"truncateContext" description:"Drop the oldest messages until the estimated prompt fits --modelContextLength, instead of only warning"`
 	SuppressThink                   bool                 `long:"suppress-think" yaml:"suppressThink" description:"Suppress text enclosed in thinking tags"`
+	StripThink                      bool                 `long:"strip-think" yaml:"stripThink" description:"Show text enclosed in thinking tags but remove it from the saved session"`
 	ThinkStartTag                   string               `long:"think-start-tag" yaml:"thinkStartTag" description:"Start tag for thinking sections" default:"<think>"`
 	ThinkEndTag                     string               `long:"think-end-tag" yaml:"thinkEndTag" description:"End tag for thinking sections" default:"</think>"`
+	DeveloperRole                   bool                 `long:"developer-role" yaml:"developerRole" description:"Send the system prompt with the developer role on OpenAI APIs (automatic for models that prefer it)"`
 	DisableResponsesAPI             bool                 `long:"disable-responses-api" yaml:"disableResponsesAPI" description:"Disable OpenAI Responses API (default: false)"`
 	TranscribeFile                  string               `long:"transcribe-file" yaml:"transcribeFile" description:"Audio or video file to transcribe"`
 	TranscribeModel                 string               `long:"transcribe-model" yaml:"transcribeModel" description:"Model to use for transcription (separate from chat model)"`
@@ -110,7 +144,23 @@ type Flags struct {
 	NotificationCommand             string               `long:"notification-command" yaml:"notificationCommand" description:"Custom command to run for notifications (overrides built-in notifications)"`
 	Thinking                        domain.ThinkingLevel `long:"thinking" yaml:"thinking" description:"Set reasoning/thinking level (e.g., off, low, medium, high, or numeric tokens for Anthropic or Google Gemini)"`
 	ShowMetadata                    bool                 `long:"show-metadata" description:"Print metadata to stderr"`
+	ShowRawResponse                 bool                 `long:"show-raw-response" description:"Print the vendor response body, as received, to stderr"`
+	DumpRaw                         string               `long:"dump-raw" description:"Append each raw vendor response body, before parsing, to this file (non-streaming requests)"`
+	Stats                           bool                 `long:"stats" description:"Print the token usage of the request to stderr"`
+	OutputJSON                      bool                 `long:"output-json" description:"Print the result as a JSON object with the model, provider, content, usage and duration"`
+	Extract                         string               `long:"extract" description:"Print only part of the response: code (first fenced code block) or json (first JSON object or array)"`
+	ExtractRegex                    string               `long:"extract-regex" description:"Print only the first capture group of this regular expression in the response"`
+	ModelFallback                   string               `long:"model-fallback" description:"Comma-separated models to try in order when the model is rate-limited or unavailable"`
+	StreamToFile                    bool                 `long:"stream-to-file" description:"With --stream and --output, write each chunk to the output file as it arrives"`
+	Batch                           string               `long:"batch" description:"Run the pattern over every file matching this glob, writing each result to <file>.out"`
+	Concurrency                     int                  `long:"concurrency" description:"Maximum number of batch inputs processed at once" default:"1"`
+	Timeout                         time.Duration        `long:"timeout" yaml:"timeout" description:"Fail a vendor request that gets no response within this time (e.g. 30s, 2m)" default:"300s"`
+	NoColor                         bool                 `long:"no-color" description:"Disable colored warnings and errors on stderr"`
 	Debug                           int                  `long:"debug" description:"Set debug level (0=off, 1=basic, 2=detailed, 3=trace, 4=wire)" default:"0"`
+
+	// temperatureSet records that --temperature was given on the command
+	// line, so the pattern's suggested temperature is not used.
+	temperatureSet bool
 }
 
 // Init Initialize flags. returns a Flags struct and an error
@@ -175,6 +225,7 @@ func Init() (ret *Flags, err error) {
 	}
 
 	debuglog.SetLevel(debuglog.LevelFromInt(ret.Debug))
+	debuglog.SetColor(!ret.NoColor)
 
 	// Check to see if a ~/.config/fabric/config.yaml config file exists (only when user didn't specify a config)
 	if ret.Config == "" {
@@ -187,9 +238,10 @@ func Init() (ret *Flags, err error) {
 	}
 
 	// If config specified, load and apply YAML for unused flags
+	var yamlKeys map[string]bool
 	if ret.Config != "" {
 		var yamlFlags *Flags
-		if yamlFlags, err = loadYAMLConfig(ret.Config); err != nil {
+		if yamlFlags, yamlKeys, err = loadYAMLConfig(ret.Config); err != nil {
 			return
 		}
 
@@ -236,6 +288,7 @@ func Init() (ret *Flags, err error) {
 		}
 		ret.Message = AppendMessage(ret.Message, pipedMessage)
 	}
+	ret.temperatureSet = usedFlags["temperature"] || yamlKeys["temperature"]
 	return
 }
 
@@ -304,29 +357,40 @@ func assignWithConversion(targetField, sourceField reflect.Value) error {
 	return fmt.Errorf(i18n.T("unsupported_conversion"), sourceField.Kind(), targetField.Kind())
 }
 
-func loadYAMLConfig(configPath string) (*Flags, error) {
+// loadYAMLConfig reads the config file at configPath. Besides the parsed
+// flags it returns the keys the file sets, which tells a value written in the
+// file apart from a zero value.
+func loadYAMLConfig(configPath string) (*Flags, map[string]bool, error) {
 	absPath, err := util.GetAbsolutePath(configPath)
 	if err != nil {
-		return nil, fmt.Errorf(i18n.T("invalid_config_path"), err)
+		return nil, nil, fmt.Errorf(i18n.T("invalid_config_path"), err)
 	}
 
 	data, err := os.ReadFile(absPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf(i18n.T("config_file_not_found"), absPath)
+			return nil, nil, fmt.Errorf(i18n.T("config_file_not_found"), absPath)
 		}
-		return nil, fmt.Errorf(i18n.T("error_reading_config_file"), err)
+		return nil, nil, fmt.Errorf(i18n.T("error_reading_config_file"), err)
 	}
 
 	// Use the existing Flags struct for YAML unmarshal
 	config := &Flags{}
 	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf(i18n.T("error_parsing_config_file"), err)
+		return nil, nil, fmt.Errorf(i18n.T("error_parsing_config_file"), err)
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, nil, fmt.Errorf(i18n.T("error_parsing_config_file"), err)
+	}
+	keys := make(map[string]bool, len(values))
+	for key := range values {
+		keys[key] = true
 	}
 
 	debuglog.Debug(debuglog.Detailed, "Config: %v\n", config)
 
-	return config, nil
+	return config, keys, nil
 }
 
 // readStdin reads from stdin and returns the input as a string or an error
@@ -431,6 +495,53 @@ func validateImageParameters(imagePath, size, quality, background string, compre
 	return nil
 }
 
+func validateSearchRecency(recency string) error {
+	if recency == "" {
+		return nil
+	}
+	validRecencies := []string{"month", "week", "day", "hour"}
+	if !slices.Contains(validRecencies, recency) {
+		return fmt.Errorf(i18n.T("invalid_search_recency"), recency)
+	}
+	return nil
+}
+
+// parseLogitBias parses token=value entries into a logit bias map. Tokens must
+// be non-negative token IDs and values must lie in the [-100, 100] range
+// accepted by the OpenAI API.
+func parseLogitBias(entries []string) (ret map[string]float64, err error) {
+	for _, entry := range entries {
+		token, value, found := strings.Cut(entry, "=")
+		id, idErr := strconv.Atoi(strings.TrimSpace(token))
+		if !found || idErr != nil || id < 0 {
+			return nil, fmt.Errorf(i18n.T("invalid_logit_bias"), entry)
+		}
+		token = strconv.Itoa(id)
+		var bias float64
+		if bias, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil || math.IsNaN(bias) {
+			return nil, fmt.Errorf(i18n.T("invalid_logit_bias"), entry)
+		}
+		if bias < -100 || bias > 100 {
+			return nil, fmt.Errorf(i18n.T("logit_bias_out_of_range"), token, bias)
+		}
+		if ret == nil {
+			ret = make(map[string]float64)
+		}
+		ret[token] = bias
+	}
+	return
+}
+
+// splitList turns a comma-separated list into its non-empty entries.
+func splitList(list string) (ret []string) {
+	for entry := range strings.SplitSeq(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			ret = append(ret, entry)
+		}
+	}
+	return
+}
+
 func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 	// Validate image file if specified
 	if err = validateImageFile(o.ImageFile); err != nil {
@@ -442,6 +553,16 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		return nil, err
 	}
 
+	// Validate search recency
+	if err = validateSearchRecency(o.SearchRecency); err != nil {
+		return nil, err
+	}
+
+	var logitBias map[string]float64
+	if logitBias, err = parseLogitBias(o.LogitBias); err != nil {
+		return nil, err
+	}
+
 	startTag := o.ThinkStartTag
 	if startTag == "" {
 		startTag = "<think>"
@@ -458,33 +579,63 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		PresencePenalty:     o.PresencePenalty,
 		FrequencyPenalty:    o.FrequencyPenalty,
 		Raw:                 o.Raw,
+		UseDeveloperRole:    o.DeveloperRole,
 		Seed:                o.Seed,
+		StopSequences:       o.StopSequences,
+		LogitBias:           logitBias,
 		Thinking:            o.Thinking,
 		ModelContextLength:  o.ModelContextLength,
 		Search:              o.Search,
 		SearchLocation:      o.SearchLocation,
+		SearchDomains:       splitList(o.SearchDomains),
+		SearchRecency:       o.SearchRecency,
 		ImageFile:           o.ImageFile,
 		ImageSize:           o.ImageSize,
 		ImageQuality:        o.ImageQuality,
 		ImageCompression:    o.ImageCompression,
 		ImageBackground:     o.ImageBackground,
+		MaxSentences:        o.MaxOutputSentences,
+		MaxRepeatChunks:     o.MaxRepeatChunks,
 		SuppressThink:       o.SuppressThink,
+		StripThinkFromSaved: o.StripThink,
 		ThinkStartTag:       startTag,
 		ThinkEndTag:         endTag,
+		ThinkTagPairs:       domain.ThinkTagPairsWithDefaults(startTag, endTag),
 		Voice:               o.Voice,
 		Notification:        o.Notification || o.NotificationCommand != "",
 		NotificationCommand: o.NotificationCommand,
 		ShowMetadata:        o.ShowMetadata,
+		ReturnRawResponse:   o.ShowRawResponse,
+		DumpRawFile:         o.DumpRaw,
+		TruncateContext:     o.TruncateContext,
+
+		UsePatternTemperatureHint: o.PatternTemperatureHint && !o.temperatureSet,
+		AutoSummarizeOnOverflow:   o.AutoSummarizeOverflow,
+		OverflowSummaryPattern:    o.OverflowSummaryPattern,
 	}
 	return
 }
 
 func (o *Flags) BuildChatRequest(Meta string) (ret *domain.ChatRequest, err error) {
+	if o.MetaFile != "" {
+		var content []byte
+		if content, err = os.ReadFile(o.MetaFile); err != nil {
+			err = fmt.Errorf(i18n.T("meta_file_read_error"), o.MetaFile, err)
+			return
+		}
+		fileMeta := strings.TrimRight(string(content), "\r\n")
+		if Meta != "" {
+			fileMeta = AppendMessage(fileMeta, Meta)
+		}
+		Meta = fileMeta
+	}
+
 	ret = &domain.ChatRequest{
 		ContextName:           o.Context,
 		SessionName:           o.Session,
 		PatternName:           o.Pattern,
 		StrategyName:          o.Strategy,
+		ContextSeparator:      expandSeparatorEscapes(o.ContextSeparator),
 		PatternVariables:      o.PatternVariables,
 		InputHasVars:          o.InputHasVars,
 		NoVariableReplacement: o.NoVariableReplacement,
@@ -546,6 +697,12 @@ func (o *Flags) BuildChatRequest(Meta string) (ret *domain.ChatRequest, err erro
 	return
 }
 
+// expandSeparatorEscapes turns the \n and \t escapes typed on the command line
+// into real newlines and tabs.
+func expandSeparatorEscapes(separator string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(separator)
+}
+
 func (o *Flags) AppendMessage(message string) {
 	o.Message = AppendMessage(o.Message, message)
 }
@@ -48,13 +48,71 @@ func ReadStdin(reader io.ReadCloser) (string, error) {
 	return buf.String(), nil
 }
 
+func TestInitSeedZero(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"cmd", "--seed", "0"}
+
+	flags, err := Init()
+	assert.NoError(t, err)
+	if assert.NotNil(t, flags.Seed) {
+		assert.Equal(t, 0, *flags.Seed)
+	}
+}
+
+func TestInitPatternTemperatureHintExplicitTemperature(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"--pattern-temperature-hint"}, want: true},
+		{args: []string{"--pattern-temperature-hint", "-t", "0.7"}, want: false},
+		{args: []string{"--pattern-temperature-hint", "--temperature=0.7"}, want: false},
+	} {
+		os.Args = append([]string{"cmd"}, tt.args...)
+		flags, err := Init()
+		assert.NoError(t, err)
+		options, err := flags.BuildChatOptions()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, options.UsePatternTemperatureHint, tt.args)
+	}
+}
+
+func TestInitPatternTemperatureHintConfigTemperature(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		config string
+		want   bool
+	}{
+		{config: "model: gpt-4\n", want: true},
+		{config: "temperature: 0.7\n", want: false},
+	} {
+		configPath := filepath.Join(dir, "config.yaml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0o644))
+
+		os.Args = []string{"cmd", "--config", configPath, "--pattern-temperature-hint"}
+		flags, err := Init()
+		assert.NoError(t, err)
+		options, err := flags.BuildChatOptions()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, options.UsePatternTemperatureHint, tt.config)
+	}
+}
+
 func TestBuildChatOptions(t *testing.T) {
+	seed := 1
 	flags := &Flags{
 		Temperature:      0.8,
 		TopP:             0.9,
 		PresencePenalty:  0.1,
 		FrequencyPenalty: 0.2,
-		Seed:             1,
+		Seed:             &seed,
 	}
 
 	expectedOptions := &domain.ChatOptions{
@@ -63,11 +121,12 @@ func TestBuildChatOptions(t *testing.T) {
 		PresencePenalty:  0.1,
 		FrequencyPenalty: 0.2,
 		Raw:              false,
-		Seed:             1,
+		Seed:             &seed,
 		Thinking:         domain.ThinkingLevel(""),
 		SuppressThink:    false,
 		ThinkStartTag:    "<think>",
 		ThinkEndTag:      "</think>",
+		ThinkTagPairs:    domain.DefaultThinkTagPairs,
 	}
 	options, err := flags.BuildChatOptions()
 	assert.NoError(t, err)
@@ -88,11 +147,12 @@ func TestBuildChatOptionsDefaultSeed(t *testing.T) {
 		PresencePenalty:  0.1,
 		FrequencyPenalty: 0.2,
 		Raw:              false,
-		Seed:             0,
+		Seed:             nil,
 		Thinking:         domain.ThinkingLevel(""),
 		SuppressThink:    false,
 		ThinkStartTag:    "<think>",
 		ThinkEndTag:      "</think>",
+		ThinkTagPairs:    domain.DefaultThinkTagPairs,
 	}
 	options, err := flags.BuildChatOptions()
 	assert.NoError(t, err)
@@ -111,6 +171,62 @@ func TestBuildChatOptionsSuppressThink(t *testing.T) {
 	assert.True(t, options.SuppressThink)
 	assert.Equal(t, "[[t]]", options.ThinkStartTag)
 	assert.Equal(t, "[[/t]]", options.ThinkEndTag)
+	assert.Equal(t, domain.ThinkTagPair{Start: "[[t]]", End: "[[/t]]"}, options.ThinkTagPairs[0])
+	assert.Len(t, options.ThinkTagPairs, len(domain.DefaultThinkTagPairs)+1)
+}
+
+func TestBuildChatOptionsSearchFilters(t *testing.T) {
+	flags := &Flags{SearchDomains: "arxiv.org, -reddit.com,,", SearchRecency: "week"}
+
+	options, err := flags.BuildChatOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"arxiv.org", "-reddit.com"}, options.SearchDomains)
+	assert.Equal(t, "week", options.SearchRecency)
+
+	flags.SearchRecency = "year"
+	_, err = flags.BuildChatOptions()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "year")
+}
+
+func TestBuildChatOptionsLogitBias(t *testing.T) {
+	flags := &Flags{LogitBias: []string{"50256=-100", " 1234 = 5.5"}}
+
+	options, err := flags.BuildChatOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"50256": -100, "1234": 5.5}, options.LogitBias)
+
+	for _, entry := range []string{"50256=101", "50256=-100.5", "50256", "=1", "50256=high", "hello=5", "-1=5", "1.5=5", "50256=NaN", "50256=Inf"} {
+		flags.LogitBias = []string{entry}
+		_, err = flags.BuildChatOptions()
+		assert.Error(t, err, entry)
+	}
+}
+
+func TestBuildChatRequestContextSeparator(t *testing.T) {
+	flags := &Flags{ContextSeparator: `\n---\t`}
+
+	request, err := flags.BuildChatRequest("")
+	assert.NoError(t, err)
+	assert.Equal(t, "\n---\t", request.ContextSeparator)
+}
+
+func TestBuildChatRequestMetaFile(t *testing.T) {
+	metaFile := filepath.Join(t.TempDir(), "meta.md")
+	assert.NoError(t, os.WriteFile(metaFile, []byte("project: fabric\nauthor: jane\n"), 0o644))
+
+	flags := &Flags{MetaFile: metaFile}
+	request, err := flags.BuildChatRequest("fabric -p summarize")
+	assert.NoError(t, err)
+	assert.Equal(t, "project: fabric\nauthor: jane\nfabric -p summarize", request.Meta)
+
+	request, err = flags.BuildChatRequest("")
+	assert.NoError(t, err)
+	assert.Equal(t, "project: fabric\nauthor: jane", request.Meta)
+
+	flags.MetaFile = filepath.Join(t.TempDir(), "missing.md")
+	_, err = flags.BuildChatRequest("fabric")
+	assert.Error(t, err)
 }
 
 func TestInitWithYAMLConfig(t *testing.T) {
@@ -18,6 +18,7 @@ var flagDescriptionMap = map[string]string{
 	"variable":                   "pattern_variables_help",
 	"context":                    "choose_context_from_available",
 	"session":                    "choose_session_from_available",
+	"meta-file":                  "meta_file_help",
 	"attachment":                 "attachment_path_or_url_help",
 	"setup":                      "run_setup_for_reconfigurable_parts",
 	"temperature":                "set_temperature",
@@ -27,7 +28,11 @@ var flagDescriptionMap = map[string]string{
 	"raw":                        "use_model_defaults_raw_help",
 	"frequencypenalty":           "set_frequency_penalty",
 	"listpatterns":               "list_all_patterns",
+	"tag":                        "filter_patterns_by_tag",
+	"search-patterns":            "search_pattern_contents",
+	"pattern-variables":          "list_pattern_variables",
 	"listmodels":                 "list_all_available_models",
+	"verbose":                    "list_models_verbose",
 	"listcontexts":               "list_all_contexts",
 	"listsessions":               "list_all_sessions",
 	"updatepatterns":             "update_patterns",
@@ -36,6 +41,8 @@ var flagDescriptionMap = map[string]string{
 	"vendor":                     "specify_vendor_for_model",
 	"modelContextLength":         "model_context_length_ollama",
 	"output":                     "output_to_file",
+	"project-root":               "project_root_for_file_changes",
+	"confirm-changes":            "confirm_file_changes",
 	"output-session":             "output_entire_session",
 	"latest":                     "number_of_latest_patterns",
 	"changeDefaultModel":         "change_default_model",
@@ -49,22 +56,36 @@ var flagDescriptionMap = map[string]string{
 	"comments":                   "grab_comments_from_youtube",
 	"metadata":                   "output_video_metadata",
 	"yt-dlp-args":                "additional_yt_dlp_args",
+	"context-separator":          "context_pattern_separator",
 	"language":                   "specify_language_code",
 	"scrape_url":                 "scrape_website_url",
 	"scrape_question":            "search_question_jina",
 	"seed":                       "seed_for_lmm_generation",
+	"stop":                       "stop_sequence_help",
+	"logit-bias":                 "logit_bias_help",
 	"wipecontext":                "wipe_context",
 	"wipesession":                "wipe_session",
 	"printcontext":               "print_context",
 	"printsession":               "print_session",
+	"export-session":             "export_session_markdown",
+	"export-meta":                "export_session_include_meta",
+	"import-session":             "import_session_from_json",
+	"name":                       "import_session_name",
+	"force":                      "import_session_force",
 	"readability":                "convert_html_readability",
+	"subtitles":                  "convert_subtitles",
 	"input-has-vars":             "apply_variables_to_input",
 	"no-variable-replacement":    "disable_pattern_variable_replacement",
 	"dry-run":                    "show_dry_run",
+	"cache":                      "use_response_cache",
+	"cache-ttl":                  "response_cache_ttl",
+	"cache-stream":               "response_cache_stream",
 	"serve":                      "serve_fabric_rest_api",
 	"serveOllama":                "serve_fabric_api_ollama_endpoints",
 	"address":                    "address_to_bind_rest_api",
 	"api-key":                    "api_key_secure_server_routes",
+	"rate-limit":                 "rate_limit_help",
+	"serve-log-bodies":           "serve_log_bodies_help",
 	"config":                     "path_to_yaml_config",
 	"version":                    "print_current_version",
 	"listextensions":             "list_all_registered_extensions",
@@ -72,18 +93,29 @@ var flagDescriptionMap = map[string]string{
 	"rmextension":                "remove_registered_extension",
 	"strategy":                   "choose_strategy_from_available",
 	"liststrategies":             "list_all_strategies",
+	"describe-strategy":          "describe_strategy_help",
 	"listvendors":                "list_all_vendors",
 	"shell-complete-list":        "output_raw_list_shell_completion",
 	"search":                     "enable_web_search_tool",
 	"search-location":            "set_location_web_search",
+	"search-domains":             "limit_search_domains",
+	"search-recency":             "limit_search_recency",
 	"image-file":                 "save_generated_image_to_file",
 	"image-size":                 "image_dimensions_help",
 	"image-quality":              "image_quality_help",
 	"image-compression":          "compression_level_jpeg_webp",
 	"image-background":           "background_type_help",
+	"pattern-temperature-hint":   "use_pattern_temperature_hint",
+	"max-output-sentences":       "max_output_sentences",
+	"max-repeat-chunks":          "max_repeat_chunks",
+	"auto-summarize-overflow":    "auto_summarize_on_overflow",
+	"overflow-summary-pattern":   "overflow_summary_pattern",
+	"truncate-context":           "truncate_context_help",
 	"suppress-think":             "suppress_thinking_tags",
+	"strip-think":                "strip_thinking_from_saved_session",
 	"think-start-tag":            "start_tag_thinking_sections",
 	"think-end-tag":              "end_tag_thinking_sections",
+	"developer-role":             "use_openai_developer_role",
 	"disable-responses-api":      "disable_openai_responses_api",
 	"transcribe-file":            "audio_video_file_transcribe",
 	"transcribe-model":           "model_for_transcription",
@@ -94,6 +126,18 @@ var flagDescriptionMap = map[string]string{
 	"notification":               "send_desktop_notification",
 	"notification-command":       "custom_notification_command",
 	"thinking":                   "set_reasoning_thinking_level",
+	"show-raw-response":          "show_raw_vendor_response",
+	"dump-raw":                   "dump_raw_help",
+	"stats":                      "show_token_usage_stats",
+	"output-json":                "output_json_help",
+	"no-color":                   "no_color_help",
+	"extract":                    "extract_help",
+	"extract-regex":              "extract_regex_help",
+	"model-fallback":             "model_fallback_help",
+	"stream-to-file":             "stream_to_file_help",
+	"batch":                      "batch_help",
+	"concurrency":                "concurrency_help",
+	"timeout":                    "timeout_help",
 	"debug":                      "set_debug_level",
 }
 
@@ -243,30 +287,8 @@ func (h *TranslatedHelpWriter) writeAllFlags() {
 
 		flagLine.WriteString(fmt.Sprintf("--%s", longTag))
 
-		// Add parameter indicator for non-boolean flags
-		isBoolFlag := field.Type.Kind() == reflect.Bool ||
-			strings.HasSuffix(longTag, "patterns") ||
-			strings.HasSuffix(longTag, "models") ||
-			strings.HasSuffix(longTag, "contexts") ||
-			strings.HasSuffix(longTag, "sessions") ||
-			strings.HasSuffix(longTag, "extensions") ||
-			strings.HasSuffix(longTag, "strategies") ||
-			strings.HasSuffix(longTag, "vendors") ||
-			strings.HasSuffix(longTag, "voices") ||
-			longTag == "setup" || longTag == "stream" || longTag == "raw" ||
-			longTag == "copy" || longTag == "updatepatterns" ||
-			longTag == "output-session" || longTag == "changeDefaultModel" ||
-			longTag == "playlist" || longTag == "transcript" ||
-			longTag == "transcript-with-timestamps" || longTag == "comments" ||
-			longTag == "metadata" || longTag == "readability" ||
-			longTag == "input-has-vars" || longTag == "no-variable-replacement" ||
-			longTag == "dry-run" || longTag == "serve" || longTag == "serveOllama" ||
-			longTag == "version" || longTag == "shell-complete-list" ||
-			longTag == "search" || longTag == "suppress-think" ||
-			longTag == "disable-responses-api" || longTag == "split-media-file" ||
-			longTag == "notification"
-
-		if !isBoolFlag {
+		// Add parameter indicator for flags that take a value
+		if field.Type.Kind() != reflect.Bool {
 			flagLine.WriteString("=")
 		}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/gemini"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/plugins/strategy"
 )
 
 // handleListingCommands handles listing-related commands
@@ -34,6 +35,40 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 		return true, err
 	}
 
+	if currentFlags.ListPatternVariables != "" {
+		var variables []string
+		if variables, err = fabricDb.Patterns.ListVariables(currentFlags.ListPatternVariables); err != nil {
+			return true, err
+		}
+		if len(variables) == 0 && !currentFlags.ShellCompleteOutput {
+			fmt.Printf("%s\n", fmt.Sprintf(i18n.T("pattern_variables_none"), currentFlags.ListPatternVariables))
+			return true, nil
+		}
+		for _, variable := range variables {
+			fmt.Println(variable)
+		}
+		return true, nil
+	}
+
+	if currentFlags.SearchPatterns != "" {
+		var results []fsdb.PatternSearchResult
+		if results, err = fabricDb.Patterns.Search(currentFlags.SearchPatterns); err != nil {
+			return true, err
+		}
+		if len(results) == 0 && !currentFlags.ShellCompleteOutput {
+			fmt.Printf("%s\n", fmt.Sprintf(i18n.T("patterns_search_no_results"), currentFlags.SearchPatterns))
+			return true, nil
+		}
+		for _, result := range results {
+			if currentFlags.ShellCompleteOutput {
+				fmt.Println(result.Name)
+			} else {
+				fmt.Printf("%s:%d: %s\n", result.Name, result.Line, result.Snippet)
+			}
+		}
+		return true, nil
+	}
+
 	if currentFlags.ListPatterns {
 		// Check if patterns exist before listing
 		var names []string
@@ -57,6 +92,13 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 			return true, nil
 		}
 
+		if currentFlags.PatternTag != "" {
+			for _, name := range fabricDb.Patterns.GetByTag(currentFlags.PatternTag) {
+				fmt.Println(name)
+			}
+			return true, nil
+		}
+
 		err = fabricDb.Patterns.ListNames(currentFlags.ShellCompleteOutput)
 		return true, err
 	}
@@ -73,6 +115,8 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 
 		if currentFlags.ShellCompleteOutput {
 			models.Print(true)
+		} else if currentFlags.Verbose {
+			ai.PrintModelInfos(os.Stdout, models.ModelInfos(registry.VendorManager))
 		} else {
 			models.PrintWithVendor(false, registry.Defaults.Vendor.Value, registry.Defaults.Model.Value)
 		}
@@ -94,6 +138,11 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 		return true, err
 	}
 
+	if currentFlags.DescribeStrategy != "" {
+		err = strategy.DescribeStrategy(currentFlags.DescribeStrategy)
+		return true, err
+	}
+
 	if currentFlags.ListVendors {
 		err = registry.ListVendors(os.Stdout)
 		return true, err
@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 )
 
@@ -22,6 +27,31 @@ func handleManagementCommands(currentFlags *Flags, fabricDb *fsdb.Db) (handled b
 		return true, err
 	}
 
+	if currentFlags.ExportSession != "" {
+		var transcript string
+		if transcript, err = fabricDb.Sessions.ExportMarkdown(currentFlags.ExportSession, currentFlags.ExportMeta); err != nil {
+			return true, err
+		}
+		if currentFlags.Output != "" {
+			err = CreateOutputFile(transcript, currentFlags.Output)
+		} else {
+			fmt.Print(transcript)
+		}
+		return true, err
+	}
+
+	if currentFlags.ImportSession != "" {
+		name := currentFlags.ImportSessionName
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(currentFlags.ImportSession), filepath.Ext(currentFlags.ImportSession))
+		}
+		var session *fsdb.Session
+		if session, err = fabricDb.Sessions.ImportSession(currentFlags.ImportSession, name, currentFlags.Force); err == nil {
+			fmt.Printf(i18n.T("sessions_imported"), session.Name, len(session.Messages))
+		}
+		return true, err
+	}
+
 	if currentFlags.PrintContext != "" {
 		err = fabricDb.Contexts.PrintContext(currentFlags.PrintContext)
 		return true, err
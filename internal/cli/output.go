@@ -1,17 +1,46 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 )
 
+// outputEnvelope is the JSON object printed by --output-json.
+type outputEnvelope struct {
+	Model      string                `json:"model"`
+	Provider   string                `json:"provider"`
+	Content    string                `json:"content"`
+	Usage      *domain.UsageMetadata `json:"usage,omitempty"`
+	DurationMs int64                 `json:"duration_ms"`
+}
+
+// writeOutputJSON writes content, the final message of session after any
+// --extract, with the model and vendor that produced it, as an outputEnvelope.
+func writeOutputJSON(w io.Writer, chatter *core.Chatter, session *fsdb.Session, content string, duration time.Duration) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(outputEnvelope{
+		Model:      chatter.Model(),
+		Provider:   chatter.VendorName(),
+		Content:    content,
+		Usage:      session.Usage,
+		DurationMs: duration.Milliseconds(),
+	})
+}
+
 func CopyToClipboard(message string) (err error) {
 	if err = clipboard.WriteAll(message); err != nil {
 		err = fmt.Errorf(i18n.T("could_not_copy_to_clipboard"), err)
@@ -20,13 +49,8 @@ func CopyToClipboard(message string) (err error) {
 }
 
 func CreateOutputFile(message string, fileName string) (err error) {
-	if _, err = os.Stat(fileName); err == nil {
-		err = fmt.Errorf(i18n.T("file_already_exists_not_overwriting"), fileName)
-		return
-	}
 	var file *os.File
-	if file, err = os.Create(fileName); err != nil {
-		err = fmt.Errorf(i18n.T("error_creating_file"), err)
+	if file, err = createOutputFile(fileName); err != nil {
 		return
 	}
 	defer file.Close()
@@ -41,6 +65,18 @@ func CreateOutputFile(message string, fileName string) (err error) {
 	return
 }
 
+// createOutputFile creates fileName, refusing to overwrite an existing file.
+func createOutputFile(fileName string) (file *os.File, err error) {
+	if _, err = os.Stat(fileName); err == nil {
+		err = fmt.Errorf(i18n.T("file_already_exists_not_overwriting"), fileName)
+		return
+	}
+	if file, err = os.Create(fileName); err != nil {
+		err = fmt.Errorf(i18n.T("error_creating_file"), err)
+	}
+	return
+}
+
 // CreateAudioOutputFile creates a binary file for audio data
 func CreateAudioOutputFile(audioData []byte, fileName string) (err error) {
 	// If no extension is provided, default to .wav
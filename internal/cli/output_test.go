@@ -1,8 +1,20 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools"
 )
 
 func TestCopyToClipboard(t *testing.T) {
@@ -55,3 +67,85 @@ func TestCreateOutputFileMessageWithTrailingNewline(t *testing.T) {
 		t.Fatalf("expected file contents %q, got %q", message, data)
 	}
 }
+
+// envelopeVendor is a vendor that answers every request with a fixed reply.
+type envelopeVendor struct{}
+
+func (v *envelopeVendor) GetName() string                       { return "MockVendor" }
+func (v *envelopeVendor) GetSetupDescription() string           { return "MockVendor" }
+func (v *envelopeVendor) IsConfigured() bool                    { return true }
+func (v *envelopeVendor) Configure() error                      { return nil }
+func (v *envelopeVendor) Setup() error                          { return nil }
+func (v *envelopeVendor) SetupFillEnvFileContent(*bytes.Buffer) {}
+func (v *envelopeVendor) ListModels(context.Context) ([]string, error) {
+	return []string{"mock-model"}, nil
+}
+func (v *envelopeVendor) SendStream(_ context.Context, _ []*chat.ChatCompletionMessage, _ *domain.ChatOptions, updates chan domain.StreamUpdate) error {
+	defer close(updates)
+	updates <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: "mock "}
+	updates <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: "reply"}
+	updates <- domain.StreamUpdate{Type: domain.StreamTypeUsage, Usage: &domain.UsageMetadata{InputTokens: 3, OutputTokens: 2, TotalTokens: 5}}
+	return nil
+}
+func (v *envelopeVendor) Send(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+	return "mock reply", nil
+}
+func (v *envelopeVendor) NeedsRawMode(string) bool { return false }
+
+func TestWriteOutputJSON(t *testing.T) {
+	vm := ai.NewVendorsManager()
+	vm.AddVendors(&envelopeVendor{})
+	registry := &core.PluginRegistry{
+		Db:            fsdb.NewDb(t.TempDir()),
+		VendorManager: vm,
+		Defaults: &tools.Defaults{
+			PluginBase:         &plugins.PluginBase{},
+			Vendor:             &plugins.Setting{Value: "MockVendor"},
+			Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "mock-model"}},
+			ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+		},
+	}
+
+	for _, stream := range []bool{false, true} {
+		chatter, err := registry.GetChatter("", 0, "", stream, false)
+		if err != nil {
+			t.Fatalf("GetChatter returned error: %v", err)
+		}
+		request := &domain.ChatRequest{Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+		session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Quiet: true})
+		if err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err = writeOutputJSON(&buf, chatter, session, session.GetLastMessage().Content, 1500*time.Millisecond); err != nil {
+			t.Fatalf("writeOutputJSON returned error: %v", err)
+		}
+
+		var envelope outputEnvelope
+		if err = json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+			t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+		}
+		if envelope.Content != "mock reply" || envelope.Provider != "MockVendor" || envelope.Model != "mock-model" {
+			t.Errorf("stream=%v: unexpected envelope %+v", stream, envelope)
+		}
+		if envelope.DurationMs != 1500 {
+			t.Errorf("stream=%v: expected duration_ms 1500, got %d", stream, envelope.DurationMs)
+		}
+		if stream && (envelope.Usage == nil || envelope.Usage.TotalTokens != 5) {
+			t.Errorf("expected streamed usage in the envelope, got %+v", envelope.Usage)
+		}
+
+		// With --extract the envelope holds the extracted text, not the reply
+		buf.Reset()
+		if err = writeOutputJSON(&buf, chatter, session, "mock", time.Second); err != nil {
+			t.Fatalf("writeOutputJSON returned error: %v", err)
+		}
+		if err = json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+			t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+		}
+		if envelope.Content != "mock" {
+			t.Errorf("stream=%v: expected the extracted content, got %q", stream, envelope.Content)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package core
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/chat"
@@ -18,17 +22,50 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/template"
 )
 
+// ErrCanceledByUser is returned by Send when its context is canceled, for
+// example by Ctrl-C. Any partial response is appended to the session first.
+var ErrCanceledByUser = errors.New(i18n.T("chatter_error_canceled_by_user"))
+
 type Chatter struct {
 	db *fsdb.Db
 
 	Stream bool
 	DryRun bool
+	Cache  *ResponseCache
+	// ProjectRoot is the directory create_coding_feature file changes are
+	// applied under. The current working directory is used when it is empty.
+	ProjectRoot string
+	// ConfirmChanges previews file changes and asks on stdin before applying them.
+	ConfirmChanges bool
+	// StreamOutput receives streamed content as it arrives, with thinking
+	// sections removed when they are suppressed.
+	StreamOutput io.Writer
+	// FallbackModels are tried in order when a request fails with a retriable
+	// error, such as rate limiting, a server error or a timeout.
+	FallbackModels []string
+
+	// resolveModel returns a chatter for a fallback model, with its vendor,
+	// normalized name and context length.
+	resolveModel func(model string) (*Chatter, error)
 
 	model              string
 	modelContextLength int
 	vendor             ai.Vendor
 }
 
+// Model returns the model the chatter sends requests to.
+func (o *Chatter) Model() string {
+	return o.model
+}
+
+// VendorName returns the name of the vendor the chatter sends requests to.
+func (o *Chatter) VendorName() string {
+	if o.vendor == nil {
+		return ""
+	}
+	return o.vendor.GetName()
+}
+
 // recordFirstStreamError sends err to errChan if the channel is empty; subsequent errors are discarded.
 func recordFirstStreamError(errChan chan error, err error) {
 	if err == nil {
@@ -45,6 +82,11 @@ func recordFirstStreamError(errChan chan error, err error) {
 
 // joinPromptSections trims each part, drops empty ones, and joins the rest with newline separators.
 func joinPromptSections(parts ...string) string {
+	return joinPromptSectionsWith("\n", parts...)
+}
+
+// joinPromptSectionsWith trims each part, drops empty ones, and joins the rest with separator.
+func joinPromptSectionsWith(separator string, parts ...string) string {
 	sections := make([]string, 0, len(parts))
 	for _, part := range parts {
 		trimmed := strings.TrimSpace(part)
@@ -53,7 +95,7 @@ func joinPromptSections(parts ...string) string {
 		}
 	}
 
-	return strings.Join(sections, "\n")
+	return strings.Join(sections, separator)
 }
 
 // Send processes a chat request and applies file changes for create_coding_feature pattern
@@ -68,6 +110,7 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 	}
 
 	vendorMessages := session.GetVendorMessages()
+	sessionMessages := vendorMessages
 
 	if debuglog.GetLevel() >= debuglog.Wire {
 		debuglog.Debug(debuglog.Wire, "FABRIC->LLM request messages (%d)\n", len(vendorMessages))
@@ -97,22 +140,387 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 		opts.ModelContextLength = o.modelContextLength
 	}
 
-	message := ""
+	vendorMessages = fitContextLength(vendorMessages, opts)
+
+	if opts.UsePatternTemperatureHint {
+		o.applyPatternTemperatureHint(request, opts)
+	}
+
+	// Keep the body of the last vendor response, captured where --dump-raw
+	// writes it, so a fallback or overflow retry reports the answer shown
+	var rawResponse []byte
+	if opts.ReturnRawResponse {
+		opts.RawResponseHandler = func(data []byte) { rawResponse = append(rawResponse[:0], data...) }
+		defer func() { opts.RawResponseHandler = nil }()
+	}
+
+	cacheKey, message, cached := o.cachedResponse(vendorMessages, opts)
+	if !cached {
+		var fellBack bool
+		message, session.Usage, fellBack, err = o.sendWithFallback(ctx, vendorMessages, sessionMessages, opts)
+		if err != nil && opts.AutoSummarizeOnOverflow && domain.IsContextLengthError(err) {
+			var shortened []*chat.ChatCompletionMessage
+			var summarizeErr error
+			if shortened, summarizeErr = o.summarizeOverflow(ctx, vendorMessages, opts); summarizeErr != nil {
+				err = summarizeErr
+			} else if shortened != nil {
+				message, session.Usage, err = o.sendToVendor(ctx, shortened, opts)
+			}
+		}
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				err = o.saveCanceled(session, message, opts)
+			}
+			return
+		}
+		// The key names the requested vendor and model, so a fallback
+		// model's answer must not be stored under it
+		if cacheKey != "" && !fellBack {
+			if cacheErr := o.Cache.Put(cacheKey, message); cacheErr != nil {
+				debuglog.Debug(debuglog.Basic, "failed to write response cache: %v\n", cacheErr)
+			}
+		}
+	}
+
+	session.RawResponse = string(rawResponse)
+
+	hadThinkContent := false
+	if opts.SuppressThink && !o.DryRun {
+		stripped := domain.StripThinkTags(message, opts.ThinkTags())
+		hadThinkContent = stripped != message
+		message = stripped
+	}
+
+	if opts.MaxSentences > 0 && !o.DryRun {
+		message = domain.LimitSentences(message, opts.MaxSentences)
+	}
+
+	if strings.TrimSpace(message) == "" {
+		// A reply that was only reasoning is not a failure; tell the user
+		// why nothing is shown instead of reporting an empty response.
+		if !hadThinkContent {
+			session = nil
+			err = errors.New(i18n.T("chatter_error_empty_response"))
+			return
+		}
+		if !opts.Quiet {
+			debuglog.Warn("%s\n", i18n.T("chatter_warning_only_hidden_reasoning"))
+		}
+	}
+
+	// Process file changes for create_coding_feature pattern
+	if request.PatternName == "create_coding_feature" {
+		summary, fileChanges, parseErr := domain.ParseFileChanges(message)
+		if parseErr != nil {
+			debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_parse_file_changes_failed"), parseErr))
+		} else if len(fileChanges) > 0 {
+			projectRoot, rootErr := o.resolveProjectRoot()
+			apply := rootErr == nil
+			if rootErr != nil {
+				debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_resolve_project_root_failed"), rootErr))
+			} else if o.ConfirmChanges {
+				if apply, rootErr = confirmFileChanges(projectRoot, fileChanges, os.Stdin, os.Stdout); rootErr != nil {
+					debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_preview_file_changes_failed"), rootErr))
+				} else if !apply {
+					fmt.Println(i18n.T("chatter_info_file_changes_skipped"))
+				}
+			}
+			if apply {
+				if applyErr := domain.ApplyFileChangesWithProgress(projectRoot, fileChanges, opts.UpdateChan); applyErr != nil {
+					debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_apply_file_changes_failed"), applyErr))
+				} else {
+					fmt.Println(i18n.T("chatter_info_file_changes_applied_successfully"))
+					fmt.Printf("%s\n\n", i18n.T("chatter_help_review_changes_with_git_diff"))
+				}
+			}
+		}
+		message = summary
+	}
+
+	session.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: message})
+
+	if session.Name != "" {
+		err = o.db.Sessions.SaveSession(sessionToSave(session, opts))
+	}
+	return
+}
+
+// saveCanceled appends the partial response of a canceled request to session,
+// saves the session when it is named and returns ErrCanceledByUser.
+func (o *Chatter) saveCanceled(session *fsdb.Session, partial string, opts *domain.ChatOptions) error {
+	if opts.SuppressThink {
+		partial = domain.StripThinkTags(partial, opts.ThinkTags())
+	}
+	if strings.TrimSpace(partial) == "" {
+		return ErrCanceledByUser
+	}
+	session.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: partial})
+	if session.Name != "" {
+		if err := o.db.Sessions.SaveSession(sessionToSave(session, opts)); err != nil {
+			return err
+		}
+	}
+	return ErrCanceledByUser
+}
+
+// fitContextLength checks the estimated prompt size against the model context
+// length before sending. An oversized prompt is reported, or with
+// TruncateContext trimmed of its oldest messages until it fits.
+func fitContextLength(messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) []*chat.ChatCompletionMessage {
+	if opts.ModelContextLength <= 0 {
+		return messages
+	}
+	estimate := domain.EstimateMessagesTokens(messages)
+	if estimate <= opts.ModelContextLength {
+		return messages
+	}
+	if !opts.TruncateContext {
+		if !opts.Quiet {
+			debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_context_length_exceeded"), estimate, opts.ModelContextLength))
+		}
+		return messages
+	}
+	truncated, dropped := domain.TruncateMessages(messages, opts.ModelContextLength)
+	if !opts.Quiet {
+		debuglog.Log("%s\n", fmt.Sprintf(i18n.T("chatter_info_context_truncated"), dropped, domain.EstimateMessagesTokens(truncated), opts.ModelContextLength))
+	}
+	return truncated
+}
+
+// sessionToSave returns the session as it should be persisted. With
+// StripThinkFromSaved the returned copy has thinking blocks removed from the
+// new assistant message, while the caller's session keeps them for display.
+func sessionToSave(session *fsdb.Session, opts *domain.ChatOptions) *fsdb.Session {
+	if !opts.StripThinkFromSaved || opts.SuppressThink {
+		return session
+	}
+	last := session.GetLastMessage()
+	stripped := *last
+	stripped.Content = domain.StripThinkTags(last.Content, opts.ThinkTags())
+
+	saved := *session
+	saved.Messages = append(slices.Clone(session.Messages[:len(session.Messages)-1]), &stripped)
+	return &saved
+}
+
+// resolveProjectRoot returns the absolute directory file changes are applied
+// under, and fails unless it is an existing directory.
+func (o *Chatter) resolveProjectRoot() (root string, err error) {
+	if o.ProjectRoot == "" {
+		return os.Getwd()
+	}
+	if root, err = filepath.Abs(o.ProjectRoot); err != nil {
+		return
+	}
+	var info os.FileInfo
+	if info, err = os.Stat(root); err != nil {
+		return
+	}
+	if !info.IsDir() {
+		err = fmt.Errorf(i18n.T("chatter_error_project_root_not_directory"), root)
+	}
+	return
+}
+
+// confirmFileChanges prints each file change with its size and a diff against
+// the current file, then asks whether to apply them. Anything but "y" or "yes",
+// including end of input, declines.
+func confirmFileChanges(projectRoot string, changes []domain.FileChange, in io.Reader, out io.Writer) (ok bool, err error) {
+	for _, change := range changes {
+		var diff string
+		if diff, err = domain.FileChangeDiff(projectRoot, change); err != nil {
+			return
+		}
+		path := change.Path
+		if change.Operation == "rename" {
+			path += " -> " + change.NewPath
+		}
+		fmt.Fprintf(out, "%s\n", fmt.Sprintf(i18n.T("chatter_file_change_preview"), change.Operation, path, len(change.Content)))
+		if diff == "" {
+			fmt.Fprintln(out, i18n.T("chatter_file_change_unchanged"))
+		} else {
+			fmt.Fprint(out, diff)
+		}
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintf(out, i18n.T("chatter_prompt_confirm_file_changes"), len(changes))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	ok = answer == "y" || answer == "yes"
+	return
+}
+
+// cachedResponse looks messages up in the response cache. key is the cache key
+// the response should be stored under, or "" when caching does not apply.
+// Streaming requests bypass the cache unless the cache allows them; cached
+// responses are then replayed as a single stream update.
+func (o *Chatter) cachedResponse(messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (key string, message string, ok bool) {
+	if o.Cache == nil || o.DryRun || (o.Stream && !o.Cache.AllowStream) {
+		return
+	}
+
+	var err error
+	if key, err = o.Cache.Key(o.vendor.GetName(), messages, opts); err != nil {
+		debuglog.Debug(debuglog.Basic, "failed to build response cache key: %v\n", err)
+		return "", "", false
+	}
+	if message, ok = o.Cache.Get(key); !ok {
+		return
+	}
+
+	debuglog.Debug(debuglog.Detailed, "response cache hit: %s\n", key)
+	if o.Stream {
+		if opts.UpdateChan != nil {
+			opts.UpdateChan <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: message}
+		}
+		if !opts.SuppressThink && !opts.Quiet {
+			fmt.Print(message)
+			if !strings.HasSuffix(message, "\n") {
+				fmt.Println()
+			}
+		}
+		output := newStreamOutput(o.StreamOutput, opts)
+		output.Write(message)
+		if outputErr := output.Close(); outputErr != nil {
+			debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_stream_output_failed"), outputErr))
+		}
+	}
+	return
+}
+
+// sendWithFallback sends messages to the chatter's model and, when that fails
+// with a retriable error before any content arrived, to each of FallbackModels
+// in turn. messages are prepared for the chatter's model; each fallback is
+// sent sessionMessages, the unfitted session, prepared for that model by
+// fallbackMessages. Once a fallback has been tried, a failure lists every
+// attempted model. fellBack reports whether a fallback answered, in which case
+// opts is left with that model's settings.
+func (o *Chatter) sendWithFallback(ctx context.Context, messages, sessionMessages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (message string, usage *domain.UsageMetadata, fellBack bool, err error) {
+	message, usage, err = o.sendToVendor(ctx, messages, opts)
+	if err == nil || message != "" || !domain.IsRetriableError(err) || len(o.FallbackModels) == 0 || o.resolveModel == nil || o.DryRun {
+		return
+	}
+
+	primary := *opts
+	attempts := []string{fmt.Sprintf("%s: %v", o.model, err)}
+	previous := o.model
+	for _, name := range o.FallbackModels {
+		resolved, resolveErr := o.resolveModel(name)
+		if resolveErr != nil {
+			attempts = append(attempts, fmt.Sprintf("%s: %v", name, resolveErr))
+			continue
+		}
+		if !opts.Quiet {
+			debuglog.Warn("%s\n", fmt.Sprintf(i18n.T("chatter_warning_model_fallback"), previous, resolved.model))
+		}
+		fallback := *o
+		fallback.vendor = resolved.vendor
+		fallback.model = resolved.model
+		fallback.modelContextLength = resolved.modelContextLength
+		*opts = primary
+		fallbackMessages := fallback.fallbackMessages(sessionMessages, opts)
+		if message, usage, err = fallback.sendToVendor(ctx, fallbackMessages, opts); err == nil {
+			fellBack = true
+			return
+		}
+		attempts = append(attempts, fmt.Sprintf("%s: %v", fallback.model, err))
+		if message != "" || !domain.IsRetriableError(err) {
+			break
+		}
+		previous = fallback.model
+	}
+	*opts = primary
+	err = fmt.Errorf(i18n.T("chatter_error_all_models_failed"), strings.Join(attempts, "; "))
+	return
+}
+
+// fallbackMessages prepares the session's messages for the chatter's model,
+// updating opts to match: raw mode is switched on when its vendor needs it,
+// and the messages are fitted to its context length, or to the one the
+// request already used when the model has none configured.
+func (o *Chatter) fallbackMessages(messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) []*chat.ChatCompletionMessage {
+	opts.Model = o.model
+	if !opts.Raw && o.vendor.NeedsRawMode(o.model) {
+		opts.Raw = true
+		messages = rawModeMessages(messages)
+	}
+	if o.modelContextLength > 0 {
+		opts.ModelContextLength = o.modelContextLength
+	}
+	return fitContextLength(messages, opts)
+}
+
+// rawModeMessages folds each system message into the user message that
+// follows it, as BuildSession does in raw mode, or turns it into a user
+// message when no user message follows.
+func rawModeMessages(messages []*chat.ChatCompletionMessage) (ret []*chat.ChatCompletionMessage) {
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if msg.Role != chat.ChatMessageRoleSystem {
+			ret = append(ret, msg)
+			continue
+		}
+		merged := &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: msg.Content}
+		if i+1 < len(messages) && messages[i+1].Role == chat.ChatMessageRoleUser {
+			next := messages[i+1]
+			i++
+			if len(next.MultiContent) > 0 {
+				merged.Content = ""
+				merged.MultiContent = append([]chat.ChatMessagePart{{Type: chat.ChatMessagePartTypeText, Text: msg.Content}}, next.MultiContent...)
+			} else if next.Content != "" {
+				merged.Content = fmt.Sprintf("%s\n\n%s", msg.Content, next.Content)
+			}
+		}
+		ret = append(ret, merged)
+	}
+	return
+}
 
+// sendToVendor sends messages to the vendor, streaming the response to stdout
+// when streaming is enabled, and returns the complete response text along with
+// the token usage, when the vendor reports it.
+func (o *Chatter) sendToVendor(ctx context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (message string, usage *domain.UsageMetadata, err error) {
 	if o.Stream {
 		responseChan := make(chan domain.StreamUpdate)
 		errChan := make(chan error, 1)
 		done := make(chan struct{})
 		printedStream := false
 
+		streamCtx, cancelStream := context.WithCancel(ctx)
+		defer cancelStream()
+
 		go func() {
 			defer close(done)
-			if streamErr := o.vendor.SendStream(ctx, session.GetVendorMessages(), opts, responseChan); streamErr != nil {
+			if streamErr := o.vendor.SendStream(streamCtx, messages, opts, responseChan); streamErr != nil {
 				recordFirstStreamError(errChan, streamErr)
 			}
 		}()
 
+		output := newStreamOutput(o.StreamOutput, opts)
+
+		var lastChunk string
+		repeatedChunks := 0
+		aborted := false
 		for update := range responseChan {
+			// Keep draining after an abort so the vendor goroutine can exit.
+			if aborted {
+				continue
+			}
+			if opts.MaxRepeatChunks > 0 && update.Type == domain.StreamTypeContent {
+				if update.Content == lastChunk {
+					repeatedChunks++
+				} else {
+					lastChunk = update.Content
+					repeatedChunks = 1
+				}
+				if repeatedChunks > opts.MaxRepeatChunks {
+					recordFirstStreamError(errChan, fmt.Errorf(i18n.T("chatter_error_repeated_stream_chunk"), opts.MaxRepeatChunks, lastChunk))
+					aborted = true
+					cancelStream()
+					continue
+				}
+			}
 			if debuglog.GetLevel() >= debuglog.Wire {
 				debuglog.Debug(debuglog.Wire, "LLM->FABRIC stream update type=%s content=%q\n", update.Type, update.Content)
 				if update.Usage != nil {
@@ -125,11 +533,15 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 			switch update.Type {
 			case domain.StreamTypeContent:
 				message += update.Content
+				output.Write(update.Content)
 				if !opts.SuppressThink && !opts.Quiet {
 					fmt.Print(update.Content)
 					printedStream = true
 				}
 			case domain.StreamTypeUsage:
+				if update.Usage != nil {
+					usage = update.Usage
+				}
 				if opts.ShowMetadata && update.Usage != nil && !opts.Quiet {
 					fmt.Fprintf(
 						os.Stderr,
@@ -144,7 +556,7 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 				}
 			case domain.StreamTypeError:
 				if !opts.Quiet {
-					fmt.Fprintf(os.Stderr, "%s\n", fmt.Sprintf(i18n.T("chatter_error_stream_update"), update.Content))
+					debuglog.Error("%s\n", fmt.Sprintf(i18n.T("chatter_error_stream_update"), update.Content))
 				}
 				recordFirstStreamError(errChan, errors.New(update.Content))
 			}
@@ -157,6 +569,10 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 		// Wait for goroutine to finish
 		<-done
 
+		if outputErr := output.Close(); outputErr != nil {
+			recordFirstStreamError(errChan, outputErr)
+		}
+
 		// Check for errors in errChan
 		select {
 		case streamErr := <-errChan:
@@ -168,53 +584,98 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 			// No errors, continue
 		}
 	} else {
-		if message, err = o.vendor.Send(ctx, session.GetVendorMessages(), opts); err != nil {
+		if reporter, ok := o.vendor.(ai.UsageReporter); ok {
+			message, usage, err = reporter.SendWithUsage(ctx, messages, opts)
+		} else {
+			message, err = o.vendor.Send(ctx, messages, opts)
+		}
+		if err != nil {
 			return
 		}
 		if debuglog.GetLevel() >= debuglog.Wire {
 			debuglog.Debug(debuglog.Wire, "LLM->FABRIC response content=%q\n", message)
 		}
 	}
+	return
+}
 
-	if opts.SuppressThink && !o.DryRun {
-		message = domain.StripThinkBlocks(message, opts.ThinkStartTag, opts.ThinkEndTag)
+// summarizeOverflow condenses the conversational turns before the latest
+// message into a single system message, using the configured summary pattern,
+// so a request that exceeded the context window can be retried. System
+// messages, which carry the pattern and context, and the latest message are
+// kept unchanged. It returns nil when summarizing cannot make the request fit:
+// there are no earlier turns, the kept messages alone exceed the known context
+// length, or not even the newest turn fits in a summary request.
+func (o *Chatter) summarizeOverflow(ctx context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret []*chat.ChatCompletionMessage, err error) {
+	latest := messages[len(messages)-1]
+	var kept, turns []*chat.ChatCompletionMessage
+	for _, msg := range messages[:len(messages)-1] {
+		if msg.Role == chat.ChatMessageRoleSystem {
+			kept = append(kept, msg)
+		} else {
+			turns = append(turns, msg)
+		}
 	}
-
-	if message == "" {
-		session = nil
-		err = errors.New(i18n.T("chatter_error_empty_response"))
-		return
+	if len(turns) == 0 {
+		return nil, nil
+	}
+	limit := opts.ModelContextLength
+	if limit > 0 && domain.EstimateMessagesTokens(append(slices.Clone(kept), latest)) >= limit {
+		debuglog.Debug(debuglog.Basic, "Context length exceeded by messages that cannot be summarized, not retrying\n")
+		return nil, nil
 	}
 
-	// Process file changes for create_coding_feature pattern
-	if request.PatternName == "create_coding_feature" {
-		summary, fileChanges, parseErr := domain.ParseFileChanges(message)
-		if parseErr != nil {
-			fmt.Printf("%s\n", fmt.Sprintf(i18n.T("chatter_warning_parse_file_changes_failed"), parseErr))
-		} else if len(fileChanges) > 0 {
-			projectRoot, err := os.Getwd()
-			if err != nil {
-				fmt.Printf("%s\n", fmt.Sprintf(i18n.T("chatter_warning_get_current_directory_failed"), err))
-			} else {
-				if applyErr := domain.ApplyFileChanges(projectRoot, fileChanges); applyErr != nil {
-					fmt.Printf("%s\n", fmt.Sprintf(i18n.T("chatter_warning_apply_file_changes_failed"), applyErr))
-				} else {
-					fmt.Println(i18n.T("chatter_info_file_changes_applied_successfully"))
-					fmt.Printf("%s\n\n", i18n.T("chatter_help_review_changes_with_git_diff"))
-				}
-			}
+	patternName := opts.OverflowSummaryPattern
+	if patternName == "" {
+		patternName = domain.DefaultOverflowSummaryPattern
+	}
+	// Drop the oldest turns until the summary request itself fits
+	var pattern *fsdb.Pattern
+	for {
+		var history strings.Builder
+		for _, msg := range turns {
+			fmt.Fprintf(&history, "%s: %s\n\n", msg.Role, msg.Content)
 		}
-		message = summary
+		if pattern, err = o.db.Patterns.GetApplyVariables(patternName, nil, history.String()); err != nil {
+			return nil, fmt.Errorf(i18n.T("chatter_error_summarize_overflow"), err)
+		}
+		if limit <= 0 || domain.EstimateTokens(pattern.Pattern) < limit {
+			break
+		}
+		if len(turns) == 1 {
+			debuglog.Debug(debuglog.Basic, "Summary request for the earlier messages exceeds the context length, not retrying\n")
+			return nil, nil
+		}
+		turns = turns[1:]
 	}
 
-	session.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: message})
+	debuglog.Debug(debuglog.Basic, "Context length exceeded, summarizing %d earlier messages with pattern %s\n", len(turns), patternName)
 
-	if session.Name != "" {
-		err = o.db.Sessions.SaveSession(session)
+	summaryOpts := *opts
+	summaryOpts.UpdateChan = nil
+	var summary string
+	if summary, err = o.vendor.Send(ctx, []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: pattern.Pattern}}, &summaryOpts); err != nil {
+		return nil, fmt.Errorf(i18n.T("chatter_error_summarize_overflow"), err)
 	}
+
+	ret = append(kept, &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleSystem, Content: summary}, latest)
 	return
 }
 
+// applyPatternTemperatureHint uses the temperature suggested by the pattern
+// text. Callers only ask for it when no temperature was chosen explicitly.
+func (o *Chatter) applyPatternTemperatureHint(request *domain.ChatRequest, opts *domain.ChatOptions) {
+	if request.PatternName == "" {
+		return
+	}
+	pattern, err := o.db.Patterns.GetWithoutVariables(request.PatternName, "")
+	if err != nil || pattern.SuggestedTemperature == nil {
+		return
+	}
+	debuglog.Debug(debuglog.Basic, "Using temperature %.2f suggested by pattern %s\n", *pattern.SuggestedTemperature, request.PatternName)
+	opts.Temperature = *pattern.SuggestedTemperature
+}
+
 func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *fsdb.Session, err error) {
 	if request.SessionName != "" {
 		var sess *fsdb.Session
@@ -261,6 +722,7 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 	}
 
 	var patternContent string
+	responseLanguage := request.Language
 	inputUsed := false
 	if request.PatternName != "" {
 		var pattern *fsdb.Pattern
@@ -274,25 +736,51 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 			return nil, fmt.Errorf(i18n.T("chatter_error_get_pattern"), request.PatternName, err)
 		}
 		patternContent = pattern.Pattern
+		if responseLanguage == "" {
+			responseLanguage = pattern.Language
+		}
 		inputUsed = true
 	}
+	if responseLanguage == "" {
+		responseLanguage = request.DefaultLanguage
+	}
 
-	systemMessage := joinPromptSections(contextContent, patternContent)
+	contextSeparator := request.ContextSeparator
+	if contextSeparator == "" {
+		contextSeparator = domain.DefaultContextSeparator
+	}
+	systemMessage := joinPromptSectionsWith(contextSeparator, contextContent, patternContent)
 
 	if request.StrategyName != "" {
-		strategy, err := strategy.LoadStrategy(request.StrategyName)
-		if err != nil {
-			return nil, fmt.Errorf(i18n.T("chatter_error_load_strategy"), request.StrategyName, err)
-		}
-		if strategy != nil && strategy.Prompt != "" {
-			systemMessage = joinPromptSections(strategy.Prompt, systemMessage)
+		// Several strategies can be combined as a comma-separated list; their
+		// prompts are stacked in order ahead of the system message.
+		var strategyPrompts []string
+		for name := range strings.SplitSeq(request.StrategyName, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			strategy, err := strategy.LoadStrategy(name)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.T("chatter_error_load_strategy"), name, err)
+			}
+			if strategy != nil && strategy.Prompt != "" {
+				strategyPrompts = append(strategyPrompts, strategy.Prompt)
+			}
 		}
+		systemMessage = joinPromptSections(append(strategyPrompts, systemMessage)...)
 	}
 
-	// Apply refined language instruction if specified
-	if request.Language != "" && request.Language != "en" {
-		// Refined instruction: Execute pattern using user input, then translate the entire response.
-		systemMessage = fmt.Sprintf(i18n.T("chatter_prompt_enforce_response_language"), systemMessage, request.Language)
+	// Apply refined language instruction if specified by the user, the
+	// pattern's frontmatter or the configured default, in that order
+	if responseLanguage != "" {
+		languageName, ok := domain.LanguageName(responseLanguage)
+		if !ok {
+			return nil, fmt.Errorf(i18n.T("chatter_error_unknown_language"), responseLanguage)
+		}
+		if languageName != "English" {
+			// Refined instruction: Execute pattern using user input, then translate the entire response.
+			systemMessage = fmt.Sprintf(i18n.T("chatter_prompt_enforce_response_language"), systemMessage, languageName)
+		}
 	}
 
 	if raw {
@@ -351,3 +839,54 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 	}
 	return
 }
+
+// streamOutput copies streamed content to a writer as it arrives. After the
+// first write error it stops writing and Close reports the error. A nil
+// *streamOutput discards everything.
+type streamOutput struct {
+	w      io.Writer
+	filter *domain.ThinkStreamFilter
+	err    error
+}
+
+// newStreamOutput returns a streamOutput writing to w, or nil when w is nil.
+// Thinking sections are removed when opts suppresses them.
+func newStreamOutput(w io.Writer, opts *domain.ChatOptions) *streamOutput {
+	if w == nil {
+		return nil
+	}
+	output := &streamOutput{w: w}
+	if opts.SuppressThink {
+		output.filter = domain.NewThinkStreamFilter(opts.ThinkTags())
+	}
+	return output
+}
+
+func (s *streamOutput) Write(content string) {
+	if s == nil {
+		return
+	}
+	if s.filter != nil {
+		content = s.filter.Write(content)
+	}
+	s.write(content)
+}
+
+// Close writes any text the think filter held back and returns the first
+// write error.
+func (s *streamOutput) Close() error {
+	if s == nil {
+		return nil
+	}
+	if s.filter != nil {
+		s.write(s.filter.Flush())
+	}
+	return s.err
+}
+
+func (s *streamOutput) write(content string) {
+	if s.err != nil || content == "" {
+		return
+	}
+	_, s.err = io.WriteString(s.w, content)
+}
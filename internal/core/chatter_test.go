@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 )
 
@@ -193,6 +199,80 @@ func TestChatter_Send_SuppressThink(t *testing.T) {
 	}
 }
 
+func TestChatter_Send_SuppressThinkTagPairs(t *testing.T) {
+	vendor := &mockVendor{
+		sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			return "<thinking>a</thinking>one <reasoning>b</reasoning>two", nil
+		},
+	}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model"}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test"},
+	}
+	opts := &domain.ChatOptions{
+		SuppressThink: true,
+		ThinkStartTag: "<think>",
+		ThinkEndTag:   "</think>",
+		ThinkTagPairs: []domain.ThinkTagPair{
+			{Start: "<thinking>", End: "</thinking>"},
+			{Start: "<reasoning>", End: "</reasoning>"},
+		},
+	}
+
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if got := session.GetLastMessage().Content; got != "one two" {
+		t.Errorf("expected filtered content %q, got %q", "one two", got)
+	}
+}
+
+func TestChatter_Send_EmptyStreamedResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunks      []string
+		wantErr     bool
+		wantContent string
+	}{
+		{name: "whitespace only", chunks: []string{"  ", "\n\t"}, wantErr: true},
+		{name: "think only", chunks: []string{"<think>weighing", " options</think>", "\n"}, wantContent: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates []domain.StreamUpdate
+			for _, chunk := range tt.chunks {
+				updates = append(updates, domain.StreamUpdate{Type: domain.StreamTypeContent, Content: chunk})
+			}
+			chatter := &Chatter{
+				db:     fsdb.NewDb(t.TempDir()),
+				vendor: &mockVendor{streamChunks: updates},
+				model:  "test-model",
+				Stream: true,
+			}
+			request := &domain.ChatRequest{
+				Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test"},
+			}
+			opts := &domain.ChatOptions{Quiet: true, SuppressThink: true, ThinkStartTag: "<think>", ThinkEndTag: "</think>"}
+
+			session, err := chatter.Send(context.Background(), request, opts)
+			if tt.wantErr {
+				if err == nil || err.Error() != i18n.T("chatter_error_empty_response") {
+					t.Fatalf("expected empty response error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			if got := session.GetLastMessage().Content; got != tt.wantContent {
+				t.Errorf("expected content %q, got %q", tt.wantContent, got)
+			}
+		})
+	}
+}
+
 func TestChatter_BuildSession_SeparatesSystemSections(t *testing.T) {
 	tempDir := t.TempDir()
 	db := fsdb.NewDb(tempDir)
@@ -253,7 +333,7 @@ func TestChatter_BuildSession_SeparatesSystemSections(t *testing.T) {
 		t.Fatalf("expected first message to be system, got %s", systemMessage.Role)
 	}
 
-	expectedSystemMessage := "STRATEGY\nCONTEXT\nPATTERN\nuser input"
+	expectedSystemMessage := "STRATEGY\nCONTEXT\n\nPATTERN\nuser input"
 	if systemMessage.Content != expectedSystemMessage {
 		t.Fatalf("expected system message %q, got %q", expectedSystemMessage, systemMessage.Content)
 	}
@@ -263,6 +343,208 @@ func TestChatter_BuildSession_SeparatesSystemSections(t *testing.T) {
 	}
 }
 
+func TestChatter_BuildSession_MultipleStrategies(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(filepath.Join(db.Patterns.Dir, "test-pattern"), 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Patterns.Dir, "test-pattern", "system.md"), []byte("PATTERN"), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	strategyDir := filepath.Join(homeDir, ".config", "fabric", "strategies")
+	if err := os.MkdirAll(strategyDir, 0o755); err != nil {
+		t.Fatalf("failed to create strategy directory: %v", err)
+	}
+	for name, prompt := range map[string]string{"cot": "THINK STEP BY STEP", "cite": "CITE SOURCES"} {
+		if err := os.WriteFile(filepath.Join(strategyDir, name+".json"), []byte(`{"prompt":"`+prompt+`"}`), 0o644); err != nil {
+			t.Fatalf("failed to write strategy: %v", err)
+		}
+	}
+
+	chatter := &Chatter{db: db}
+	newRequest := func(strategies string) *domain.ChatRequest {
+		return &domain.ChatRequest{
+			PatternName:  "test-pattern",
+			StrategyName: strategies,
+			Message:      &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "input"},
+		}
+	}
+
+	session, err := chatter.BuildSession(newRequest("cot, cite"), false)
+	if err != nil {
+		t.Fatalf("BuildSession returned error: %v", err)
+	}
+	expected := "THINK STEP BY STEP\nCITE SOURCES\nPATTERN\ninput"
+	if got := session.GetVendorMessages()[0].Content; got != expected {
+		t.Errorf("expected system message %q, got %q", expected, got)
+	}
+
+	_, err = chatter.BuildSession(newRequest("cot,missing"), false)
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("expected an error naming the missing strategy, got %v", err)
+	}
+}
+
+func TestChatter_BuildSession_WithMeta(t *testing.T) {
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir())}
+	request := &domain.ChatRequest{
+		Meta: "project: fabric\nfabric -p summarize",
+		Message: &chat.ChatCompletionMessage{
+			Role:    chat.ChatMessageRoleUser,
+			Content: "user input",
+		},
+	}
+
+	session, err := chatter.BuildSession(request, false)
+	if err != nil {
+		t.Fatalf("BuildSession returned error: %v", err)
+	}
+
+	if len(session.Messages) == 0 || session.Messages[0].Role != domain.ChatMessageRoleMeta {
+		t.Fatalf("expected the session to start with a meta message, got %+v", session.Messages)
+	}
+	if session.Messages[0].Content != request.Meta {
+		t.Fatalf("expected meta content %q, got %q", request.Meta, session.Messages[0].Content)
+	}
+	for _, message := range session.GetVendorMessages() {
+		if message.Role == domain.ChatMessageRoleMeta || strings.Contains(message.Content, "project: fabric") {
+			t.Fatalf("meta message leaked into vendor messages: %+v", message)
+		}
+	}
+}
+
+func TestChatter_BuildSession_ContextSeparator(t *testing.T) {
+	tempDir := t.TempDir()
+	db := fsdb.NewDb(tempDir)
+
+	if err := os.MkdirAll(filepath.Join(db.Patterns.Dir, "test-pattern"), 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	if err := os.MkdirAll(db.Contexts.Dir, 0o755); err != nil {
+		t.Fatalf("failed to create context directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Patterns.Dir, "test-pattern", "system.md"), []byte("# IDENTITY\nPATTERN"), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Contexts.Dir, "test-context"), []byte("last context line\n"), 0o644); err != nil {
+		t.Fatalf("failed to write context: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contextName string
+		separator   string
+		expected    string
+	}{
+		{
+			name:        "default separator",
+			contextName: "test-context",
+			expected:    "last context line\n\n# IDENTITY\nPATTERN\ninput",
+		},
+		{
+			name:        "custom separator",
+			contextName: "test-context",
+			separator:   "\n---\n",
+			expected:    "last context line\n---\n# IDENTITY\nPATTERN\ninput",
+		},
+		{
+			name:      "no context keeps pattern unchanged",
+			separator: "\n---\n",
+			expected:  "# IDENTITY\nPATTERN\ninput",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatter := &Chatter{db: db}
+			request := &domain.ChatRequest{
+				ContextName:      tt.contextName,
+				PatternName:      "test-pattern",
+				ContextSeparator: tt.separator,
+				Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "input"},
+			}
+
+			session, err := chatter.BuildSession(request, false)
+			if err != nil {
+				t.Fatalf("BuildSession returned error: %v", err)
+			}
+			if got := session.GetVendorMessages()[0].Content; got != tt.expected {
+				t.Errorf("expected system message %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestChatter_BuildSession_PatternLanguage(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(filepath.Join(db.Patterns.Dir, "french"), 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Patterns.Dir, "french", "system.md"), []byte("---\nlanguage: fr\n---\nSummarize"), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(db.Patterns.Dir, "plain"), 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Patterns.Dir, "plain", "system.md"), []byte("Summarize"), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		language        string
+		defaultLanguage string
+		pattern         string
+		want            string
+		wantErr         bool
+	}{
+		{name: "pattern language applies by default", want: "ONLY in the French language"},
+		{name: "pattern language overrides configured default", defaultLanguage: "de", want: "ONLY in the French language"},
+		{name: "configured default applies without pattern language", defaultLanguage: "de", pattern: "plain", want: "ONLY in the German language"},
+		{name: "user language overrides pattern", language: "de", want: "ONLY in the German language"},
+		{name: "language name", language: "spanish", want: "ONLY in the Spanish language"},
+		{name: "regional tag keeps its subtags", language: "pt-BR", want: "ONLY in the Portuguese (pt-BR) language"},
+		{name: "unknown language", language: "klingonese", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatter := &Chatter{db: db}
+			pattern := tt.pattern
+			if pattern == "" {
+				pattern = "french"
+			}
+			request := &domain.ChatRequest{
+				PatternName:     pattern,
+				Language:        tt.language,
+				DefaultLanguage: tt.defaultLanguage,
+				Message:         &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "input"},
+			}
+
+			session, err := chatter.BuildSession(request, false)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.language) {
+					t.Fatalf("expected an error naming %q, got %v", tt.language, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSession returned error: %v", err)
+			}
+			got := session.GetVendorMessages()[0].Content
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("expected system message to contain %q, got %q", tt.want, got)
+			}
+			if tt.language != "" && strings.Contains(got, "ONLY in the French language") {
+				t.Errorf("expected user language to replace the pattern language, got %q", got)
+			}
+		})
+	}
+}
+
 func TestChatter_Send_StreamingErrorPropagation(t *testing.T) {
 	// Create a temporary database for testing
 	tempDir := t.TempDir()
@@ -519,3 +801,936 @@ func TestChatter_Send_StreamingMetadataPropagation(t *testing.T) {
 		t.Error("Expected to receive a usage metadata update, but didn't")
 	}
 }
+
+func TestChatter_Send_PatternTemperatureHint(t *testing.T) {
+	tests := []struct {
+		name        string
+		useHint     bool
+		temperature float64
+		expected    float64
+	}{
+		{name: "hint disabled", useHint: false, temperature: domain.DefaultTemperature, expected: domain.DefaultTemperature},
+		{name: "hint applied to default temperature", useHint: true, temperature: domain.DefaultTemperature, expected: domain.PreciseTemperatureHint},
+		{name: "hint replaces another temperature", useHint: true, temperature: 0.5, expected: domain.PreciseTemperatureHint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := fsdb.NewDb(t.TempDir())
+			patternDir := filepath.Join(db.Patterns.Dir, "extract")
+			if err := os.MkdirAll(patternDir, 0o755); err != nil {
+				t.Fatalf("failed to create pattern directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(patternDir, "system.md"), []byte("Be precise."), 0o644); err != nil {
+				t.Fatalf("failed to write pattern: %v", err)
+			}
+
+			var sentTemperature float64
+			vendor := &mockVendor{
+				sendFunc: func(_ context.Context, _ []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+					sentTemperature = opts.Temperature
+					return "ok", nil
+				},
+			}
+			chatter := &Chatter{db: db, vendor: vendor, model: "test-model"}
+
+			request := &domain.ChatRequest{
+				PatternName: "extract",
+				Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "be creative"},
+			}
+			opts := &domain.ChatOptions{Temperature: tt.temperature, UsePatternTemperatureHint: tt.useHint}
+
+			if _, err := chatter.Send(context.Background(), request, opts); err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			if sentTemperature != tt.expected {
+				t.Errorf("expected temperature %v, got %v", tt.expected, sentTemperature)
+			}
+		})
+	}
+}
+
+func TestChatter_Send_MaxSentences(t *testing.T) {
+	vendor := &mockVendor{
+		sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			return "One. Two. Three.", nil
+		},
+	}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model"}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "count"},
+	}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{MaxSentences: 2})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if got := session.GetLastMessage().Content; got != "One. Two." {
+		t.Errorf("expected response limited to two sentences, got %q", got)
+	}
+}
+
+func TestChatter_Send_MaxRepeatChunks(t *testing.T) {
+	repeated := make([]domain.StreamUpdate, 10)
+	for i := range repeated {
+		repeated[i] = domain.StreamUpdate{Type: domain.StreamTypeContent, Content: "again "}
+	}
+
+	tests := []struct {
+		name      string
+		chunks    []domain.StreamUpdate
+		limit     int
+		wantAbort bool
+	}{
+		{name: "aborts on runaway repetition", chunks: repeated, limit: 3, wantAbort: true},
+		{name: "disabled when zero", chunks: repeated, limit: 0},
+		{
+			name: "non-consecutive repeats are allowed",
+			chunks: []domain.StreamUpdate{
+				{Type: domain.StreamTypeContent, Content: "a"},
+				{Type: domain.StreamTypeContent, Content: "b"},
+				{Type: domain.StreamTypeContent, Content: "a"},
+				{Type: domain.StreamTypeContent, Content: "b"},
+			},
+			limit: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: &mockVendor{streamChunks: tt.chunks}, model: "test-model", Stream: true}
+			request := &domain.ChatRequest{
+				Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "loop"},
+			}
+
+			_, err := chatter.Send(context.Background(), request, &domain.ChatOptions{MaxRepeatChunks: tt.limit, Quiet: true})
+			if tt.wantAbort {
+				if err == nil || !strings.Contains(err.Error(), "repeated more than 3 consecutive times") {
+					t.Fatalf("expected repeated chunk abort, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestChatter_Send_AutoSummarizeOnOverflow(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	for name, content := range map[string]string{
+		"condense": "Condense:\n{{input}}",
+		"analyst":  "You are an analyst.\n{{input}}",
+	} {
+		patternDir := filepath.Join(db.Patterns.Dir, name)
+		if err := os.MkdirAll(patternDir, 0o755); err != nil {
+			t.Fatalf("failed to create pattern directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(patternDir, "system.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write pattern: %v", err)
+		}
+	}
+	if err := os.MkdirAll(db.Sessions.Dir, 0o755); err != nil {
+		t.Fatalf("failed to create sessions directory: %v", err)
+	}
+	history := `[{"role":"user","content":"very long history"},{"role":"assistant","content":"earlier answer"}]`
+	if err := os.WriteFile(filepath.Join(db.Sessions.Dir, "history.json"), []byte(history), 0o644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+
+	var calls [][]*chat.ChatCompletionMessage
+	vendor := &mockVendor{
+		sendFunc: func(_ context.Context, msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+			calls = append(calls, msgs)
+			switch len(calls) {
+			case 1:
+				return "", errors.New("This model's maximum context length is 10 tokens")
+			case 2:
+				return "short summary", nil
+			default:
+				return "final answer", nil
+			}
+		},
+	}
+	chatter := &Chatter{db: db, vendor: vendor, model: "test-model"}
+
+	request := &domain.ChatRequest{
+		SessionName: "history",
+		PatternName: "analyst",
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+	opts := &domain.ChatOptions{AutoSummarizeOnOverflow: true, OverflowSummaryPattern: "condense"}
+
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("expected original, summary and retry calls, got %d", len(calls))
+	}
+	summaryRequest := calls[1][0].Content
+	if !strings.HasPrefix(summaryRequest, "Condense:") || !strings.Contains(summaryRequest, "very long history") {
+		t.Errorf("expected summary request to apply the pattern to earlier turns, got %q", summaryRequest)
+	}
+	if strings.Contains(summaryRequest, "You are an analyst.") {
+		t.Errorf("expected the pattern message to be left out of the summary, got %q", summaryRequest)
+	}
+	patternMessage := calls[0][len(calls[0])-1]
+	retry := calls[2]
+	if len(retry) != 2 || retry[0].Role != chat.ChatMessageRoleSystem || retry[0].Content != "short summary" {
+		t.Fatalf("expected retry with the summary followed by the pattern message, got %+v", retry)
+	}
+	if retry[1] != patternMessage || !strings.HasPrefix(retry[1].Content, "You are an analyst.") || !strings.Contains(retry[1].Content, "question") {
+		t.Errorf("expected the pattern message to survive the retry unchanged, got %+v", retry[1])
+	}
+	if got := session.GetLastMessage().Content; got != "final answer" {
+		t.Errorf("expected final answer, got %q", got)
+	}
+}
+
+func TestChatter_Send_AutoSummarizeOversizedInput(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Sessions.Dir, 0o755); err != nil {
+		t.Fatalf("failed to create sessions directory: %v", err)
+	}
+	history := `[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]`
+	if err := os.WriteFile(filepath.Join(db.Sessions.Dir, "history.json"), []byte(history), 0o644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+
+	calls := 0
+	vendor := &mockVendor{
+		sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			calls++
+			return "", errors.New("context_length_exceeded")
+		},
+	}
+	chatter := &Chatter{db: db, vendor: vendor, model: "test-model", modelContextLength: 50}
+	request := &domain.ChatRequest{
+		SessionName: "history",
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: strings.Repeat("x", 400)},
+	}
+
+	_, err := chatter.Send(context.Background(), request, &domain.ChatOptions{AutoSummarizeOnOverflow: true, Quiet: true})
+	if !domain.IsContextLengthError(err) {
+		t.Fatalf("expected the original context length error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no summary or retry for an input that cannot fit, got %d calls", calls)
+	}
+}
+
+func TestChatter_Send_ContextLengthEstimate(t *testing.T) {
+	history := `[{"role":"user","content":"` + strings.Repeat("x", 400) + `"},{"role":"assistant","content":"` + strings.Repeat("y", 400) + `"}]`
+
+	tests := []struct {
+		name         string
+		truncate     bool
+		wantMessages int
+		wantStderr   string
+	}{
+		{name: "warns by default", wantMessages: 3, wantStderr: "exceeds the model context length of 50"},
+		{name: "truncates oldest messages", truncate: true, wantMessages: 1, wantStderr: "Dropped 2 older message(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := fsdb.NewDb(t.TempDir())
+			if err := os.MkdirAll(db.Sessions.Dir, 0o755); err != nil {
+				t.Fatalf("failed to create sessions directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(db.Sessions.Dir, "history.json"), []byte(history), 0o644); err != nil {
+				t.Fatalf("failed to write session: %v", err)
+			}
+
+			var sent []*chat.ChatCompletionMessage
+			vendor := &mockVendor{
+				sendFunc: func(_ context.Context, msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+					sent = msgs
+					return "answer", nil
+				},
+			}
+			chatter := &Chatter{db: db, vendor: vendor, model: "test-model", modelContextLength: 50}
+			request := &domain.ChatRequest{
+				SessionName: "history",
+				Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+			}
+
+			var output bytes.Buffer
+			debuglog.SetOutput(&output)
+			_, err := chatter.Send(context.Background(), request, &domain.ChatOptions{TruncateContext: tt.truncate})
+			debuglog.SetOutput(os.Stderr)
+			if err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+
+			if len(sent) != tt.wantMessages {
+				t.Fatalf("expected %d messages sent, got %d", tt.wantMessages, len(sent))
+			}
+			if sent[len(sent)-1].Content != "question" {
+				t.Errorf("expected the latest message to be kept, got %q", sent[len(sent)-1].Content)
+			}
+			if !strings.Contains(output.String(), tt.wantStderr) {
+				t.Errorf("expected stderr to contain %q, got %q", tt.wantStderr, output.String())
+			}
+		})
+	}
+}
+
+func TestChatter_Send_OverflowWithoutAutoSummarize(t *testing.T) {
+	calls := 0
+	vendor := &mockVendor{
+		sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			calls++
+			return "", errors.New("context_length_exceeded")
+		},
+	}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model"}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+
+	if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{}); !domain.IsContextLengthError(err) {
+		t.Fatalf("expected context length error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry, got %d calls", calls)
+	}
+}
+
+func TestChatter_Send_ReturnRawResponse(t *testing.T) {
+	vendor := &mockVendor{
+		sendFunc: func(_ context.Context, _ []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+			ai.DumpRawResponse(opts, []byte(`{"content":"<think>reasoning</think>Answer. Extra."}`))
+			return "<think>reasoning</think>Answer. Extra.", nil
+		},
+	}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model"}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+	opts := &domain.ChatOptions{
+		ReturnRawResponse: true,
+		SuppressThink:     true,
+		ThinkStartTag:     "<think>",
+		ThinkEndTag:       "</think>",
+		MaxSentences:      1,
+	}
+
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if got := session.GetLastMessage().Content; got != "Answer." {
+		t.Errorf("expected processed message %q, got %q", "Answer.", got)
+	}
+	if want := `{"content":"<think>reasoning</think>Answer. Extra."}`; session.RawResponse != want {
+		t.Errorf("expected raw response %q, got %q", want, session.RawResponse)
+	}
+	if opts.RawResponseHandler != nil {
+		t.Error("expected the raw response handler to be cleared after Send")
+	}
+}
+
+func TestChatter_Send_ReturnRawResponseNotCaptured(t *testing.T) {
+	vendor := &mockVendor{
+		sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			return "Answer.", nil
+		},
+	}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model"}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{ReturnRawResponse: true})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if session.RawResponse != "" {
+		t.Errorf("expected no raw response from a vendor that does not capture one, got %q", session.RawResponse)
+	}
+}
+
+// countingVendor counts the requests that reach the wrapped mock vendor.
+type countingVendor struct {
+	*mockVendor
+	calls int
+}
+
+func (c *countingVendor) Send(ctx context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	c.calls++
+	return c.mockVendor.Send(ctx, messages, opts)
+}
+
+func (c *countingVendor) SendStream(ctx context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions, responseChan chan domain.StreamUpdate) error {
+	c.calls++
+	return c.mockVendor.SendStream(ctx, messages, opts, responseChan)
+}
+
+func TestChatter_Send_ResponseCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		stream      bool
+		allowStream bool
+		wantCalls   int
+	}{
+		{name: "non-streaming uses cache", wantCalls: 1},
+		{name: "streaming bypasses cache", stream: true, wantCalls: 2},
+		{name: "streaming uses cache when allowed", stream: true, allowStream: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vendor := &countingVendor{mockVendor: &mockVendor{
+				streamChunks: []domain.StreamUpdate{{Type: domain.StreamTypeContent, Content: "test response"}},
+			}}
+			cache := NewResponseCache(t.TempDir(), time.Hour)
+			cache.AllowStream = tt.allowStream
+			chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model", Stream: tt.stream, Cache: cache}
+
+			for range 2 {
+				request := &domain.ChatRequest{
+					Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+				}
+				session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Quiet: true})
+				if err != nil {
+					t.Fatalf("Send returned error: %v", err)
+				}
+				if got := session.GetLastMessage().Content; got != "test response" {
+					t.Errorf("expected %q, got %q", "test response", got)
+				}
+			}
+			if vendor.calls != tt.wantCalls {
+				t.Errorf("expected %d vendor calls, got %d", tt.wantCalls, vendor.calls)
+			}
+		})
+	}
+}
+
+// usageVendor reports fixed token usage for non-streaming requests.
+type usageVendor struct {
+	*mockVendor
+	usage *domain.UsageMetadata
+}
+
+func (u *usageVendor) SendWithUsage(ctx context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, *domain.UsageMetadata, error) {
+	ret, err := u.Send(ctx, messages, opts)
+	return ret, u.usage, err
+}
+
+func TestChatter_Send_Usage(t *testing.T) {
+	usage := &domain.UsageMetadata{InputTokens: 7, OutputTokens: 3, TotalTokens: 10}
+	tests := []struct {
+		name   string
+		vendor ai.Vendor
+		stream bool
+		want   *domain.UsageMetadata
+	}{
+		{name: "usage reporter", vendor: &usageVendor{mockVendor: &mockVendor{}, usage: usage}, want: usage},
+		{name: "vendor without usage", vendor: &mockVendor{}},
+		{
+			name: "stream usage update",
+			vendor: &mockVendor{streamChunks: []domain.StreamUpdate{
+				{Type: domain.StreamTypeContent, Content: "test response"},
+				{Type: domain.StreamTypeUsage, Usage: usage},
+			}},
+			stream: true,
+			want:   usage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: tt.vendor, model: "test-model", Stream: tt.stream}
+			request := &domain.ChatRequest{
+				Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+			}
+
+			session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Quiet: true})
+			if err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			if tt.want == nil {
+				if session.Usage != nil {
+					t.Errorf("expected no usage, got %+v", session.Usage)
+				}
+			} else if session.Usage == nil || *session.Usage != *tt.want {
+				t.Errorf("expected usage %+v, got %+v", tt.want, session.Usage)
+			}
+		})
+	}
+}
+
+func TestChatter_Send_StripThinkFromSaved(t *testing.T) {
+	const response = "<think>reasoning</think>answer"
+	tests := []struct {
+		name   string
+		vendor *mockVendor
+		stream bool
+	}{
+		{
+			name: "non-streaming",
+			vendor: &mockVendor{sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+				return response, nil
+			}},
+		},
+		{
+			name: "streaming",
+			vendor: &mockVendor{streamChunks: []domain.StreamUpdate{
+				{Type: domain.StreamTypeContent, Content: "<think>reasoning</think>"},
+				{Type: domain.StreamTypeContent, Content: "answer"},
+			}},
+			stream: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := fsdb.NewDb(t.TempDir())
+			if err := os.MkdirAll(db.Sessions.Dir, 0o755); err != nil {
+				t.Fatalf("failed to create sessions directory: %v", err)
+			}
+			chatter := &Chatter{db: db, vendor: tt.vendor, model: "test-model", Stream: tt.stream}
+			request := &domain.ChatRequest{
+				SessionName: "thinking",
+				Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+			}
+			opts := &domain.ChatOptions{
+				Quiet:               true,
+				StripThinkFromSaved: true,
+				ThinkStartTag:       "<think>",
+				ThinkEndTag:         "</think>",
+			}
+
+			session, err := chatter.Send(context.Background(), request, opts)
+			if err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			if got := session.GetLastMessage().Content; got != response {
+				t.Errorf("expected returned session to keep thinking, got %q", got)
+			}
+
+			saved, err := db.Sessions.Get("thinking")
+			if err != nil {
+				t.Fatalf("failed to load saved session: %v", err)
+			}
+			if got := saved.GetLastMessage().Content; got != "answer" {
+				t.Errorf("expected saved session without thinking, got %q", got)
+			}
+			if len(saved.Messages) != len(session.Messages) {
+				t.Errorf("expected %d saved messages, got %d", len(session.Messages), len(saved.Messages))
+			}
+		})
+	}
+}
+
+func TestChatter_Send_ProjectRoot(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(filepath.Join(db.Patterns.Dir, "create_coding_feature"), 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Patterns.Dir, "create_coding_feature", "system.md"), []byte("Write code"), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+
+	vendor := &mockVendor{
+		sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			return "Added main.go\n" + domain.FileChangesMarker + "\n" +
+				`[{"operation":"create","path":"cmd/main.go","content":"package main\n"}]`, nil
+		},
+	}
+	projectRoot := t.TempDir()
+	chatter := &Chatter{db: db, vendor: vendor, model: "test-model", ProjectRoot: projectRoot}
+	request := &domain.ChatRequest{
+		PatternName: "create_coding_feature",
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "add main"},
+	}
+
+	if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(projectRoot, "cmd", "main.go"))
+	if err != nil {
+		t.Fatalf("expected file under project root: %v", err)
+	}
+	if string(content) != "package main\n" {
+		t.Errorf("unexpected file content %q", content)
+	}
+}
+
+func TestChatter_ResolveProjectRoot(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		projectRoot string
+		want        string
+		wantErr     bool
+	}{
+		{name: "defaults to working directory", want: cwd},
+		{name: "existing directory", projectRoot: dir, want: dir},
+		{name: "missing directory", projectRoot: filepath.Join(dir, "missing"), wantErr: true},
+		{name: "file instead of directory", projectRoot: file, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatter := &Chatter{ProjectRoot: tt.projectRoot}
+			got, err := chatter.resolveProjectRoot()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got root %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveProjectRoot returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfirmFileChanges(t *testing.T) {
+	projectRoot := t.TempDir()
+	changes := []domain.FileChange{{Operation: "create", Path: "main.go", Content: "package main\n"}}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.input), func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := confirmFileChanges(projectRoot, changes, strings.NewReader(tt.input), &out)
+			if err != nil {
+				t.Fatalf("confirmFileChanges returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			for _, want := range []string{"create main.go (13 bytes)", "+package main", "[y/N]"} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected preview to contain %q, got %q", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+// fileSizeRecorder writes to a file and records the file size after each write.
+type fileSizeRecorder struct {
+	file  *os.File
+	sizes []int64
+}
+
+func (r *fileSizeRecorder) Write(p []byte) (int, error) {
+	n, err := r.file.Write(p)
+	if info, statErr := r.file.Stat(); statErr == nil {
+		r.sizes = append(r.sizes, info.Size())
+	}
+	return n, err
+}
+
+func TestChatter_Send_StreamOutputWritesIncrementally(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "out.txt")
+	file, err := os.Create(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	recorder := &fileSizeRecorder{file: file}
+
+	chatter := &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		Stream: true,
+		vendor: &mockVendor{streamChunks: []domain.StreamUpdate{
+			{Type: domain.StreamTypeContent, Content: "<think>plan"},
+			{Type: domain.StreamTypeContent, Content: " more</think>\n"},
+			{Type: domain.StreamTypeContent, Content: "Hello "},
+			{Type: domain.StreamTypeContent, Content: "world"},
+		}},
+		model:        "test-model",
+		StreamOutput: recorder,
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+	opts := &domain.ChatOptions{
+		Model:         "test-model",
+		Quiet:         true,
+		SuppressThink: true,
+		ThinkStartTag: "<think>",
+		ThinkEndTag:   "</think>",
+	}
+
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if got := session.GetLastMessage().Content; got != "Hello world" {
+		t.Errorf("expected session message %q, got %q", "Hello world", got)
+	}
+	if want := []int64{6, 11}; !slices.Equal(recorder.sizes, want) {
+		t.Errorf("expected the file to grow per chunk as %v, got %v", want, recorder.sizes)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Hello world" {
+		t.Errorf("expected file content %q, got %q", "Hello world", content)
+	}
+}
+
+func TestChatter_Send_ModelFallback(t *testing.T) {
+	var attempted []string
+	vendor := &mockVendor{sendFunc: func(_ context.Context, _ []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+		attempted = append(attempted, opts.Model)
+		switch opts.Model {
+		case "model-b":
+			return "answer from b", nil
+		case "model-bad":
+			return "", errors.New("400 Bad Request: invalid request")
+		default:
+			return "", errors.New("429 Too Many Requests")
+		}
+	}}
+	resolve := func(model string) (*Chatter, error) {
+		if model == "missing" {
+			return nil, errors.New("model not found")
+		}
+		return &Chatter{vendor: vendor, model: model}, nil
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+
+	tests := []struct {
+		name          string
+		fallbacks     []string
+		wantAttempted []string
+		wantMessage   string
+		wantErr       []string
+	}{
+		{name: "falls back to the next model", fallbacks: []string{"missing", "model-b", "model-c"}, wantAttempted: []string{"model-a", "model-b"}, wantMessage: "answer from b"},
+		{name: "lists every attempted model", fallbacks: []string{"missing", "model-c"}, wantAttempted: []string{"model-a", "model-c"}, wantErr: []string{"model-a: 429", "missing: model not found", "model-c: 429"}},
+		{name: "stops at a non-retriable error", fallbacks: []string{"model-bad", "model-b"}, wantAttempted: []string{"model-a", "model-bad"}, wantErr: []string{"model-a: 429", "model-bad: 400"}},
+		{name: "no fallbacks", wantAttempted: []string{"model-a"}, wantErr: []string{"429 Too Many Requests"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempted = nil
+			chatter := &Chatter{
+				db:             fsdb.NewDb(t.TempDir()),
+				vendor:         vendor,
+				model:          "model-a",
+				FallbackModels: tt.fallbacks,
+				resolveModel:   resolve,
+			}
+
+			session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Quiet: true})
+
+			if !slices.Equal(attempted, tt.wantAttempted) {
+				t.Errorf("expected attempts %v, got %v", tt.wantAttempted, attempted)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Send returned error: %v", err)
+				}
+				if got := session.GetLastMessage().Content; got != tt.wantMessage {
+					t.Errorf("expected message %q, got %q", tt.wantMessage, got)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error %q to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+// namedVendor is a mockVendor with its own name that can require raw mode.
+type namedVendor struct {
+	mockVendor
+	name    string
+	needRaw bool
+}
+
+func (v *namedVendor) GetName() string            { return v.name }
+func (v *namedVendor) NeedsRawMode(_ string) bool { return v.needRaw }
+
+func TestChatter_Send_ModelFallbackPreparesMessages(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	patternDir := filepath.Join(db.Patterns.Dir, "analyst")
+	if err := os.MkdirAll(patternDir, 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(patternDir, "system.md"), []byte("You are an analyst."), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+
+	var primaryMessages []*chat.ChatCompletionMessage
+	primary := &mockVendor{sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+		primaryMessages = messages
+		return "", errors.New("429 Too Many Requests")
+	}}
+	var fallbackMessages []*chat.ChatCompletionMessage
+	var fallbackOpts domain.ChatOptions
+	fallback := &namedVendor{name: "raw", needRaw: true}
+	fallback.sendFunc = func(_ context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+		fallbackMessages, fallbackOpts = messages, *opts
+		return "answer", nil
+	}
+	chatter := &Chatter{
+		db:             db,
+		vendor:         primary,
+		model:          "model-a",
+		FallbackModels: []string{"model-b"},
+		resolveModel: func(model string) (*Chatter, error) {
+			return &Chatter{vendor: fallback, model: model, modelContextLength: 4096}, nil
+		},
+	}
+	request := &domain.ChatRequest{
+		PatternName: "analyst",
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+
+	if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Quiet: true, ModelContextLength: 100000}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if len(primaryMessages) == 0 || primaryMessages[0].Role != chat.ChatMessageRoleSystem {
+		t.Fatalf("expected the primary model to get the pattern as a system message, got %+v", primaryMessages)
+	}
+	if len(fallbackMessages) != 1 || fallbackMessages[0].Role != chat.ChatMessageRoleUser ||
+		!strings.Contains(fallbackMessages[0].Content, "You are an analyst.") || !strings.Contains(fallbackMessages[0].Content, "question") {
+		t.Errorf("expected the raw-mode fallback to get one user message with the pattern and input, got %+v", fallbackMessages)
+	}
+	if fallbackOpts.Model != "model-b" || !fallbackOpts.Raw || fallbackOpts.ModelContextLength != 4096 {
+		t.Errorf("expected options for the fallback model, got model %q raw %v context length %d",
+			fallbackOpts.Model, fallbackOpts.Raw, fallbackOpts.ModelContextLength)
+	}
+}
+
+func TestChatter_Send_ModelFallbackNotCached(t *testing.T) {
+	primary := &countingVendor{mockVendor: &mockVendor{sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+		return "", errors.New("503 Service Unavailable")
+	}}}
+	// The fallback serves a model of the same name from another vendor
+	fallback := &namedVendor{name: "other"}
+	fallback.sendFunc = func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+		return "fallback answer", nil
+	}
+	chatter := &Chatter{
+		db:             fsdb.NewDb(t.TempDir()),
+		vendor:         primary,
+		model:          "model-a",
+		FallbackModels: []string{"other/model-a"},
+		Cache:          NewResponseCache(t.TempDir(), time.Hour),
+		resolveModel: func(string) (*Chatter, error) {
+			return &Chatter{vendor: fallback, model: "model-a"}, nil
+		},
+	}
+
+	for range 2 {
+		request := &domain.ChatRequest{
+			Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+		}
+		session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Quiet: true})
+		if err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+		if got := session.GetLastMessage().Content; got != "fallback answer" {
+			t.Errorf("expected %q, got %q", "fallback answer", got)
+		}
+	}
+	if primary.calls != 2 {
+		t.Errorf("expected the primary model to be asked again instead of a cached fallback answer, got %d calls", primary.calls)
+	}
+
+	messages := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "question"}}
+	if _, _, cached := chatter.cachedResponse(messages, &domain.ChatOptions{Model: "model-a"}); cached {
+		t.Error("expected no cached response for the primary model")
+	}
+}
+
+// cancelingVendor streams its chunks, then cancels the request as a user
+// pressing Ctrl-C would and waits for the cancellation to arrive.
+type cancelingVendor struct {
+	mockVendor
+	cancel context.CancelFunc
+}
+
+func (v *cancelingVendor) SendStream(ctx context.Context, _ []*chat.ChatCompletionMessage, _ *domain.ChatOptions, responseChan chan domain.StreamUpdate) error {
+	defer close(responseChan)
+	for _, chunk := range v.streamChunks {
+		responseChan <- chunk
+	}
+	v.cancel()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestChatter_Send_CanceledStreamSavesPartialSession(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Sessions.Dir, 0o755); err != nil {
+		t.Fatalf("failed to create sessions directory: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vendor := &cancelingVendor{
+		mockVendor: mockVendor{streamChunks: []domain.StreamUpdate{
+			{Type: domain.StreamTypeContent, Content: "partial "},
+			{Type: domain.StreamTypeContent, Content: "answer"},
+		}},
+		cancel: cancel,
+	}
+	chatter := &Chatter{db: db, vendor: vendor, model: "test-model", Stream: true}
+	request := &domain.ChatRequest{
+		SessionName: "interrupted",
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+
+	_, err := chatter.Send(ctx, request, &domain.ChatOptions{Quiet: true})
+	if !errors.Is(err, ErrCanceledByUser) {
+		t.Fatalf("expected ErrCanceledByUser, got %v", err)
+	}
+
+	saved, err := db.Sessions.Get("interrupted")
+	if err != nil {
+		t.Fatalf("failed to load saved session: %v", err)
+	}
+	if len(saved.Messages) != 2 {
+		t.Fatalf("expected the question and the partial answer, got %d messages", len(saved.Messages))
+	}
+	last := saved.GetLastMessage()
+	if last.Role != chat.ChatMessageRoleAssistant || last.Content != "partial answer" {
+		t.Errorf("expected partial assistant message, got %s %q", last.Role, last.Content)
+	}
+}
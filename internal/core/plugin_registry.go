@@ -24,10 +24,12 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/ai/dryrun"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/exolab"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/gemini"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/llamacpp"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/lmstudio"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/ollama"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/openai_compatible"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openaicompat"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/perplexity"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/vertexai"
 	"github.com/danielmiessler/fabric/internal/plugins/strategy"
@@ -84,11 +86,13 @@ func NewPluginRegistry(db *fsdb.Db) (ret *PluginRegistry, err error) {
 		anthropic.NewClient(),
 		vertexai.NewClient(),
 		lmstudio.NewClient(),
+		llamacpp.NewClient(),
 		exolab.NewClient(),
 		perplexity.NewClient(),
 		codex.NewClient(),
-		copilot.NewClient(), // Microsoft 365 Copilot
-		bedrock.NewClient(), // AWS Bedrock - credentials configured via setup or AWS credential chain
+		copilot.NewClient(),      // Microsoft 365 Copilot
+		bedrock.NewClient(),      // AWS Bedrock - credentials configured via setup or AWS credential chain
+		openaicompat.NewClient(), // Any OpenAI-compatible endpoint configured by base URL
 	)
 
 	// Add all OpenAI-compatible providers
@@ -120,6 +124,10 @@ func (o *PluginRegistry) ListVendors(out io.Writer) error {
 	return nil
 }
 
+// ProviderPriorityEnv names the environment variable holding an ordered, comma-separated
+// list of vendor names consulted when no explicit model is requested.
+const ProviderPriorityEnv = "FABRIC_PROVIDER_PRIORITY"
+
 type PluginRegistry struct {
 	Db *fsdb.Db
 
@@ -480,6 +488,7 @@ func (o *PluginRegistry) GetChatter(model string, modelContextLength int, vendor
 		Stream: stream,
 		DryRun: dryRun,
 	}
+	ret.resolveModel = o.resolveModel
 
 	defaultModel := o.Defaults.Model.Value
 	defaultModelContextLength, err := strconv.Atoi(o.Defaults.ModelContextLength.Value)
@@ -503,12 +512,16 @@ func (o *PluginRegistry) GetChatter(model string, modelContextLength int, vendor
 			ret.model = defaultModel
 		}
 	} else if model == "" {
+		ret.model = defaultModel
 		if vendorName != "" {
 			ret.vendor = vendorManager.FindByName(vendorName)
+		} else if priority := parseProviderPriority(os.Getenv(ProviderPriorityEnv)); len(priority) > 0 {
+			if ret.vendor, ret.model, err = o.selectPriorityVendor(priority, defaultVendor, defaultModel); err != nil {
+				return
+			}
 		} else {
 			ret.vendor = vendorManager.FindByName(defaultVendor)
 		}
-		ret.model = defaultModel
 	} else {
 		var models *ai.VendorsModels
 		if models, err = vendorManager.GetModels(); err != nil {
@@ -581,3 +594,48 @@ func (o *PluginRegistry) GetChatter(model string, modelContextLength int, vendor
 	}
 	return
 }
+
+// resolveModel returns a chatter for model, using the same rules as an
+// explicit --model, including the "vendor/model" form.
+func (o *PluginRegistry) resolveModel(model string) (*Chatter, error) {
+	return o.GetChatter(model, 0, "", false, false)
+}
+
+// parseProviderPriority splits a comma-separated vendor list, dropping blank entries.
+func parseProviderPriority(value string) (ret []string) {
+	for name := range strings.SplitSeq(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ret = append(ret, name)
+		}
+	}
+	return
+}
+
+// selectPriorityVendor returns the first configured vendor from priority together
+// with the model to use for it. The default model is kept when the chosen vendor is
+// the default vendor; otherwise the vendor's first listed model is used. Vendors
+// that are not configured or list no models are skipped. When no priority entry
+// matches, the default vendor is returned.
+func (o *PluginRegistry) selectPriorityVendor(priority []string, defaultVendor, defaultModel string) (vendor ai.Vendor, model string, err error) {
+	var models *ai.VendorsModels
+	for _, name := range priority {
+		if vendor = o.VendorManager.FindByName(name); vendor == nil {
+			continue
+		}
+		if strings.EqualFold(vendor.GetName(), defaultVendor) && defaultModel != "" {
+			return vendor, defaultModel, nil
+		}
+		if models == nil {
+			if models, err = o.VendorManager.GetModels(); err != nil {
+				return nil, "", err
+			}
+		}
+		for _, groupItems := range models.FilterByVendor(vendor.GetName()).GroupsItems {
+			if len(groupItems.Items) > 0 {
+				return vendor, groupItems.Items[0], nil
+			}
+		}
+		debuglog.Debug(debuglog.Basic, "Skipping priority vendor %s: no models available\n", vendor.GetName())
+	}
+	return o.VendorManager.FindByName(defaultVendor), defaultModel, nil
+}
@@ -210,3 +210,96 @@ func TestGetChatter_VendorPrefixIgnoredWhenNotAVendor(t *testing.T) {
 		t.Fatalf("expected model 'notavendor/model', got %s", chatter.model)
 	}
 }
+
+func TestGetChatter_ProviderPriority(t *testing.T) {
+	tests := []struct {
+		name           string
+		priority       string
+		expectedVendor string
+		expectedModel  string
+	}{
+		{
+			name:           "first configured vendor in priority wins",
+			priority:       "VendorC,VendorB,VendorA",
+			expectedVendor: "VendorB",
+			expectedModel:  "model-b1",
+		},
+		{
+			name:           "default vendor keeps default model",
+			priority:       "vendora, VendorB",
+			expectedVendor: "VendorA",
+			expectedModel:  "model-a2",
+		},
+		{
+			name:           "no configured vendor falls back to default",
+			priority:       "VendorC,VendorD",
+			expectedVendor: "VendorA",
+			expectedModel:  "model-a2",
+		},
+		{
+			name:           "empty priority uses default",
+			priority:       " , ",
+			expectedVendor: "VendorA",
+			expectedModel:  "model-a2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProviderPriorityEnv, tt.priority)
+
+			vm := ai.NewVendorsManager()
+			vm.AddVendors(
+				&testVendor{name: "VendorA", models: []string{"model-a1", "model-a2"}},
+				&testVendor{name: "VendorB", models: []string{"model-b1", "model-b2"}},
+			)
+
+			defaults := &tools.Defaults{
+				PluginBase:         &plugins.PluginBase{},
+				Vendor:             &plugins.Setting{Value: "VendorA"},
+				Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "model-a2"}},
+				ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+			}
+
+			registry := &PluginRegistry{Db: fsdb.NewDb(t.TempDir()), VendorManager: vm, Defaults: defaults}
+
+			chatter, err := registry.GetChatter("", 0, "", false, false)
+			if err != nil {
+				t.Fatalf("GetChatter() error = %v", err)
+			}
+			if chatter.vendor.GetName() != tt.expectedVendor {
+				t.Errorf("expected vendor %s, got %s", tt.expectedVendor, chatter.vendor.GetName())
+			}
+			if chatter.model != tt.expectedModel {
+				t.Errorf("expected model %s, got %s", tt.expectedModel, chatter.model)
+			}
+		})
+	}
+}
+
+func TestGetChatter_ProviderPriorityIgnoredWithExplicitVendor(t *testing.T) {
+	t.Setenv(ProviderPriorityEnv, "VendorB")
+
+	vm := ai.NewVendorsManager()
+	vm.AddVendors(
+		&testVendor{name: "VendorA", models: []string{"model-a"}},
+		&testVendor{name: "VendorB", models: []string{"model-b"}},
+	)
+
+	defaults := &tools.Defaults{
+		PluginBase:         &plugins.PluginBase{},
+		Vendor:             &plugins.Setting{Value: "VendorA"},
+		Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "model-a"}},
+		ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+	}
+
+	registry := &PluginRegistry{Db: fsdb.NewDb(t.TempDir()), VendorManager: vm, Defaults: defaults}
+
+	chatter, err := registry.GetChatter("", 0, "VendorA", false, false)
+	if err != nil {
+		t.Fatalf("GetChatter() error = %v", err)
+	}
+	if chatter.vendor.GetName() != "VendorA" {
+		t.Errorf("expected explicit vendor VendorA, got %s", chatter.vendor.GetName())
+	}
+}
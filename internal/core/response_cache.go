@@ -0,0 +1,122 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// ResponseCacheDirName is the directory under the fabric config directory that
+// holds cached responses.
+const ResponseCacheDirName = "cache"
+
+// ResponseCache stores assistant responses on disk, keyed by a hash of the
+// vendor messages, the model and the options that affect the output.
+type ResponseCache struct {
+	Dir string
+	// TTL is how long an entry stays valid; zero means entries never expire.
+	TTL time.Duration
+	// AllowStream enables the cache for streaming requests, which bypass it by default.
+	AllowStream bool
+}
+
+type responseCacheEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Message   string    `json:"message"`
+}
+
+// responseCacheKey lists everything that identifies a request. Options that only
+// affect local presentation (quiet, metadata, think stripping) are left out.
+type responseCacheKey struct {
+	Vendor           string                        `json:"vendor"`
+	Model            string                        `json:"model"`
+	Messages         []*chat.ChatCompletionMessage `json:"messages"`
+	Temperature      float64                       `json:"temperature"`
+	TopP             float64                       `json:"top_p"`
+	PresencePenalty  float64                       `json:"presence_penalty"`
+	FrequencyPenalty float64                       `json:"frequency_penalty"`
+	Raw              bool                          `json:"raw"`
+	UseDeveloperRole bool                          `json:"use_developer_role"`
+	Seed             *int                          `json:"seed"`
+	StopSequences    []string                      `json:"stop_sequences"`
+	LogitBias        map[string]float64            `json:"logit_bias"`
+	Thinking         domain.ThinkingLevel          `json:"thinking"`
+	MaxTokens        int                           `json:"max_tokens"`
+	Search           bool                          `json:"search"`
+	SearchLocation   string                        `json:"search_location"`
+	SearchDomains    []string                      `json:"search_domains"`
+	SearchRecency    string                        `json:"search_recency"`
+}
+
+// NewResponseCache creates a cache that stores entries in dir.
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{Dir: dir, TTL: ttl}
+}
+
+// Key returns the cache key for sending messages to vendor with opts.
+func (c *ResponseCache) Key(vendor string, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	data, err := json.Marshal(responseCacheKey{
+		Vendor:           vendor,
+		Model:            opts.Model,
+		Messages:         messages,
+		Temperature:      opts.Temperature,
+		TopP:             opts.TopP,
+		PresencePenalty:  opts.PresencePenalty,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		Raw:              opts.Raw,
+		UseDeveloperRole: opts.UseDeveloperRole,
+		Seed:             opts.Seed,
+		StopSequences:    opts.StopSequences,
+		LogitBias:        opts.LogitBias,
+		Thinking:         opts.Thinking,
+		MaxTokens:        opts.MaxTokens,
+		Search:           opts.Search,
+		SearchLocation:   opts.SearchLocation,
+		SearchDomains:    opts.SearchDomains,
+		SearchRecency:    opts.SearchRecency,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the cached message for key. Missing, unreadable and expired
+// entries are reported as misses.
+func (c *ResponseCache) Get(key string) (message string, ok bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	var entry responseCacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	if c.TTL > 0 && time.Since(entry.CreatedAt) > c.TTL {
+		return "", false
+	}
+	return entry.Message, true
+}
+
+// Put stores message under key.
+func (c *ResponseCache) Put(key, message string) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(responseCacheEntry{CreatedAt: time.Now(), Message: message})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), data, 0o644)
+}
+
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+func TestResponseCache_Key(t *testing.T) {
+	cache := NewResponseCache(t.TempDir(), 0)
+	messages := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+
+	base, err := cache.Key("mock", messages, &domain.ChatOptions{Model: "a", Temperature: 0.7})
+	if err != nil {
+		t.Fatalf("Key returned error: %v", err)
+	}
+	same, _ := cache.Key("mock", messages, &domain.ChatOptions{Model: "a", Temperature: 0.7, Quiet: true})
+	if same != base {
+		t.Errorf("expected presentation-only options to keep the key, got %q and %q", base, same)
+	}
+
+	for name, opts := range map[string]*domain.ChatOptions{
+		"model":       {Model: "b", Temperature: 0.7},
+		"temperature": {Model: "a", Temperature: 0.2},
+	} {
+		key, _ := cache.Key("mock", messages, opts)
+		if key == base {
+			t.Errorf("expected a different key when %s changes", name)
+		}
+	}
+	other, _ := cache.Key("other", messages, &domain.ChatOptions{Model: "a", Temperature: 0.7})
+	if other == base {
+		t.Error("expected a different key for another vendor")
+	}
+}
+
+func TestResponseCache_GetPut(t *testing.T) {
+	cache := NewResponseCache(t.TempDir(), time.Hour)
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Fatal("expected a miss for an unknown key")
+	}
+	if err := cache.Put("key", "answer"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if got, ok := cache.Get("key"); !ok || got != "answer" {
+		t.Errorf("expected cached %q, got %q (ok=%v)", "answer", got, ok)
+	}
+
+	cache.TTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected expired entry to be a miss")
+	}
+
+	cache.TTL = 0
+	if _, ok := cache.Get("key"); !ok {
+		t.Error("expected entry to never expire with a zero TTL")
+	}
+}
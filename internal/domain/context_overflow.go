@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+)
+
+// charsPerToken is the rough number of characters per token used to estimate
+// prompt sizes without a vendor tokenizer.
+const charsPerToken = 4
+
+// messageTokenOverhead approximates the tokens each message adds for its role
+// and framing.
+const messageTokenOverhead = 4
+
+// DefaultOverflowSummaryPattern is the pattern used to condense earlier context
+// when a request exceeds the model's context window.
+const DefaultOverflowSummaryPattern = "summarize"
+
+// contextLengthErrorMarkers are lowercase fragments that vendors use in errors
+// returned when the prompt does not fit in the model's context window.
+var contextLengthErrorMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context length exceeded",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"exceeds the maximum number of tokens",
+}
+
+// IsContextLengthError reports whether err looks like a vendor error caused by
+// exceeding the model's context window.
+func IsContextLengthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, marker := range contextLengthErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimateTokens approximates the number of tokens in text.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateMessagesTokens approximates the number of tokens messages take up in
+// a request. Only text content is counted.
+func EstimateMessagesTokens(messages []*chat.ChatCompletionMessage) (ret int) {
+	for _, message := range messages {
+		ret += messageTokenOverhead + EstimateTokens(message.Content)
+		for _, part := range message.MultiContent {
+			ret += EstimateTokens(part.Text)
+		}
+	}
+	return
+}
+
+// TruncateMessages drops the oldest messages, other than system messages and
+// the latest message, until the estimate fits in limit or nothing else can be
+// dropped. It reports how many messages were dropped.
+func TruncateMessages(messages []*chat.ChatCompletionMessage, limit int) (ret []*chat.ChatCompletionMessage, dropped int) {
+	ret = messages
+	for EstimateMessagesTokens(ret) > limit {
+		index := -1
+		for i, message := range ret[:len(ret)-1] {
+			if message.Role != chat.ChatMessageRoleSystem {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			break
+		}
+		ret = append(ret[:index:index], ret[index+1:]...)
+		dropped++
+	}
+	return
+}
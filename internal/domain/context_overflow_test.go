@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+)
+
+func TestIsContextLengthError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "openai code", err: errors.New(`400 Bad Request {"code": "context_length_exceeded"}`), want: true},
+		{name: "openai message", err: errors.New("This model's maximum context length is 8192 tokens"), want: true},
+		{name: "anthropic message", err: errors.New("prompt is too long: 210000 tokens > 200000 maximum"), want: true},
+		{name: "unrelated", err: errors.New("401 unauthorized"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsContextLengthError(tt.err); got != tt.want {
+				t.Errorf("IsContextLengthError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Errorf("EstimateTokens(\"\") = %d, want 0", got)
+	}
+	if got := EstimateTokens("abcdefgh"); got != 2 {
+		t.Errorf("EstimateTokens(8 chars) = %d, want 2", got)
+	}
+	if got := EstimateTokens("héllo"); got != 2 {
+		t.Errorf("EstimateTokens counts runes, got %d, want 2", got)
+	}
+
+	messages := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "abcd"},
+		{Role: chat.ChatMessageRoleUser, MultiContent: []chat.ChatMessagePart{{Type: chat.ChatMessagePartTypeText, Text: "abcdefgh"}}},
+	}
+	if got := EstimateMessagesTokens(messages); got != 2*messageTokenOverhead+3 {
+		t.Errorf("EstimateMessagesTokens = %d, want %d", got, 2*messageTokenOverhead+3)
+	}
+}
+
+func TestTruncateMessages(t *testing.T) {
+	long := strings.Repeat("x", 400)
+	system := &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleSystem, Content: "rules"}
+	oldUser := &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: long}
+	oldReply := &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: long}
+	latest := &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"}
+	messages := []*chat.ChatCompletionMessage{system, oldUser, oldReply, latest}
+
+	got, dropped := TruncateMessages(messages, 150)
+	if dropped != 1 || len(got) != 3 || got[0] != system || got[1] != oldReply || got[2] != latest {
+		t.Errorf("expected only the oldest message dropped, got %d dropped: %+v", dropped, got)
+	}
+	if len(messages) != 4 || messages[1] != oldUser {
+		t.Errorf("expected the input slice to be left unchanged, got %+v", messages)
+	}
+
+	got, dropped = TruncateMessages(messages, 10)
+	if dropped != 2 || len(got) != 2 || got[0] != system || got[1] != latest {
+		t.Errorf("expected the system and latest messages to be kept, got %d dropped: %+v", dropped, got)
+	}
+
+	got, dropped = TruncateMessages(messages, 1000)
+	if dropped != 0 || len(got) != 4 {
+		t.Errorf("expected messages that fit to be kept, got %d dropped", dropped)
+	}
+}
@@ -4,6 +4,10 @@ import "github.com/danielmiessler/fabric/internal/chat"
 
 const ChatMessageRoleMeta = "meta"
 
+// DefaultContextSeparator separates context content from pattern content in the
+// system message when no separator is configured.
+const DefaultContextSeparator = "\n\n"
+
 // Default values for chat options (must match cli/flags.go defaults)
 const (
 	DefaultTemperature      = 0.7
@@ -19,10 +23,12 @@ type ChatRequest struct {
 	PatternVariables      map[string]string
 	Message               *chat.ChatCompletionMessage
 	Language              string
+	DefaultLanguage       string // used when neither Language nor the pattern sets one
 	Meta                  string
 	InputHasVars          bool
 	NoVariableReplacement bool
 	StrategyName          string
+	ContextSeparator      string
 }
 
 type ChatOptions struct {
@@ -32,28 +38,51 @@ type ChatOptions struct {
 	PresencePenalty     float64
 	FrequencyPenalty    float64
 	Raw                 bool
-	Seed                int
+	UseDeveloperRole    bool
+	Seed                *int
+	StopSequences       []string
+	LogitBias           map[string]float64
 	Thinking            ThinkingLevel
 	ModelContextLength  int
 	MaxTokens           int
+	MaxSentences        int
+	MaxRepeatChunks     int
 	Search              bool
 	SearchLocation      string
+	SearchDomains       []string
+	SearchRecency       string
 	ImageFile           string
 	ImageSize           string
 	ImageQuality        string
 	ImageCompression    int
 	ImageBackground     string
 	SuppressThink       bool
+	StripThinkFromSaved bool
 	ThinkStartTag       string
 	ThinkEndTag         string
+	ThinkTagPairs       []ThinkTagPair
 	AudioOutput         bool
 	AudioFormat         string
 	Voice               string
 	Notification        bool
 	NotificationCommand string
 	ShowMetadata        bool
+	ReturnRawResponse   bool
+	DumpRawFile         string
+	TruncateContext     bool
 	Quiet               bool
 	UpdateChan          chan StreamUpdate `json:"-"`
+
+	// UsePatternTemperatureHint applies the temperature suggested by the
+	// pattern text. Callers leave it unset when a temperature was chosen.
+	UsePatternTemperatureHint bool
+	// AutoSummarizeOnOverflow retries a request that overflows the context
+	// with earlier turns summarized by OverflowSummaryPattern.
+	AutoSummarizeOnOverflow bool
+	OverflowSummaryPattern  string
+	// RawResponseHandler receives each vendor response body as it is dumped
+	// for DumpRawFile. The slice must not be retained.
+	RawResponseHandler func(data []byte) `json:"-"`
 }
 
 // NormalizeMessages remove empty messages and ensure messages order user-assist-user
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Extraction modes understood by NewExtractor.
+const (
+	// ExtractCode selects the body of the first fenced code block.
+	ExtractCode = "code"
+	// ExtractJSON selects the first JSON object or array.
+	ExtractJSON = "json"
+)
+
+// fencedCodeBlockPattern matches a ``` fenced block, capturing its body
+// without the info string.
+var fencedCodeBlockPattern = regexp.MustCompile("(?s)```[^\n]*\n(.*?)```")
+
+// Extractor pulls one part out of a response, such as a code block, a JSON
+// value or a regular expression capture.
+type Extractor struct {
+	mode  string
+	regex *regexp.Regexp
+}
+
+// NewExtractor returns an extractor for mode (ExtractCode or ExtractJSON) or,
+// when pattern is set, for the first capture group of that regular
+// expression. Exactly one of mode and pattern must be set.
+func NewExtractor(mode, pattern string) (ret *Extractor, err error) {
+	switch {
+	case mode != "" && pattern != "":
+		return nil, errors.New(i18n.T("extract_mode_and_regex_conflict"))
+	case pattern != "":
+		var regex *regexp.Regexp
+		if regex, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf(i18n.T("extract_invalid_regex"), pattern, err)
+		}
+		return &Extractor{regex: regex}, nil
+	case mode == ExtractCode || mode == ExtractJSON:
+		return &Extractor{mode: mode}, nil
+	default:
+		return nil, fmt.Errorf(i18n.T("extract_invalid_mode"), mode)
+	}
+}
+
+// Extract returns the selected part of content, or an error when content has
+// no such part.
+func (e *Extractor) Extract(content string) (string, error) {
+	if e.regex != nil {
+		return ExtractRegex(content, e.regex)
+	}
+	if e.mode == ExtractCode {
+		if block, ok := ExtractCodeBlock(content); ok {
+			return block, nil
+		}
+		return "", errors.New(i18n.T("extract_no_code_block"))
+	}
+	if value, ok := ExtractJSONValue(content); ok {
+		return value, nil
+	}
+	return "", errors.New(i18n.T("extract_no_json"))
+}
+
+// ExtractCodeBlock returns the body of the first fenced code block in content.
+func ExtractCodeBlock(content string) (string, bool) {
+	match := fencedCodeBlockPattern.FindStringSubmatch(content)
+	if match == nil {
+		return "", false
+	}
+	return strings.TrimSuffix(match[1], "\n"), true
+}
+
+// ExtractJSONValue returns the first JSON object or array in content, as
+// written in content.
+func ExtractJSONValue(content string) (string, bool) {
+	for i := 0; i < len(content); i++ {
+		if content[i] != '{' && content[i] != '[' {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(content[i:]))
+		var value json.RawMessage
+		if decoder.Decode(&value) == nil {
+			return string(bytes.TrimSpace(value)), true
+		}
+	}
+	return "", false
+}
+
+// ExtractRegex returns the first capture group of the first match of regex in
+// content, or the whole match when regex has no groups.
+func ExtractRegex(content string, regex *regexp.Regexp) (string, error) {
+	match := regex.FindStringSubmatch(content)
+	if match == nil {
+		return "", fmt.Errorf(i18n.T("extract_regex_no_match"), regex.String())
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
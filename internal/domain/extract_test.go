@@ -0,0 +1,69 @@
+package domain
+
+import "testing"
+
+func TestExtractor(t *testing.T) {
+	response := "Here you go:\n\n```go\nfmt.Println(\"hi\")\n```\n\nResult: {\"name\": \"fabric\", \"tags\": [1, 2]} and [3]\nVersion: v1.4.2\n"
+
+	tests := []struct {
+		name    string
+		mode    string
+		pattern string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "code block", mode: ExtractCode, input: response, want: "fmt.Println(\"hi\")"},
+		{name: "code block without info string", mode: ExtractCode, input: "```\na\nb\n```", want: "a\nb"},
+		{name: "no code block", mode: ExtractCode, input: "plain text", wantErr: true},
+		{name: "json object", mode: ExtractJSON, input: response, want: `{"name": "fabric", "tags": [1, 2]}`},
+		{name: "json array", mode: ExtractJSON, input: "values: [1, {\"a\": 2}] done", want: `[1, {"a": 2}]`},
+		{name: "json skips invalid braces", mode: ExtractJSON, input: "use {braces} then {\"ok\": true}", want: `{"ok": true}`},
+		{name: "no json", mode: ExtractJSON, input: "no {json} here", wantErr: true},
+		{name: "regex capture group", pattern: `Version: (v[\d.]+)`, input: response, want: "v1.4.2"},
+		{name: "regex without group", pattern: `v\d+`, input: response, want: "v1"},
+		{name: "regex no match", pattern: `Missing: (\w+)`, input: response, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := NewExtractor(tt.mode, tt.pattern)
+			if err != nil {
+				t.Fatalf("NewExtractor returned error: %v", err)
+			}
+			got, err := extractor.Extract(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewExtractorErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		pattern string
+	}{
+		{name: "unknown mode", mode: "yaml"},
+		{name: "invalid regex", pattern: "("},
+		{name: "mode and regex", mode: ExtractCode, pattern: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExtractor(tt.mode, tt.pattern); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
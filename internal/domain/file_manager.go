@@ -2,13 +2,16 @@ package domain
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
 // FileChangesMarker identifies the start of a file changes section in output
@@ -21,9 +24,10 @@ const (
 
 // FileChange represents a single file change operation to be performed
 type FileChange struct {
-	Operation string `json:"operation"` // "create" or "update"
-	Path      string `json:"path"`      // Relative path from project root
-	Content   string `json:"content"`   // New file content
+	Operation string `json:"operation"`          // "create", "update", "delete" or "rename"
+	Path      string `json:"path"`               // Relative path from project root
+	NewPath   string `json:"new_path,omitempty"` // Destination path for "rename"
+	Content   string `json:"content"`            // New file content
 }
 
 // ParseFileChanges extracts and parses the file change marker section from LLM output
@@ -81,30 +85,50 @@ func ParseFileChanges(output string) (changeSummary string, changes []FileChange
 		}
 	}
 
-	// Validate file changes
-	for i, change := range fileChanges {
+	if err = validateFileChanges(fileChanges); err != nil {
+		return changeSummary, nil, err
+	}
+
+	return changeSummary, fileChanges, nil
+}
+
+// validateFileChanges checks the operation, paths and content size of each
+// parsed file change.
+func validateFileChanges(changes []FileChange) error {
+	for i, change := range changes {
 		// Validate operation
-		if change.Operation != "create" && change.Operation != "update" {
-			return changeSummary, nil, fmt.Errorf(i18n.T("file_manager_invalid_operation"), i, change.Operation)
+		switch change.Operation {
+		case "create", "update", "delete", "rename":
+		default:
+			return fmt.Errorf(i18n.T("file_manager_invalid_operation"), i, change.Operation)
 		}
 
 		// Validate path
 		if change.Path == "" {
-			return changeSummary, nil, fmt.Errorf(i18n.T("file_manager_empty_path"), i)
+			return fmt.Errorf(i18n.T("file_manager_empty_path"), i)
 		}
 
 		// Check for suspicious paths (directory traversal)
 		if strings.Contains(change.Path, "..") {
-			return changeSummary, nil, fmt.Errorf(i18n.T("file_manager_suspicious_path"), i, change.Path)
+			return fmt.Errorf(i18n.T("file_manager_suspicious_path"), i, change.Path)
+		}
+
+		// Renames also need a safe destination
+		if change.Operation == "rename" {
+			if change.NewPath == "" {
+				return fmt.Errorf(i18n.T("file_manager_empty_new_path"), i)
+			}
+			if strings.Contains(change.NewPath, "..") {
+				return fmt.Errorf(i18n.T("file_manager_suspicious_path"), i, change.NewPath)
+			}
 		}
 
 		// Check file size
 		if len(change.Content) > MaxFileSize {
-			return changeSummary, nil, fmt.Errorf(i18n.T("file_manager_file_content_too_large"), i, len(change.Content))
+			return fmt.Errorf(i18n.T("file_manager_file_content_too_large"), i, len(change.Content))
 		}
 	}
-
-	return changeSummary, fileChanges, nil
+	return nil
 }
 
 // fixInvalidEscapes replaces invalid escape sequences in JSON strings
@@ -167,24 +191,102 @@ func fixInvalidEscapes(jsonStr string) string {
 	return result.String()
 }
 
+// FileChangeDiff returns a unified diff between the file change targets under
+// projectRoot and the content the change would write. Files that do not exist
+// yet are diffed against /dev/null, deletions are diffed to /dev/null, and
+// renames compare the file with itself; an empty result means the change would
+// not alter any file content.
+func FileChangeDiff(projectRoot string, change FileChange) (string, error) {
+	fromFile := "a/" + change.Path
+	existing, err := os.ReadFile(filepath.Join(projectRoot, change.Path))
+	if errors.Is(err, fs.ErrNotExist) {
+		fromFile = "/dev/null"
+	} else if err != nil {
+		return "", err
+	}
+
+	toFile, content := "b/"+change.Path, change.Content
+	switch change.Operation {
+	case "delete":
+		toFile, content = "/dev/null", ""
+	case "rename":
+		toFile, content = "b/"+change.NewPath, string(existing)
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(string(existing)),
+		B:        diffLines(content),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// diffLines splits content into newline-terminated lines. Unlike
+// difflib.SplitLines it does not add an empty line after a trailing newline.
+func diffLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
 // ApplyFileChanges applies the parsed file changes to the file system
 func ApplyFileChanges(projectRoot string, changes []FileChange) error {
+	return ApplyFileChangesWithProgress(projectRoot, changes, nil)
+}
+
+// ApplyFileChangesWithProgress applies the parsed file changes and, when updates
+// is not nil, sends a StreamTypeFileChange update after each applied change.
+func ApplyFileChangesWithProgress(projectRoot string, changes []FileChange, updates chan<- StreamUpdate) error {
 	for i, change := range changes {
 		// Get the absolute path
 		absPath := filepath.Join(projectRoot, change.Path)
 
-		// Create directories if necessary
-		dir := filepath.Dir(absPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf(i18n.T("file_manager_failed_create_directory"), dir, i, err)
-		}
+		switch change.Operation {
+		case "delete":
+			if err := os.Remove(absPath); err != nil {
+				return fmt.Errorf(i18n.T("file_manager_failed_delete_file"), absPath, i, err)
+			}
+		case "rename":
+			newPath := filepath.Join(projectRoot, change.NewPath)
+			dir := filepath.Dir(newPath)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf(i18n.T("file_manager_failed_create_directory"), dir, i, err)
+			}
+			if err := os.Rename(absPath, newPath); err != nil {
+				return fmt.Errorf(i18n.T("file_manager_failed_rename_file"), absPath, newPath, i, err)
+			}
+		default:
+			// Create directories if necessary
+			dir := filepath.Dir(absPath)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf(i18n.T("file_manager_failed_create_directory"), dir, i, err)
+			}
 
-		// Write the file
-		if err := os.WriteFile(absPath, []byte(change.Content), 0644); err != nil {
-			return fmt.Errorf(i18n.T("file_manager_failed_write_file"), absPath, i, err)
+			// Write the file
+			if err := os.WriteFile(absPath, []byte(change.Content), 0644); err != nil {
+				return fmt.Errorf(i18n.T("file_manager_failed_write_file"), absPath, i, err)
+			}
 		}
 
 		fmt.Printf(i18n.T("file_manager_applied_operation")+"\n", change.Operation, change.Path)
+		if updates != nil {
+			updates <- StreamUpdate{
+				Type: StreamTypeFileChange,
+				FileChange: &FileChangeProgress{
+					Operation: change.Operation,
+					Path:      change.Path,
+					Index:     i + 1,
+					Total:     len(changes),
+				},
+			}
+		}
 	}
 
 	return nil
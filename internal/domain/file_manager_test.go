@@ -64,7 +64,7 @@ Some text after.`,
 ` + FileChangesMarker + `
 [
 	{
-		"operation": "delete",
+		"operation": "chmod",
 		"path": "test.txt",
 		"content": ""
 	}
@@ -96,6 +96,64 @@ Some text after.`,
 		"path": "../etc/passwd",
 		"content": "Hello, World!"
 	}
+]`,
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "Delete and rename operations",
+			input: `Some text before.
+` + FileChangesMarker + `
+[
+	{
+		"operation": "delete",
+		"path": "old.txt"
+	},
+	{
+		"operation": "rename",
+		"path": "a.txt",
+		"new_path": "dir/b.txt"
+	}
+]`,
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "Delete with directory traversal",
+			input: `Some text before.
+` + FileChangesMarker + `
+[
+	{
+		"operation": "delete",
+		"path": "../etc/passwd"
+	}
+]`,
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "Rename without new path",
+			input: `Some text before.
+` + FileChangesMarker + `
+[
+	{
+		"operation": "rename",
+		"path": "a.txt"
+	}
+]`,
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "Rename with directory traversal in new path",
+			input: `Some text before.
+` + FileChangesMarker + `
+[
+	{
+		"operation": "rename",
+		"path": "a.txt",
+		"new_path": "../outside.txt"
+	}
 ]`,
 			want:    0,
 			wantErr: true,
@@ -183,3 +241,119 @@ func TestApplyFileChanges(t *testing.T) {
 		t.Errorf("Updated file content = %q, want %q", string(content), "Updated content")
 	}
 }
+
+func TestApplyFileChangesDeleteAndRename(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, content := range map[string]string{"old.txt": "old", "a.txt": "moved"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	changes := []FileChange{
+		{Operation: "delete", Path: "old.txt"},
+		{Operation: "rename", Path: "a.txt", NewPath: "dir/b.txt"},
+	}
+	if err := ApplyFileChanges(tempDir, changes); err != nil {
+		t.Fatalf("ApplyFileChanges() error = %v", err)
+	}
+
+	for _, name := range []string{"old.txt", "a.txt"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got err = %v", name, err)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "dir", "b.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read renamed file: %v", err)
+	}
+	if string(content) != "moved" {
+		t.Errorf("Renamed file content = %q, want %q", string(content), "moved")
+	}
+
+	if err := ApplyFileChanges(tempDir, []FileChange{{Operation: "delete", Path: "missing.txt"}}); err == nil {
+		t.Error("expected error deleting a missing file")
+	}
+}
+
+func TestApplyFileChangesWithProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	changes := []FileChange{
+		{Operation: "create", Path: "a.txt", Content: "a"},
+		{Operation: "update", Path: "dir/b.txt", Content: "b"},
+		{Operation: "create", Path: "c.txt", Content: "c"},
+	}
+
+	updates := make(chan StreamUpdate, len(changes))
+	if err := ApplyFileChangesWithProgress(tempDir, changes, updates); err != nil {
+		t.Fatalf("ApplyFileChangesWithProgress() error = %v", err)
+	}
+	close(updates)
+
+	index := 0
+	for update := range updates {
+		index++
+		if update.Type != StreamTypeFileChange || update.FileChange == nil {
+			t.Fatalf("update %d: expected file change event, got %+v", index, update)
+		}
+		change := changes[index-1]
+		want := FileChangeProgress{Operation: change.Operation, Path: change.Path, Index: index, Total: len(changes)}
+		if *update.FileChange != want {
+			t.Errorf("update %d = %+v, want %+v", index, *update.FileChange, want)
+		}
+	}
+	if index != len(changes) {
+		t.Errorf("expected %d events, got %d", len(changes), index)
+	}
+}
+
+func TestFileChangeDiff(t *testing.T) {
+	projectRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectRoot, "existing.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change FileChange
+		want   string
+	}{
+		{
+			name:   "new file",
+			change: FileChange{Operation: "create", Path: "new.txt", Content: "hello\n"},
+			want:   "--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+hello\n",
+		},
+		{
+			name:   "updated file",
+			change: FileChange{Operation: "update", Path: "existing.txt", Content: "one\nthree\n"},
+			want:   "--- a/existing.txt\n+++ b/existing.txt\n@@ -1,2 +1,2 @@\n one\n-two\n+three\n",
+		},
+		{
+			name:   "deleted file",
+			change: FileChange{Operation: "delete", Path: "existing.txt"},
+			want:   "--- a/existing.txt\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-one\n-two\n",
+		},
+		{
+			name:   "renamed file",
+			change: FileChange{Operation: "rename", Path: "existing.txt", NewPath: "moved.txt"},
+			want:   "",
+		},
+		{
+			name:   "unchanged file",
+			change: FileChange{Operation: "update", Path: "existing.txt", Content: "one\ntwo\n"},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FileChangeDiff(projectRoot, tt.change)
+			if err != nil {
+				t.Fatalf("FileChangeDiff() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FileChangeDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
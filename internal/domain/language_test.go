@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestLanguageName(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{value: "es", want: "Spanish", wantOK: true},
+		{value: "zh", want: "Chinese", wantOK: true},
+		{value: " FR ", want: "French", wantOK: true},
+		{value: "en", want: "English", wantOK: true},
+		{value: "Spanish", want: "Spanish", wantOK: true},
+		{value: "norwegian bokmål", want: "Norwegian Bokmål", wantOK: true},
+		{value: "pt-BR", want: "Portuguese (pt-BR)", wantOK: true},
+		{value: "zh-Hant", want: "Chinese (zh-Hant)", wantOK: true},
+		{value: "xx", wantOK: false},
+		{value: "fil", wantOK: false},
+		{value: "gibberish language", wantOK: false},
+		{value: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := LanguageName(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("LanguageName(%q) = %q, %v; want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"os"
+	"strings"
+)
+
+// OpenAIAPIOverridesEnv names the environment variable used to override the API
+// selection for specific models. It holds a comma-separated list of
+// "<model-prefix>=<responses|chat>" entries, e.g.
+// "my-proxy-model=chat,gpt-6=responses".
+const OpenAIAPIOverridesEnv = "FABRIC_OPENAI_API_OVERRIDES"
+
+const (
+	openAIAPIResponses = "responses"
+	openAIAPIChat      = "chat"
+)
+
+// openAIModelAPIs maps known model name prefixes to the API they must use.
+// Reasoning and agentic models that are only offered on the Responses API map to
+// true; legacy or special-purpose models that only exist on Chat Completions map
+// to false. The longest matching prefix wins.
+var openAIModelAPIs = map[string]bool{
+	"o1-pro":                       true,
+	"o3-pro":                       true,
+	"o3-deep-research":             true,
+	"o4-mini-deep-research":        true,
+	"codex-mini":                   true,
+	"computer-use-preview":         true,
+	"gpt-5-codex":                  true,
+	"gpt-5-pro":                    true,
+	"gpt-3.5-turbo-instruct":       false,
+	"gpt-4o-audio-preview":         false,
+	"gpt-4o-mini-audio-preview":    false,
+	"gpt-4o-search-preview":        false,
+	"gpt-4o-mini-search-preview":   false,
+	"gpt-4o-realtime-preview":      false,
+	"gpt-4o-mini-realtime-preview": false,
+	"babbage-002":                  false,
+	"davinci-002":                  false,
+}
+
+// OpenAIUsesResponsesAPI reports whether requests for model should be sent via
+// the OpenAI Responses API rather than Chat Completions. Entries from
+// FABRIC_OPENAI_API_OVERRIDES take precedence over the built-in table; models
+// that match neither default to the Responses API.
+func OpenAIUsesResponsesAPI(model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+
+	if useResponses, ok := matchOpenAIModelAPI(model, parseOpenAIAPIOverrides(os.Getenv(OpenAIAPIOverridesEnv))); ok {
+		return useResponses
+	}
+	if useResponses, ok := matchOpenAIModelAPI(model, openAIModelAPIs); ok {
+		return useResponses
+	}
+	return true
+}
+
+// matchOpenAIModelAPI returns the table entry for the longest prefix of model.
+func matchOpenAIModelAPI(model string, table map[string]bool) (useResponses bool, found bool) {
+	longest := -1
+	for prefix, value := range table {
+		if strings.HasPrefix(model, prefix) && len(prefix) > longest {
+			longest = len(prefix)
+			useResponses = value
+			found = true
+		}
+	}
+	return
+}
+
+// parseOpenAIAPIOverrides parses the FABRIC_OPENAI_API_OVERRIDES format.
+// Malformed entries and unknown API names are ignored.
+func parseOpenAIAPIOverrides(value string) map[string]bool {
+	overrides := make(map[string]bool)
+	for entry := range strings.SplitSeq(value, ",") {
+		prefix, api, ok := strings.Cut(entry, "=")
+		prefix = strings.ToLower(strings.TrimSpace(prefix))
+		if !ok || prefix == "" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(api)) {
+		case openAIAPIResponses:
+			overrides[prefix] = true
+		case openAIAPIChat:
+			overrides[prefix] = false
+		}
+	}
+	return overrides
+}
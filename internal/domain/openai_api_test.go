@@ -0,0 +1,45 @@
+package domain
+
+import "testing"
+
+func TestOpenAIUsesResponsesAPI(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		expected bool
+	}{
+		{name: "reasoning model requires Responses", model: "o3-pro", expected: true},
+		{name: "dated reasoning model matches prefix", model: "o1-pro-2025-03-19", expected: true},
+		{name: "legacy model uses Chat Completions", model: "gpt-3.5-turbo-instruct", expected: false},
+		{name: "search preview uses Chat Completions", model: "gpt-4o-mini-search-preview-2025-03-11", expected: false},
+		{name: "case-insensitive match", model: "GPT-4o-Search-Preview", expected: false},
+		{name: "unknown model defaults to Responses", model: "some-future-model", expected: true},
+		{name: "general model defaults to Responses", model: "gpt-4o", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(OpenAIAPIOverridesEnv, "")
+			if got := OpenAIUsesResponsesAPI(tt.model); got != tt.expected {
+				t.Errorf("OpenAIUsesResponsesAPI(%q) = %v, want %v", tt.model, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOpenAIUsesResponsesAPI_EnvOverride(t *testing.T) {
+	t.Setenv(OpenAIAPIOverridesEnv, "o3-pro=chat, my-proxy = responses,broken,other=unknown")
+
+	if OpenAIUsesResponsesAPI("o3-pro") {
+		t.Error("expected override to force Chat Completions for o3-pro")
+	}
+	if !OpenAIUsesResponsesAPI("my-proxy-model") {
+		t.Error("expected override to force Responses for my-proxy-model")
+	}
+	if OpenAIUsesResponsesAPI("gpt-4o-search-preview") {
+		t.Error("expected built-in table to apply when no override matches")
+	}
+	if !OpenAIUsesResponsesAPI("other-model") {
+		t.Error("expected unknown API names in overrides to be ignored")
+	}
+}
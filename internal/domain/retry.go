@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// retriableStatusPattern matches the HTTP statuses vendors return for rate
+// limiting and transient server failures.
+var retriableStatusPattern = regexp.MustCompile(`\b(?:429|500|502|503|504|529)\b`)
+
+// retriableErrorMarkers are lowercase fragments that vendors use in errors
+// for rate limiting, overload and timeouts.
+var retriableErrorMarkers = []string{
+	"rate limit",
+	"rate_limit",
+	"too many requests",
+	"overloaded",
+	"service unavailable",
+	"temporarily unavailable",
+	"timeout",
+	"timed out",
+}
+
+// IsRetriableError reports whether err looks like a transient vendor failure
+// (rate limiting, a 5xx status or a timeout) that another model may not hit.
+// A canceled context is never retriable.
+func IsRetriableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	if retriableStatusPattern.MatchString(message) {
+		return true
+	}
+	for _, marker := range retriableErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
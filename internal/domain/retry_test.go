@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRetriableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "rate limited", err: errors.New("POST /v1/chat/completions: 429 Too Many Requests"), want: true},
+		{name: "server error", err: errors.New("503 Service Unavailable"), want: true},
+		{name: "anthropic overloaded", err: errors.New(`{"type":"overloaded_error","message":"Overloaded"}`), want: true},
+		{name: "deadline", err: fmt.Errorf("request failed: %w", context.DeadlineExceeded), want: true},
+		{name: "canceled", err: fmt.Errorf("request failed: %w", context.Canceled), want: false},
+		{name: "unauthorized", err: errors.New("401 unauthorized"), want: false},
+		{name: "bad request", err: errors.New("400 Bad Request: invalid model"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetriableError(tt.err); got != tt.want {
+				t.Errorf("IsRetriableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
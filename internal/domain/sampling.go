@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SamplingParams describes which sampling parameters a model accepts.
+type SamplingParams struct {
+	// Temperature reports whether the model accepts a temperature.
+	Temperature bool
+	// TopP reports whether the model accepts top_p.
+	TopP bool
+	// ExclusiveTemperatureTopP reports that the model rejects requests that set
+	// both temperature and top_p.
+	ExclusiveTemperatureTopP bool
+}
+
+var (
+	// DefaultSamplingParams applies to models without a registered entry.
+	DefaultSamplingParams = SamplingParams{Temperature: true, TopP: true}
+	// AnthropicSamplingParams describes Claude models, which accept either
+	// temperature or top_p but not both.
+	AnthropicSamplingParams = SamplingParams{Temperature: true, TopP: true, ExclusiveTemperatureTopP: true}
+	// ReasoningSamplingParams describes reasoning models that reject both
+	// temperature and top_p.
+	ReasoningSamplingParams = SamplingParams{}
+)
+
+var (
+	modelSamplingParamsMu sync.RWMutex
+	// modelSamplingParams maps model name prefixes to their sampling
+	// capabilities. The longest matching prefix wins.
+	modelSamplingParams = map[string]SamplingParams{
+		"o1":         ReasoningSamplingParams,
+		"o3":         ReasoningSamplingParams,
+		"o4":         ReasoningSamplingParams,
+		"gpt-5":      ReasoningSamplingParams,
+		"gpt-5-chat": DefaultSamplingParams,
+		"claude":     AnthropicSamplingParams,
+	}
+)
+
+// modelNamespacePattern matches dotted namespaces such as the Bedrock
+// inference profile and provider prefixes in "us.anthropic.claude-...".
+var modelNamespacePattern = regexp.MustCompile(`^(?:[a-z]+\.)+`)
+
+// RegisterModelSamplingParams declares the sampling parameters supported by
+// models whose name starts with prefix, replacing any existing entry.
+func RegisterModelSamplingParams(prefix string, params SamplingParams) {
+	modelSamplingParamsMu.Lock()
+	defer modelSamplingParamsMu.Unlock()
+	modelSamplingParams[strings.ToLower(strings.TrimSpace(prefix))] = params
+}
+
+// ModelSamplingParams returns the sampling parameters registered for model.
+// Provider prefixes ("openai/o1") and dotted namespaces ("us.anthropic.claude")
+// are ignored when matching. ok is false when no entry matches, in which case
+// DefaultSamplingParams is returned.
+func ModelSamplingParams(model string) (params SamplingParams, ok bool) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = modelNamespacePattern.ReplaceAllString(name, "")
+
+	modelSamplingParamsMu.RLock()
+	defer modelSamplingParamsMu.RUnlock()
+
+	longest := -1
+	for prefix, value := range modelSamplingParams {
+		if strings.HasPrefix(name, prefix) && len(prefix) > longest {
+			longest = len(prefix)
+			params = value
+			ok = true
+		}
+	}
+	if !ok {
+		params = DefaultSamplingParams
+	}
+	return
+}
+
+// Select reports which of temperature and top_p to send. top_p is only sent
+// when hasTopP is true. When the two are mutually exclusive, top_p wins if it
+// differs from DefaultTopP; otherwise temperature is sent.
+func (p SamplingParams) Select(topP float64, hasTopP bool) (sendTemperature, sendTopP bool) {
+	sendTemperature = p.Temperature
+	sendTopP = p.TopP && hasTopP
+	if p.ExclusiveTemperatureTopP && sendTemperature && sendTopP {
+		if topP != DefaultTopP {
+			sendTemperature = false
+		} else {
+			sendTopP = false
+		}
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "testing"
+
+func TestModelSamplingParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		expected   SamplingParams
+		registered bool
+	}{
+		{name: "reasoning model omits sampling", model: "o1-mini", expected: ReasoningSamplingParams, registered: true},
+		{name: "provider prefix is ignored", model: "openai/o3-mini", expected: ReasoningSamplingParams, registered: true},
+		{name: "longest prefix wins", model: "gpt-5-chat-latest", expected: DefaultSamplingParams, registered: true},
+		{name: "bedrock namespace is ignored", model: "us.anthropic.claude-3-haiku-20240307-v1:0", expected: AnthropicSamplingParams, registered: true},
+		{name: "dotted version is not a namespace", model: "gpt-3.5-turbo", expected: DefaultSamplingParams},
+		{name: "unknown model uses defaults", model: "llama3", expected: DefaultSamplingParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ModelSamplingParams(tt.model)
+			if got != tt.expected || ok != tt.registered {
+				t.Errorf("ModelSamplingParams(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.expected, tt.registered)
+			}
+		})
+	}
+}
+
+func TestRegisterModelSamplingParams(t *testing.T) {
+	RegisterModelSamplingParams("Test-Reasoner", ReasoningSamplingParams)
+	t.Cleanup(func() {
+		modelSamplingParamsMu.Lock()
+		delete(modelSamplingParams, "test-reasoner")
+		modelSamplingParamsMu.Unlock()
+	})
+
+	if got, ok := ModelSamplingParams("test-reasoner-v2"); !ok || got.Temperature {
+		t.Errorf("expected registered model to reject temperature, got %+v, %v", got, ok)
+	}
+}
+
+func TestSamplingParamsSelect(t *testing.T) {
+	tests := []struct {
+		name            string
+		params          SamplingParams
+		topP            float64
+		hasTopP         bool
+		wantTemperature bool
+		wantTopP        bool
+	}{
+		{name: "defaults send both", params: DefaultSamplingParams, topP: 0.5, hasTopP: true, wantTemperature: true, wantTopP: true},
+		{name: "unset top_p is not sent", params: DefaultSamplingParams, hasTopP: false, wantTemperature: true},
+		{name: "reasoning sends neither", params: ReasoningSamplingParams, topP: 0.5, hasTopP: true},
+		{name: "exclusive prefers custom top_p", params: AnthropicSamplingParams, topP: 0.5, hasTopP: true, wantTopP: true},
+		{name: "exclusive falls back to temperature", params: AnthropicSamplingParams, topP: DefaultTopP, hasTopP: true, wantTemperature: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTemperature, gotTopP := tt.params.Select(tt.topP, tt.hasTopP)
+			if gotTemperature != tt.wantTemperature || gotTopP != tt.wantTopP {
+				t.Errorf("Select() = %v, %v; want %v, %v", gotTemperature, gotTopP, tt.wantTemperature, tt.wantTopP)
+			}
+		})
+	}
+}
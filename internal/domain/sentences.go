@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sentenceAbbreviations lists common abbreviations whose trailing period does
+// not end a sentence. Entries are lowercase and without the final period.
+var sentenceAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "vs": true, "etc": true, "e.g": true, "i.e": true, "cf": true, "al": true,
+	"inc": true, "ltd": true, "co": true, "corp": true, "no": true, "fig": true,
+	"approx": true, "u.s": true, "u.k": true,
+}
+
+// LimitSentences truncates text to at most n sentences. A sentence ends at '.',
+// '!' or '?' (optionally followed by closing quotes or brackets) when followed by
+// whitespace or the end of the text; periods after common abbreviations and
+// single-letter initials are ignored. n <= 0 means unlimited.
+func LimitSentences(text string, n int) string {
+	if n <= 0 {
+		return text
+	}
+
+	runes := []rune(text)
+	count := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune(`"')]”’`, runes[end]) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			continue
+		}
+		if r == '.' && isAbbreviation(runes[:i]) {
+			continue
+		}
+
+		count++
+		if count == n {
+			return strings.TrimSpace(string(runes[:end]))
+		}
+		i = end - 1
+	}
+	return text
+}
+
+// isAbbreviation reports whether the word ending just before a period is a
+// known abbreviation or a single-letter initial.
+func isAbbreviation(before []rune) bool {
+	start := len(before)
+	for start > 0 && !unicode.IsSpace(before[start-1]) && !strings.ContainsRune(`"'([“‘`, before[start-1]) {
+		start--
+	}
+	word := strings.ToLower(string(before[start:]))
+	if len([]rune(word)) == 1 && unicode.IsLetter([]rune(word)[0]) {
+		return true
+	}
+	return sentenceAbbreviations[word]
+}
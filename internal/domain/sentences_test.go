@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestLimitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		n        int
+		expected string
+	}{
+		{
+			name:     "truncates to n sentences",
+			text:     "First point. Second point! Third point? Fourth point.",
+			n:        2,
+			expected: "First point. Second point!",
+		},
+		{
+			name:     "shorter text is left intact",
+			text:     "Only one sentence here.",
+			n:        3,
+			expected: "Only one sentence here.",
+		},
+		{
+			name:     "zero means unlimited",
+			text:     "One. Two. Three.",
+			n:        0,
+			expected: "One. Two. Three.",
+		},
+		{
+			name:     "abbreviations and initials do not end sentences",
+			text:     "Dr. Smith met J. R. Jones, e.g. at work. They talked. Then left.",
+			n:        1,
+			expected: "Dr. Smith met J. R. Jones, e.g. at work.",
+		},
+		{
+			name:     "decimals and closing quotes",
+			text:     "The value rose 3.5 percent. He said \"stop.\" Nobody did.",
+			n:        2,
+			expected: "The value rose 3.5 percent. He said \"stop.\"",
+		},
+		{
+			name:     "handles newlines between sentences",
+			text:     "Line one.\nLine two.\nLine three.",
+			n:        2,
+			expected: "Line one.\nLine two.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LimitSentences(tt.text, tt.n); got != tt.expected {
+				t.Errorf("LimitSentences() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -4,16 +4,18 @@ package domain
 type StreamType string
 
 const (
-	StreamTypeContent StreamType = "content"
-	StreamTypeUsage   StreamType = "usage"
-	StreamTypeError   StreamType = "error"
+	StreamTypeContent    StreamType = "content"
+	StreamTypeUsage      StreamType = "usage"
+	StreamTypeError      StreamType = "error"
+	StreamTypeFileChange StreamType = "file_change"
 )
 
 // StreamUpdate is the unified payload sent through the internal channels.
 type StreamUpdate struct {
-	Type    StreamType     `json:"type"`
-	Content string         `json:"content,omitempty"` // For text deltas
-	Usage   *UsageMetadata `json:"usage,omitempty"`   // For token counts
+	Type       StreamType          `json:"type"`
+	Content    string              `json:"content,omitempty"`     // For text deltas
+	Usage      *UsageMetadata      `json:"usage,omitempty"`       // For token counts
+	FileChange *FileChangeProgress `json:"file_change,omitempty"` // For applied file changes
 }
 
 // UsageMetadata normalizes token counts across different providers.
@@ -22,3 +24,12 @@ type UsageMetadata struct {
 	OutputTokens int `json:"output_tokens"`
 	TotalTokens  int `json:"total_tokens"`
 }
+
+// FileChangeProgress describes one file change applied by create_coding_feature.
+// Index is 1-based so it can be shown directly as "Index/Total".
+type FileChangeProgress struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Index     int    `json:"index"`
+	Total     int    `json:"total"`
+}
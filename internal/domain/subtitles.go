@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// SubtitleCue is one timed cue of an SRT or WebVTT file. Lines holds the cue
+// text with markup removed, one entry per non-empty line.
+type SubtitleCue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// subtitleTagPattern matches WebVTT markup such as <c>, </c>, <v Speaker> and
+// the inline <00:00:01.234> word timings of auto-generated captions.
+var subtitleTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ParseSubtitles reads the cues of SRT or WebVTT content. Headers, NOTE, STYLE
+// and REGION blocks, and SRT sequence numbers are skipped.
+func ParseSubtitles(content string) (ret []SubtitleCue, err error) {
+	content = strings.TrimPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "\ufeff")
+	for block := range strings.SplitSeq(content, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		timing := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue
+		}
+
+		cue := SubtitleCue{}
+		if cue.Start, cue.End, err = parseCueTiming(lines[timing]); err != nil {
+			return nil, err
+		}
+		for _, line := range lines[timing+1:] {
+			if line = strings.TrimSpace(subtitleTagPattern.ReplaceAllString(line, "")); line != "" {
+				cue.Lines = append(cue.Lines, line)
+			}
+		}
+		if len(cue.Lines) > 0 {
+			ret = append(ret, cue)
+		}
+	}
+	if len(ret) == 0 {
+		err = errors.New(i18n.T("subtitles_no_cues"))
+	}
+	return
+}
+
+// MergeSubtitleCues joins cue text into plain text. Rolling captions repeat the
+// previous cue's last lines, and overlapping windows repeat its last words, so
+// text already present in the previous cue's tail is dropped. Word overlaps
+// shorter than minWords are kept; minWords <= 0 uses
+// DefaultTranscriptOverlapWords.
+func MergeSubtitleCues(cues []SubtitleCue, minWords int) string {
+	var parts []string
+	var previous []string
+	for _, cue := range cues {
+		lines := cue.Lines[sharedLineCount(previous, cue.Lines):]
+		text := TrimCaptionOverlap(strings.Join(previous, " "), strings.Join(lines, " "), minWords)
+		if text != "" {
+			parts = append(parts, text)
+		}
+		previous = cue.Lines
+	}
+	return strings.Join(parts, " ")
+}
+
+// SubtitlesToText parses SRT or WebVTT content and returns its deduplicated
+// plain text.
+func SubtitlesToText(content string) (ret string, err error) {
+	var cues []SubtitleCue
+	if cues, err = ParseSubtitles(content); err != nil {
+		return
+	}
+	ret = MergeSubtitleCues(cues, DefaultTranscriptOverlapWords)
+	return
+}
+
+// sharedLineCount returns how many leading lines of current repeat the last
+// lines of previous.
+func sharedLineCount(previous, current []string) int {
+	for n := min(len(previous), len(current)); n > 0; n-- {
+		if equalFragments(previous[len(previous)-n:], current[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// parseCueTiming parses a "start --> end" line, ignoring WebVTT cue settings.
+func parseCueTiming(line string) (start, end time.Duration, err error) {
+	before, after, _ := strings.Cut(line, "-->")
+	endFields := strings.Fields(after)
+	if len(endFields) == 0 {
+		err = fmt.Errorf(i18n.T("subtitles_invalid_timestamp"), strings.TrimSpace(line))
+		return
+	}
+	if start, err = parseSubtitleTimestamp(before); err != nil {
+		return
+	}
+	end, err = parseSubtitleTimestamp(endFields[0])
+	return
+}
+
+// parseSubtitleTimestamp parses "HH:MM:SS.mmm", "MM:SS.mmm" or the SRT form
+// "HH:MM:SS,mmm".
+func parseSubtitleTimestamp(value string) (ret time.Duration, err error) {
+	value = strings.TrimSpace(value)
+	invalid := func() (time.Duration, error) {
+		return 0, fmt.Errorf(i18n.T("subtitles_invalid_timestamp"), value)
+	}
+
+	clock, millis, _ := strings.Cut(strings.Replace(value, ",", ".", 1), ".")
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return invalid()
+	}
+	for _, part := range parts {
+		n, convErr := strconv.Atoi(part)
+		if convErr != nil || n < 0 {
+			return invalid()
+		}
+		ret = ret*60 + time.Duration(n)*time.Second
+	}
+	if millis != "" {
+		n, convErr := strconv.Atoi(millis)
+		if convErr != nil || n < 0 || len(millis) > 3 {
+			return invalid()
+		}
+		for range 3 - len(millis) {
+			n *= 10
+		}
+		ret += time.Duration(n) * time.Millisecond
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rollingCaptionsVTT follows the layout of YouTube auto-generated captions: each
+// cue shows the previous line above the one being spoken, with short cues in
+// between that only repeat what is already on screen.
+const rollingCaptionsVTT = `WEBVTT
+Kind: captions
+Language: en
+
+00:00:00.000 --> 00:00:02.510 align:start position:0%
+
+Every<00:00:00.280><c> day</c><00:00:00.560><c> you</c><00:00:01.120><c> should</c><00:00:01.600><c> be</c><00:00:02.000><c> in</c>
+
+00:00:02.510 --> 00:00:02.520 align:start position:0%
+Every day you should be in
+
+
+00:00:02.520 --> 00:00:05.000 align:start position:0%
+Every day you should be in
+the<00:00:03.000><c> gym</c><00:00:03.400><c> working</c><00:00:04.000><c> on</c><00:00:04.400><c> your</c><00:00:04.700><c> form</c>
+
+00:00:05.000 --> 00:00:05.010 align:start position:0%
+the gym working on your form
+
+
+00:00:05.010 --> 00:00:07.000 align:start position:0%
+the gym working on your form
+no,<00:00:05.500><c> no,</c><00:00:06.000><c> no,</c><00:00:06.500><c> not</c><00:00:06.800><c> like</c><00:00:06.900><c> that</c>
+`
+
+func TestSubtitlesToTextRollingVTT(t *testing.T) {
+	got, err := SubtitlesToText(rollingCaptionsVTT)
+	if err != nil {
+		t.Fatalf("SubtitlesToText() error = %v", err)
+	}
+	want := "Every day you should be in the gym working on your form no, no, no, not like that"
+	if got != want {
+		t.Errorf("SubtitlesToText() = %q, want %q", got, want)
+	}
+}
+
+func TestSubtitlesToTextOverlappingWindowsSRT(t *testing.T) {
+	srt := "1\r\n00:00:01,000 --> 00:00:03,000\r\nso what I want to talk about today\r\n\r\n" +
+		"2\r\n00:00:03,000 --> 00:00:05,000\r\nwant to talk about today is how we build habits\r\n\r\n" +
+		"3\r\n00:00:05,000 --> 00:00:07,000\r\n<i>and why they stick.</i>\r\n"
+
+	got, err := SubtitlesToText(srt)
+	if err != nil {
+		t.Fatalf("SubtitlesToText() error = %v", err)
+	}
+	want := "so what I want to talk about today is how we build habits and why they stick."
+	if got != want {
+		t.Errorf("SubtitlesToText() = %q, want %q", got, want)
+	}
+}
+
+func TestMergeSubtitleCuesKeepsRepeatsAfterOtherText(t *testing.T) {
+	cues := []SubtitleCue{
+		{Lines: []string{"We will fight"}},
+		{Lines: []string{"We will fight"}},
+		{Lines: []string{"on the beaches"}},
+		{Lines: []string{"We will fight"}},
+	}
+	want := "We will fight on the beaches We will fight"
+	if got := MergeSubtitleCues(cues, 0); got != want {
+		t.Errorf("MergeSubtitleCues() = %q, want %q", got, want)
+	}
+}
+
+func TestParseSubtitles(t *testing.T) {
+	vtt := "WEBVTT\n\nNOTE a comment\n\nintro\n01:02.500 --> 01:04.000 line:0\n<v Speaker>Hello</v>\nthere\n\n" +
+		"01:00:00.000 --> 01:00:01.000\n"
+
+	got, err := ParseSubtitles(vtt)
+	if err != nil {
+		t.Fatalf("ParseSubtitles() error = %v", err)
+	}
+	want := []SubtitleCue{{
+		Start: time.Minute + 2500*time.Millisecond,
+		End:   time.Minute + 4*time.Second,
+		Lines: []string{"Hello", "there"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSubtitles() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSubtitlesErrors(t *testing.T) {
+	if _, err := ParseSubtitles("just some text\n\nwith no cues"); err == nil {
+		t.Error("expected an error for input without cues")
+	}
+	if _, err := ParseSubtitles("1\n00:00:aa,000 --> 00:00:02,000\nhello\n"); err == nil {
+		t.Error("expected an error for a malformed timestamp")
+	}
+}
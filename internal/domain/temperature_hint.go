@@ -0,0 +1,46 @@
+package domain
+
+import "strings"
+
+const (
+	// PreciseTemperatureHint is suggested for patterns that ask for precise output.
+	PreciseTemperatureHint = 0.2
+	// CreativeTemperatureHint is suggested for patterns that ask for creative output.
+	CreativeTemperatureHint = 1.0
+)
+
+var (
+	precisePhrases = []string{
+		"be precise", "be accurate", "be exact", "be factual", "be deterministic",
+	}
+	creativePhrases = []string{
+		"be creative", "be imaginative", "be inventive", "be playful",
+	}
+)
+
+// SuggestTemperature looks for phrases in pattern text that imply a sampling
+// temperature, such as "be precise" or "be creative". The suggestion is
+// advisory only; ok is false when no phrase matches or when the text asks for
+// both precise and creative output.
+func SuggestTemperature(text string) (temperature float64, ok bool) {
+	text = strings.ToLower(text)
+	precise := containsAny(text, precisePhrases)
+	creative := containsAny(text, creativePhrases)
+
+	switch {
+	case precise && !creative:
+		return PreciseTemperatureHint, true
+	case creative && !precise:
+		return CreativeTemperatureHint, true
+	}
+	return 0, false
+}
+
+func containsAny(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
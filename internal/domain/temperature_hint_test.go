@@ -0,0 +1,47 @@
+package domain
+
+import "testing"
+
+func TestSuggestTemperature(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantTemp float64
+		wantOK   bool
+	}{
+		{
+			name:     "precise maps to low temperature",
+			text:     "# IDENTITY\nYou extract facts. Be precise and cite sources.",
+			wantTemp: PreciseTemperatureHint,
+			wantOK:   true,
+		},
+		{
+			name:     "creative maps to higher temperature",
+			text:     "You write short stories. BE CREATIVE with the plot.",
+			wantTemp: CreativeTemperatureHint,
+			wantOK:   true,
+		},
+		{
+			name:   "no hint",
+			text:   "Summarize the input in five bullets.",
+			wantOK: false,
+		},
+		{
+			name:   "conflicting hints",
+			text:   "Be creative with titles but be precise with numbers.",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SuggestTemperature(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("SuggestTemperature() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.wantTemp {
+				t.Errorf("SuggestTemperature() = %v, want %v", got, tt.wantTemp)
+			}
+		})
+	}
+}
@@ -1,32 +1,201 @@
 package domain
 
-import (
-	"regexp"
-	"sync"
-)
+import "strings"
+
+// ThinkTagPair is a start and end tag that enclose a thinking section.
+type ThinkTagPair struct {
+	Start string
+	End   string
+}
+
+// DefaultThinkTagPairs lists the thinking tag styles models commonly emit.
+var DefaultThinkTagPairs = []ThinkTagPair{
+	{Start: "<think>", End: "</think>"},
+	{Start: "<thinking>", End: "</thinking>"},
+	{Start: "<reasoning>", End: "</reasoning>"},
+}
+
+// ThinkTags returns the tag pairs used to detect thinking sections. When
+// ThinkTagPairs is empty the ThinkStartTag/ThinkEndTag pair is used, followed
+// by DefaultThinkTagPairs.
+func (o *ChatOptions) ThinkTags() []ThinkTagPair {
+	if len(o.ThinkTagPairs) > 0 {
+		return o.ThinkTagPairs
+	}
+	return ThinkTagPairsWithDefaults(o.ThinkStartTag, o.ThinkEndTag)
+}
+
+// ThinkTagPairsWithDefaults returns the startTag/endTag pair followed by the
+// DefaultThinkTagPairs it does not duplicate. The pair is left out when either
+// tag is empty.
+func ThinkTagPairsWithDefaults(startTag, endTag string) (ret []ThinkTagPair) {
+	if startTag != "" && endTag != "" {
+		ret = append(ret, ThinkTagPair{Start: startTag, End: endTag})
+	}
+	for _, pair := range DefaultThinkTagPairs {
+		if len(ret) == 0 || ret[0] != pair {
+			ret = append(ret, pair)
+		}
+	}
+	return
+}
 
 // StripThinkBlocks removes any content between the provided start and end tags
 // from the input string. Whitespace following the end tag is also removed so
 // output resumes at the next non-empty line.
-var (
-	regexCache = make(map[string]*regexp.Regexp)
-	cacheMutex sync.Mutex
-)
-
 func StripThinkBlocks(input, startTag, endTag string) string {
-	if startTag == "" || endTag == "" {
-		return input
+	return StripThinkTags(input, []ThinkTagPair{{Start: startTag, End: endTag}})
+}
+
+// StripThinkTags removes every section enclosed by any of the tag pairs,
+// along with the whitespace that follows it. A section ends at the end tag
+// that balances its own start tag, so nested sections of the same style are
+// removed whole and tags of other styles inside a section are ignored. An
+// unclosed start tag is kept as text and scanning resumes after it, so later
+// closed sections are still removed. Pairs with an empty tag are skipped.
+func StripThinkTags(input string, tags []ThinkTagPair) string {
+	var builder strings.Builder
+	rest := input
+	for {
+		start, pair := nextThinkStart(rest, tags)
+		if start < 0 {
+			break
+		}
+		end := thinkSectionEnd(rest[start:], pair)
+		if end < 0 {
+			skip := start + len(pair.Start)
+			builder.WriteString(rest[:skip])
+			rest = rest[skip:]
+			continue
+		}
+		builder.WriteString(rest[:start])
+		rest = strings.TrimLeft(rest[start+end:], " \t\n\f\r")
+	}
+	builder.WriteString(rest)
+	return builder.String()
+}
+
+// nextThinkStart returns the index of the earliest start tag in s and its
+// pair. When several start tags begin at the same index the longest wins.
+func nextThinkStart(s string, tags []ThinkTagPair) (index int, pair ThinkTagPair) {
+	index = -1
+	for _, candidate := range tags {
+		if candidate.Start == "" || candidate.End == "" {
+			continue
+		}
+		i := strings.Index(s, candidate.Start)
+		if i < 0 {
+			continue
+		}
+		if index < 0 || i < index || (i == index && len(candidate.Start) > len(pair.Start)) {
+			index, pair = i, candidate
+		}
 	}
+	return
+}
 
-	cacheKey := startTag + "|" + endTag
-	cacheMutex.Lock()
-	re, exists := regexCache[cacheKey]
-	if !exists {
-		pattern := "(?s)" + regexp.QuoteMeta(startTag) + ".*?" + regexp.QuoteMeta(endTag) + "\\s*"
-		re = regexp.MustCompile(pattern)
-		regexCache[cacheKey] = re
+// thinkSectionEnd returns the length of the section at the start of s, which
+// begins with pair.Start, or -1 when it is never closed.
+func thinkSectionEnd(s string, pair ThinkTagPair) int {
+	if pair.Start == pair.End {
+		// Identical tags cannot nest; the next occurrence closes the section.
+		i := strings.Index(s[len(pair.Start):], pair.End)
+		if i < 0 {
+			return -1
+		}
+		return len(pair.Start) + i + len(pair.End)
+	}
+	depth := 0
+	pos := 0
+	for {
+		nextStart := strings.Index(s[pos:], pair.Start)
+		nextEnd := strings.Index(s[pos:], pair.End)
+		switch {
+		case nextEnd < 0:
+			return -1
+		case nextStart >= 0 && nextStart < nextEnd:
+			depth++
+			pos += nextStart + len(pair.Start)
+		default:
+			depth--
+			pos += nextEnd + len(pair.End)
+			if depth == 0 {
+				return pos
+			}
+		}
 	}
-	cacheMutex.Unlock()
+}
+
+// ThinkStreamFilter removes thinking sections from text that arrives in
+// chunks. Text that may still turn out to belong to a section, such as an
+// unclosed section or a partial start tag, is held back until later chunks
+// resolve it or Flush is called. The concatenated output always equals
+// StripThinkTags applied to the concatenated input.
+type ThinkStreamFilter struct {
+	tags    []ThinkTagPair
+	raw     strings.Builder
+	emitted int
+}
+
+// NewThinkStreamFilter creates a filter that removes sections enclosed by any
+// of the tag pairs.
+func NewThinkStreamFilter(tags []ThinkTagPair) *ThinkStreamFilter {
+	return &ThinkStreamFilter{tags: tags}
+}
+
+// Write adds chunk to the stream and returns the text that became visible.
+func (f *ThinkStreamFilter) Write(chunk string) string {
+	f.raw.WriteString(chunk)
+	return f.emit(stripResolvedThinkTags(f.raw.String(), f.tags))
+}
 
-	return re.ReplaceAllString(input, "")
+// Flush returns the text still held back. Like StripThinkTags, it keeps an
+// unclosed section.
+func (f *ThinkStreamFilter) Flush() string {
+	return f.emit(StripThinkTags(f.raw.String(), f.tags))
+}
+
+func (f *ThinkStreamFilter) emit(visible string) string {
+	out := visible[f.emitted:]
+	f.emitted = len(visible)
+	return out
+}
+
+// stripResolvedThinkTags is StripThinkTags limited to the part of input that
+// later text cannot change: it stops before an unclosed section and before a
+// trailing partial start tag.
+func stripResolvedThinkTags(input string, tags []ThinkTagPair) string {
+	var builder strings.Builder
+	rest := input
+	for {
+		start, pair := nextThinkStart(rest, tags)
+		if start < 0 {
+			break
+		}
+		end := thinkSectionEnd(rest[start:], pair)
+		builder.WriteString(rest[:start])
+		if end < 0 {
+			return builder.String()
+		}
+		rest = strings.TrimLeft(rest[start+end:], " \t\n\f\r")
+	}
+	builder.WriteString(rest[:len(rest)-partialThinkStartLen(rest, tags)])
+	return builder.String()
+}
+
+// partialThinkStartLen returns the length of the longest suffix of s that is a
+// proper prefix of a start tag.
+func partialThinkStartLen(s string, tags []ThinkTagPair) (longest int) {
+	for _, pair := range tags {
+		if pair.Start == "" || pair.End == "" {
+			continue
+		}
+		for n := min(len(pair.Start)-1, len(s)); n > longest; n-- {
+			if strings.HasSuffix(s, pair.Start[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return
 }
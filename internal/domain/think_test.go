@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestStripThinkBlocks(t *testing.T) {
 	input := "<think>internal</think>\n\nresult"
@@ -17,3 +21,103 @@ func TestStripThinkBlocksCustomTags(t *testing.T) {
 		t.Errorf("expected %q, got %q", "visible", got)
 	}
 }
+
+func TestStripThinkTags(t *testing.T) {
+	tags := []ThinkTagPair{
+		{Start: "<think>", End: "</think>"},
+		{Start: "<thinking>", End: "</thinking>"},
+		{Start: "<reasoning>", End: "</reasoning>"},
+	}
+	tests := []struct {
+		name  string
+		input string
+		tags  []ThinkTagPair
+		want  string
+	}{
+		{name: "mixed styles", input: "<think>a</think>one <thinking>b</thinking>two <reasoning>c</reasoning>three", tags: tags, want: "one two three"},
+		{name: "nested same style", input: "<think>a<think>b</think>c</think> answer", tags: tags, want: "answer"},
+		{name: "nested other style", input: "<thinking>a<reasoning>b</reasoning>c</thinking>\nanswer", tags: tags, want: "answer"},
+		{name: "interleaved styles", input: "<think>a<reasoning>b</think>c</reasoning>d", tags: tags, want: "c</reasoning>d"},
+		{name: "unclosed section", input: "answer <think>still thinking", tags: tags, want: "answer <think>still thinking"},
+		{name: "stray start tag before closed sections", input: "a <think> b <thinking>x</thinking>c <think>y</think>d", tags: tags, want: "a <think> b c d"},
+		{name: "identical start and end", input: "---hidden--- shown", tags: []ThinkTagPair{{Start: "---", End: "---"}}, want: "shown"},
+		{name: "empty tags ignored", input: "<think>a</think>b", tags: []ThinkTagPair{{Start: "", End: "</think>"}}, want: "<think>a</think>b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripThinkTags(tt.input, tt.tags); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestChatOptionsThinkTags(t *testing.T) {
+	opts := &ChatOptions{ThinkStartTag: "<think>", ThinkEndTag: "</think>"}
+	if got := opts.ThinkTags(); !reflect.DeepEqual(got, DefaultThinkTagPairs) {
+		t.Errorf("expected the default pairs, got %+v", got)
+	}
+
+	opts = &ChatOptions{ThinkStartTag: "[[t]]", ThinkEndTag: "[[/t]]"}
+	want := append([]ThinkTagPair{{Start: "[[t]]", End: "[[/t]]"}}, DefaultThinkTagPairs...)
+	if got := opts.ThinkTags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the configured pair first, got %+v", got)
+	}
+
+	opts.ThinkTagPairs = []ThinkTagPair{{Start: "<reasoning>", End: "</reasoning>"}}
+	if got := opts.ThinkTags(); len(got) != 1 || got[0].Start != "<reasoning>" {
+		t.Errorf("expected configured pairs, got %+v", got)
+	}
+}
+
+func TestThinkStreamFilter(t *testing.T) {
+	tags := []ThinkTagPair{
+		{Start: "<think>", End: "</think>"},
+		{Start: "<thinking>", End: "</thinking>"},
+	}
+	inputs := []string{
+		"<think>hidden</think>\n\nanswer",
+		"before <thinking>a<think>b</think>c</thinking>  after <think>x</think>end",
+		"answer <think>still thinking",
+		"stray <think> then <thinking>hidden</thinking> answer",
+		"no sections at all",
+		"a < b but <thin is not a tag",
+	}
+
+	for _, input := range inputs {
+		want := StripThinkTags(input, tags)
+		// Every chunk size must produce the same output as stripping the whole text
+		for size := 1; size <= len(input); size++ {
+			filter := NewThinkStreamFilter(tags)
+			var got strings.Builder
+			for i := 0; i < len(input); i += size {
+				chunk := filter.Write(input[i:min(i+size, len(input))])
+				if strings.Contains(chunk, "hidden") {
+					t.Errorf("input %q, chunk size %d: emitted suppressed text %q", input, size, chunk)
+				}
+				got.WriteString(chunk)
+			}
+			got.WriteString(filter.Flush())
+			if got.String() != want {
+				t.Errorf("input %q, chunk size %d: expected %q, got %q", input, size, want, got.String())
+			}
+		}
+	}
+}
+
+func TestThinkStreamFilterHoldsUnresolvedText(t *testing.T) {
+	filter := NewThinkStreamFilter([]ThinkTagPair{{Start: "<think>", End: "</think>"}})
+	if got := filter.Write("answer <thi"); got != "answer " {
+		t.Errorf("expected partial start tag to be held, got %q", got)
+	}
+	if got := filter.Write("nk>reasoning"); got != "" {
+		t.Errorf("expected unclosed section to be held, got %q", got)
+	}
+	if got := filter.Write("</think> done"); got != "done" {
+		t.Errorf("expected text after the section, got %q", got)
+	}
+	if got := filter.Flush(); got != "" {
+		t.Errorf("expected nothing left to flush, got %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultTranscriptOverlapWords is the shortest repeated run of words treated
+// as a caption overlap rather than intentional repetition.
+const DefaultTranscriptOverlapWords = 4
+
+// maxTranscriptOverlapWords bounds the run length that is checked, keeping the
+// scan linear; overlapping caption windows are much shorter than this.
+const maxTranscriptOverlapWords = 64
+
+// CollapseRepeatedFragments removes immediately repeated runs of at least
+// minWords words, such as "Every day you should be in Every day you should be
+// in", keeping the first occurrence. Words are compared case-insensitively and
+// without surrounding punctuation. Shorter repeats ("no, no, no") are left
+// alone; minWords <= 0 uses DefaultTranscriptOverlapWords.
+func CollapseRepeatedFragments(text string, minWords int) string {
+	if minWords <= 0 {
+		minWords = DefaultTranscriptOverlapWords
+	}
+
+	words := strings.Fields(text)
+	kept := make([]string, 0, len(words))
+	keys := make([]string, 0, len(words))
+	collapsed := false
+	for _, word := range words {
+		kept = append(kept, word)
+		keys = append(keys, fragmentKey(word))
+		for n := min(maxTranscriptOverlapWords, len(keys)/2); n >= minWords; n-- {
+			if equalFragments(keys[len(keys)-2*n:len(keys)-n], keys[len(keys)-n:]) {
+				kept = kept[:len(kept)-n]
+				keys = keys[:len(keys)-n]
+				collapsed = true
+				break
+			}
+		}
+	}
+	if !collapsed {
+		return text
+	}
+	return strings.Join(kept, " ")
+}
+
+// TrimCaptionOverlap drops the leading words of current that repeat the end of
+// previous, as happens when caption windows overlap. Overlaps shorter than
+// minWords words are kept; minWords <= 0 uses DefaultTranscriptOverlapWords.
+// The result is empty when current adds nothing to previous.
+func TrimCaptionOverlap(previous, current string, minWords int) string {
+	if minWords <= 0 {
+		minWords = DefaultTranscriptOverlapWords
+	}
+
+	prevKeys := fragmentKeys(strings.Fields(previous))
+	words := strings.Fields(current)
+	keys := fragmentKeys(words)
+	for n := min(maxTranscriptOverlapWords, len(prevKeys), len(keys)); n >= minWords; n-- {
+		if equalFragments(prevKeys[len(prevKeys)-n:], keys[:n]) {
+			return strings.Join(words[n:], " ")
+		}
+	}
+	return current
+}
+
+// fragmentKey normalizes a word for overlap comparison.
+func fragmentKey(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
+
+func fragmentKeys(words []string) []string {
+	ret := make([]string, len(words))
+	for i, word := range words {
+		ret[i] = fragmentKey(word)
+	}
+	return ret
+}
+
+func equalFragments(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
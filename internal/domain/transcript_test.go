@@ -0,0 +1,99 @@
+package domain
+
+import "testing"
+
+func TestCollapseRepeatedFragments(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		minWords int
+		expected string
+	}{
+		{
+			name:     "collapses a duplicated caption window",
+			text:     "Every day you should be in Every day you should be in the gym working on your form.",
+			expected: "Every day you should be in the gym working on your form.",
+		},
+		{
+			name:     "collapses overlapping windows",
+			text:     "so what I want to talk about today is what I want to talk about today is how we build habits",
+			expected: "so what I want to talk about today is how we build habits",
+		},
+		{
+			name:     "collapses runs repeated more than twice",
+			text:     "and then we went out and then we went out and then we went out to the lake",
+			expected: "and then we went out to the lake",
+		},
+		{
+			name:     "ignores case and punctuation",
+			text:     "Thanks for watching, see you. thanks for watching see you next time.",
+			expected: "Thanks for watching, see you. next time.",
+		},
+		{
+			name:     "keeps short intentional repetition",
+			text:     "Location, location, location. No, no, no. We will fight, we will fight.",
+			expected: "Location, location, location. No, no, no. We will fight, we will fight.",
+		},
+		{
+			name:     "threshold is tunable",
+			text:     "We will fight, we will fight on the beaches.",
+			minWords: 3,
+			expected: "We will fight, on the beaches.",
+		},
+		{
+			name:     "text without repeats is unchanged",
+			text:     "It was the best of times, it was the worst of times.",
+			expected: "It was the best of times, it was the worst of times.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CollapseRepeatedFragments(tt.text, tt.minWords); got != tt.expected {
+				t.Errorf("CollapseRepeatedFragments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrimCaptionOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		expected string
+	}{
+		{
+			name:     "drops words carried over from the previous window",
+			previous: "Every day you should be in",
+			current:  "you should be in the gym",
+			expected: "the gym",
+		},
+		{
+			name:     "returns empty when nothing new is added",
+			previous: "Every day you should be in",
+			current:  "day you should be in",
+			expected: "",
+		},
+		{
+			name:     "keeps short overlaps",
+			previous: "I said no",
+			current:  "no to that",
+			expected: "no to that",
+		},
+		{
+			name:     "first caption is unchanged",
+			previous: "",
+			current:  "Welcome back to the channel",
+			expected: "Welcome back to the channel",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrimCaptionOverlap(tt.previous, tt.current, 0); got != tt.expected {
+				t.Errorf("TrimCaptionOverlap() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package i18n
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	gi18n "github.com/nicksnyder/go-i18n/v2/i18n"
@@ -38,3 +40,26 @@ func TestTranslation(t *testing.T) {
 		})
 	}
 }
+
+// T renders messages as templates, so a literal "{{" makes it panic.
+func TestLocalesHaveNoTemplateDelimiters(t *testing.T) {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		t.Fatalf("failed to read locales: %v", err)
+	}
+	for _, file := range files {
+		data, err := localeFS.ReadFile("locales/" + file.Name())
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file.Name(), err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			t.Fatalf("failed to parse %s: %v", file.Name(), err)
+		}
+		for id, message := range messages {
+			if strings.Contains(message, "{{") {
+				t.Errorf("%s: message %q contains a template delimiter: %s", file.Name(), id, message)
+			}
+		}
+	}
+}
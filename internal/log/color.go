@@ -0,0 +1,69 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+)
+
+var (
+	colorDisabled bool
+	// isTerminal reports whether w is attached to a terminal. It is a variable
+	// so tests can simulate a TTY.
+	isTerminal = func(w io.Writer) bool {
+		f, ok := w.(*os.File)
+		if !ok {
+			return false
+		}
+		info, err := f.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+)
+
+// SetColor enables or disables colored output. Color is enabled by default
+// and still requires a terminal and an unset NO_COLOR.
+func SetColor(enabled bool) {
+	mu.Lock()
+	colorDisabled = !enabled
+	mu.Unlock()
+}
+
+// ColorEnabled reports whether messages written to w are colorized: color has
+// not been disabled, NO_COLOR (https://no-color.org) is unset and w is a
+// terminal.
+func ColorEnabled(w io.Writer) bool {
+	mu.RLock()
+	disabled := colorDisabled
+	mu.RUnlock()
+	if disabled || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// Warn writes a warning to stderr, in yellow when color is enabled.
+func Warn(format string, a ...any) {
+	writeColored(colorYellow, format, a...)
+}
+
+// Error writes an error to stderr, in red when color is enabled.
+func Error(format string, a ...any) {
+	writeColored(colorRed, format, a...)
+}
+
+func writeColored(color, format string, a ...any) {
+	mu.RLock()
+	w := output
+	mu.RUnlock()
+	msg := fmt.Sprintf(format, a...)
+	if ColorEnabled(w) {
+		msg = color + msg + colorReset
+	}
+	fmt.Fprint(w, msg)
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func withTerminal(t *testing.T, terminal bool) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prevTerminal := isTerminal
+	isTerminal = func(io.Writer) bool { return terminal }
+	SetOutput(&buf)
+	t.Cleanup(func() {
+		isTerminal = prevTerminal
+		SetOutput(os.Stderr)
+		SetColor(true)
+	})
+	return &buf
+}
+
+func TestWarnAndErrorColorOnTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	buf := withTerminal(t, true)
+
+	Warn("careful %d\n", 1)
+	Error("broken\n")
+
+	if got, want := buf.String(), colorYellow+"careful 1\n"+colorReset+colorRed+"broken\n"+colorReset; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWarnAndErrorPlain(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+		noColor  string
+		disabled bool
+	}{
+		{name: "not a terminal", terminal: false},
+		{name: "NO_COLOR set", terminal: true, noColor: "1"},
+		{name: "color disabled", terminal: true, disabled: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tc.noColor)
+			buf := withTerminal(t, tc.terminal)
+			SetColor(!tc.disabled)
+
+			Warn("careful\n")
+			Error("broken\n")
+
+			if strings.Contains(buf.String(), "\033[") {
+				t.Fatalf("expected no color codes, got %q", buf.String())
+			}
+			if got, want := buf.String(), "careful\nbroken\n"; got != want {
+				t.Fatalf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
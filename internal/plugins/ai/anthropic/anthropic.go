@@ -17,6 +17,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 )
 
 const defaultBaseUrl = "https://api.anthropic.com/"
@@ -283,6 +284,12 @@ func (an *Client) buildMessageParams(msgs []anthropic.MessageParam, opts *domain
 	return
 }
 
+// ModelCapabilities reports that every Claude model streams and supports the
+// web search tool.
+func (an *Client) ModelCapabilities(string) ai.ModelCapabilities {
+	return ai.ModelCapabilities{Streaming: true, Search: true}
+}
+
 func (an *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (
 	ret string, err error) {
 
@@ -310,11 +317,26 @@ func (an *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage,
 		}
 	}
 
+	ai.DumpRawResponseObject(opts, message)
+	ret = parseAnthropicMessage(message, opts)
+
+	return
+}
+
+// parseAnthropicMessage joins the text blocks of message and appends any web
+// search citations. Native thinking blocks are dropped when opts.SuppressThink
+// is set and otherwise wrapped in the configured think tags, so they are
+// handled like tagged reasoning from other vendors.
+func parseAnthropicMessage(message *anthropic.Message, opts *domain.ChatOptions) string {
 	var textParts []string
 	var citations []string
 	citationMap := make(map[string]bool) // To avoid duplicate citations
 
 	for _, block := range message.Content {
+		if block.Type == "thinking" && block.Thinking != "" && !opts.SuppressThink {
+			textParts = append(textParts, wrapThinking(block.Thinking, opts))
+			continue
+		}
 		if block.Type == "text" && block.Text != "" {
 			textParts = append(textParts, block.Text)
 
@@ -345,9 +367,20 @@ func (an *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage,
 		resultBuilder.WriteString("\n\n")
 		resultBuilder.WriteString(strings.Join(citations, "\n"))
 	}
-	ret = resultBuilder.String()
+	return resultBuilder.String()
+}
 
-	return
+// wrapThinking encloses thinking in the first complete think tag pair from
+// opts, so the section is found again when thinking is stripped.
+func wrapThinking(thinking string, opts *domain.ChatOptions) string {
+	tags := domain.DefaultThinkTagPairs[0]
+	for _, pair := range opts.ThinkTags() {
+		if pair.Start != "" && pair.End != "" {
+			tags = pair
+			break
+		}
+	}
+	return tags.Start + "\n" + strings.TrimSpace(thinking) + "\n" + tags.End + "\n\n"
 }
 
 func (an *Client) toMessages(msgs []*chat.ChatCompletionMessage) (ret []anthropic.MessageParam) {
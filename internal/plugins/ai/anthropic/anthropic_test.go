@@ -424,3 +424,45 @@ func TestToMessages_MultiContentPDFAttachment(t *testing.T) {
 		t.Fatalf("Expected document data to match base64 payload, got %s", document.Source.OfBase64.Data)
 	}
 }
+
+func TestParseAnthropicMessage_ThinkingBlocks(t *testing.T) {
+	message := &anthropic.Message{
+		Content: []anthropic.ContentBlockUnion{
+			{Type: "thinking", Thinking: "Let me work this out."},
+			{Type: "text", Text: "The answer is 42."},
+		},
+	}
+
+	tests := []struct {
+		name string
+		opts *domain.ChatOptions
+		want string
+	}{
+		{
+			name: "wrapped in configured tags",
+			opts: &domain.ChatOptions{ThinkStartTag: "<reasoning>", ThinkEndTag: "</reasoning>"},
+			want: "<reasoning>\nLet me work this out.\n</reasoning>\n\nThe answer is 42.",
+		},
+		{
+			name: "default tags",
+			opts: &domain.ChatOptions{},
+			want: "<think>\nLet me work this out.\n</think>\n\nThe answer is 42.",
+		},
+		{
+			name: "suppressed",
+			opts: &domain.ChatOptions{SuppressThink: true, ThinkStartTag: "<think>", ThinkEndTag: "</think>"},
+			want: "The answer is 42.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAnthropicMessage(message, tt.opts)
+			if got != tt.want {
+				t.Errorf("parseAnthropicMessage() = %q, want %q", got, tt.want)
+			}
+			if stripped := domain.StripThinkTags(got, tt.opts.ThinkTags()); tt.opts.ThinkStartTag != "" && stripped != "The answer is 42." {
+				t.Errorf("StripThinkTags() = %q, want the text block only", stripped)
+			}
+		})
+	}
+}
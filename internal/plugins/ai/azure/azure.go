@@ -17,6 +17,7 @@ import (
 func NewClient() (ret *Client) {
 	ret = &Client{}
 	ret.Client = openai.NewClientCompatible("Azure", "", ret.configure)
+	ret.SetDeveloperRoleSupported(true)
 	ret.ApiDeployments = ret.AddSetupQuestionCustom("deployments", true,
 		i18n.T("azure_deployments_question"))
 	ret.ApiVersion = ret.AddSetupQuestionCustom("API Version", false,
@@ -19,6 +19,7 @@ import (
 func NewClient() (ret *Client) {
 	ret = &Client{}
 	ret.Client = openai.NewClientCompatibleNoSetupQuestions("AzureEntra", ret.configure)
+	ret.SetDeveloperRoleSupported(true)
 
 	ret.ApiBaseURL = ret.AddSetupQuestionCustom("API Base URL", true,
 		i18n.T("azure_base_url_question"))
@@ -6,13 +6,16 @@
 package azureaigateway
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,7 +27,16 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/ai"
 )
 
-const gatewayTimeout = 300 * time.Second
+// defaultMaxRetries is the number of retries for throttled or transiently
+// failing requests when the max_retries setup question is left empty.
+const defaultMaxRetries = 3
+
+// maxRetryDelay caps the wait between retries, including Retry-After values.
+const maxRetryDelay = 60 * time.Second
+
+// retryBaseDelay is the initial backoff delay; it doubles with each attempt.
+// It is a variable so tests can shorten it.
+var retryBaseDelay = time.Second
 
 // Ensure Client implements the ai.Vendor interface
 var _ ai.Vendor = (*Client)(nil)
@@ -54,6 +66,23 @@ type Backend interface {
 	ParseResponse(body []byte) (string, error)
 }
 
+// StreamingBackend is implemented by backends whose API streams Server-Sent
+// Events through the gateway. Backends that don't implement it use the
+// non-streaming fallback in SendStream.
+type StreamingBackend interface {
+	Backend
+
+	// BuildStreamEndpoint constructs the streaming API endpoint URL for the given model
+	BuildStreamEndpoint(baseURL, model string) string
+
+	// PrepareStreamRequest prepares the HTTP request body for a streaming call
+	PrepareStreamRequest(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) ([]byte, error)
+
+	// ParseStreamChunk parses the payload of one SSE "data:" event.
+	// done reports that the stream has ended.
+	ParseStreamChunk(data []byte) (updates []domain.StreamUpdate, done bool, err error)
+}
+
 // Client implements the Azure AI Gateway vendor for Fabric.
 // It supports multiple backends (Bedrock, Azure OpenAI, Vertex AI) through
 // a unified Azure APIM Gateway with shared subscription key authentication.
@@ -63,15 +92,20 @@ type Client struct {
 	GatewayURL      *plugins.SetupQuestion
 	SubscriptionKey *plugins.SetupQuestion
 	APIVersion      *plugins.SetupQuestion
+	MaxRetries      *plugins.SetupQuestion
+	ModelDiscovery  *plugins.SetupQuestion
+	SafetyThreshold *plugins.SetupQuestion
 
 	backend    Backend
 	httpClient *http.Client
+	maxRetries int
+	timeout    time.Duration
 }
 
 // NewClient creates a new Azure AI Gateway client
 func NewClient() *Client {
 	vendorName := "AzureAIGateway"
-	client := &Client{}
+	client := &Client{timeout: ai.DefaultTimeout}
 
 	client.PluginBase = plugins.NewVendorPluginBase(vendorName, client.configure)
 
@@ -83,6 +117,12 @@ func NewClient() *Client {
 		i18n.T("azureaigateway_subscription_key_question"))
 	client.APIVersion = client.AddSetupQuestionCustom("api_version", false,
 		i18n.T("azureaigateway_api_version_question"))
+	client.MaxRetries = client.AddSetupQuestionCustom("max_retries", false,
+		fmt.Sprintf(i18n.T("azureaigateway_max_retries_question"), defaultMaxRetries))
+	client.ModelDiscovery = client.AddSetupQuestionCustom("model_discovery", false,
+		i18n.T("azureaigateway_model_discovery_question"))
+	client.SafetyThreshold = client.AddSetupQuestionCustom("safety_threshold", false,
+		i18n.T("azureaigateway_safety_threshold_question"))
 
 	return client
 }
@@ -110,15 +150,37 @@ func (c *Client) configure() error {
 		c.BackendType.Value = backendType
 	}
 
-	c.httpClient = &http.Client{Timeout: gatewayTimeout}
+	c.maxRetries = defaultMaxRetries
+	if value := strings.TrimSpace(c.MaxRetries.Value); value != "" {
+		if c.maxRetries, err = strconv.Atoi(value); err != nil || c.maxRetries < 0 {
+			return fmt.Errorf(i18n.T("azureaigateway_invalid_max_retries"), value)
+		}
+	}
+
+	modelDiscovery := false
+	if value := strings.TrimSpace(c.ModelDiscovery.Value); value != "" {
+		if modelDiscovery, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf(i18n.T("azureaigateway_invalid_model_discovery"), value)
+		}
+	}
+
+	c.httpClient = &http.Client{Transport: &ai.ResponseTimeoutTransport{Timeout: c.timeout}}
 
 	switch backendType {
 	case "bedrock":
-		c.backend = NewBedrockBackend(c.SubscriptionKey.Value)
+		bedrock := NewBedrockBackend(c.SubscriptionKey.Value)
+		if modelDiscovery {
+			bedrock.EnableModelDiscovery(c.GatewayURL.Value, c.httpClient)
+		}
+		c.backend = bedrock
 	case "azure-openai":
 		c.backend = NewAzureOpenAIBackend(c.SubscriptionKey.Value, c.APIVersion.Value)
 	case "vertex-ai":
-		c.backend = NewVertexAIBackend(c.SubscriptionKey.Value)
+		vertexAI := NewVertexAIBackend(c.SubscriptionKey.Value)
+		if err = vertexAI.SetSafetyThreshold(c.SafetyThreshold.Value); err != nil {
+			return err
+		}
+		c.backend = vertexAI
 	default:
 		return fmt.Errorf(i18n.T("azureaigateway_unsupported_backend"), backendType)
 	}
@@ -126,6 +188,15 @@ func (c *Client) configure() error {
 	return nil
 }
 
+// SetTimeout bounds how long each gateway request waits for the response to
+// start. A stream that has started is not cut off.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	if c.httpClient != nil {
+		ai.SetResponseTimeout(c.httpClient, timeout)
+	}
+}
+
 // IsConfigured returns true if both gateway URL and subscription key are configured
 func (c *Client) IsConfigured() bool {
 	return c.GatewayURL.Value != "" && c.SubscriptionKey.Value != ""
@@ -151,62 +222,134 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 		return "", fmt.Errorf(i18n.T("azureaigateway_prepare_request_failed"), err)
 	}
 
-	endpoint := c.backend.BuildEndpoint(c.GatewayURL.Value, opts.Model)
-	debuglog.Debug(debuglog.Detailed, "AzureAIGateway request to %s\n", endpoint)
+	resp, err := c.post(ctx, c.backend.BuildEndpoint(c.GatewayURL.Value, opts.Model), bodyBytes)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(bodyBytes))
+	respBody, err := readResponseBody(resp)
 	if err != nil {
-		return "", fmt.Errorf(i18n.T("azureaigateway_failed_create_request"), err)
+		return "", err
+	}
+	ai.DumpRawResponse(opts, respBody)
+	if resp.StatusCode != http.StatusOK {
+		return "", httpError(resp.StatusCode, respBody)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	headerName, headerValue := c.backend.AuthHeader()
-	req.Header.Set(headerName, headerValue)
+	return c.backend.ParseResponse(respBody)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf(i18n.T("azureaigateway_http_request_failed"), err)
+// post sends bodyBytes to endpoint, retrying with exponential backoff while the
+// gateway answers with a retryable status. The caller must close the body of
+// the returned response.
+func (c *Client) post(ctx context.Context, endpoint string, bodyBytes []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		debuglog.Debug(debuglog.Detailed, "AzureAIGateway request to %s\n", endpoint)
+
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("azureaigateway_failed_create_request"), err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		headerName, headerValue := c.backend.AuthHeader()
+		req.Header.Set(headerName, headerValue)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("azureaigateway_http_request_failed"), err)
+		}
+
+		debuglog.Debug(debuglog.Detailed, "AzureAIGateway response status: %d\n", resp.StatusCode)
+
+		if !isRetryableStatus(resp.StatusCode) || attempt >= c.maxRetries {
+			return resp, nil
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+		resp.Body.Close()
+
+		delay := retryDelay(attempt, resp.Header.Get("Retry-After"))
+		debuglog.Debug(debuglog.Detailed, "AzureAIGateway HTTP %d, retrying in %s (attempt %d of %d)\n", resp.StatusCode, delay, attempt+1, c.maxRetries)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf(i18n.T("azureaigateway_http_request_failed"), ctx.Err())
+		case <-time.After(delay):
+		}
 	}
-	defer resp.Body.Close()
+}
 
+// maxResponseSize limits how much of a non-streaming response body is read.
+const maxResponseSize = 10 * 1024 * 1024
+
+// readResponseBody reads the response body, failing if it exceeds maxResponseSize.
+func readResponseBody(resp *http.Response) ([]byte, error) {
 	// Read up to 10MB+1 byte to detect truncation
-	const maxResponseSize = 10 * 1024 * 1024
 	limitedBody := io.LimitReader(resp.Body, maxResponseSize+1)
 	respBody, err := io.ReadAll(limitedBody)
 	if err != nil {
-		return "", fmt.Errorf(i18n.T("azureaigateway_failed_read_response"), err)
+		return nil, fmt.Errorf(i18n.T("azureaigateway_failed_read_response"), err)
 	}
 	if len(respBody) > maxResponseSize {
-		return "", fmt.Errorf(i18n.T("azureaigateway_response_too_large"), maxResponseSize)
+		return nil, fmt.Errorf(i18n.T("azureaigateway_response_too_large"), maxResponseSize)
 	}
+	return respBody, nil
+}
 
-	debuglog.Debug(debuglog.Detailed, "AzureAIGateway response status: %d\n", resp.StatusCode)
+// httpError builds the error for a non-200 gateway response, truncating the body.
+func httpError(statusCode int, respBody []byte) error {
+	debugBody := string(respBody)
+	if len(debugBody) > 2000 {
+		debugBody = debugBody[:2000] + "...[truncated]"
+	}
+	debuglog.Debug(debuglog.Detailed, "AzureAIGateway error body: %s\n", debugBody)
+	errMsg := string(respBody)
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500] + "..."
+	}
+	return fmt.Errorf(i18n.T("azureaigateway_http_error"), statusCode, errMsg)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		debugBody := string(respBody)
-		if len(debugBody) > 2000 {
-			debugBody = debugBody[:2000] + "...[truncated]"
-		}
-		debuglog.Debug(debuglog.Detailed, "AzureAIGateway error body: %s\n", debugBody)
-		errMsg := string(respBody)
-		if len(errMsg) > 500 {
-			errMsg = errMsg[:500] + "..."
-		}
-		return "", fmt.Errorf(i18n.T("azureaigateway_http_error"), resp.StatusCode, errMsg)
+// isRetryableStatus reports whether the gateway status indicates throttling or
+// a transient upstream failure worth retrying.
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
 	}
+	return false
+}
 
-	return c.backend.ParseResponse(respBody)
+// retryDelay returns how long to wait before the next attempt. A Retry-After
+// header (seconds or HTTP date) takes precedence; otherwise the delay grows
+// exponentially from retryBaseDelay with up to 50% random jitter.
+func retryDelay(attempt int, retryAfter string) time.Duration {
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		delay = max(time.Until(date), 0)
+	} else {
+		delay = retryBaseDelay << attempt
+		if delay <= 0 || delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		delay += rand.N(delay/2 + 1)
+	}
+	return min(delay, maxRetryDelay)
 }
 
-// SendStream falls back to non-streaming (APIM gateway doesn't support SSE pass-through).
+// SendStream streams Server-Sent Events for backends that implement
+// StreamingBackend. Other backends fall back to a single non-streaming call.
 func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	defer close(channel)
 	if c.backend == nil {
 		return errors.New(i18n.T("azureaigateway_backend_not_initialized"))
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, gatewayTimeout)
-	defer cancel()
+	if streamingBackend, ok := c.backend.(StreamingBackend); ok {
+		return c.sendStreamSSE(ctx, streamingBackend, msgs, opts, channel)
+	}
 
 	result, err := c.Send(ctx, msgs, opts)
 	if err != nil {
@@ -218,3 +361,48 @@ func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMess
 	}
 	return nil
 }
+
+// sendStreamSSE sends a streaming request and forwards each parsed SSE event.
+func (c *Client) sendStreamSSE(ctx context.Context, backend StreamingBackend, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+	bodyBytes, err := backend.PrepareStreamRequest(msgs, opts)
+	if err != nil {
+		return fmt.Errorf(i18n.T("azureaigateway_prepare_request_failed"), err)
+	}
+
+	resp, err := c.post(ctx, backend.BuildStreamEndpoint(c.GatewayURL.Value, opts.Model), bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, err := readResponseBody(resp)
+		if err != nil {
+			return err
+		}
+		return httpError(resp.StatusCode, respBody)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:")); ok {
+			updates, done, parseErr := backend.ParseStreamChunk(bytes.TrimSpace(data))
+			if parseErr != nil {
+				return parseErr
+			}
+			for _, update := range updates {
+				channel <- update
+			}
+			if done {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("azureaigateway_failed_read_response"), err)
+		}
+	}
+}
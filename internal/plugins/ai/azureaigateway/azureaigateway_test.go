@@ -3,13 +3,17 @@ package azureaigateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
@@ -69,6 +73,90 @@ func TestBedrockListModels(t *testing.T) {
 	}
 }
 
+func TestBedrockListModelsDiscovery(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodGet || r.URL.Path != "/foundation-models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("byProvider") != "anthropic" {
+			t.Errorf("expected byProvider=anthropic, got %q", r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("wrong auth header: %s", r.Header.Get("Authorization"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"modelSummaries": []map[string]any{
+				{"modelId": "anthropic.claude-sonnet-4-5-20250929-v1:0", "outputModalities": []string{"TEXT"}},
+				{"modelId": "anthropic.claude-3-haiku-20240307-v1:0", "outputModalities": []string{"TEXT"}},
+				{"modelId": "anthropic.image-model-v1:0", "outputModalities": []string{"IMAGE"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	b := NewBedrockBackend("key")
+	b.EnableModelDiscovery(server.URL+"/", server.Client())
+
+	want := []string{"anthropic.claude-3-haiku-20240307-v1:0", "anthropic.claude-sonnet-4-5-20250929-v1:0"}
+	for range 2 {
+		models, err := b.ListModels(context.Background())
+		if err != nil {
+			t.Fatalf("ListModels() error = %v", err)
+		}
+		if strings.Join(models, ",") != strings.Join(want, ",") {
+			t.Errorf("ListModels() = %v, want %v", models, want)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the model list to be fetched once and cached, got %d requests", requests)
+	}
+}
+
+func TestBedrockListModelsDiscoveryFallback(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "not routed by this gateway", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	b := NewBedrockBackend("key")
+	b.EnableModelDiscovery(server.URL, server.Client())
+
+	for range 2 {
+		models, err := b.ListModels(context.Background())
+		if err != nil {
+			t.Fatalf("ListModels() error = %v", err)
+		}
+		if strings.Join(models, ",") != strings.Join(bedrockStaticModels(), ",") {
+			t.Errorf("expected static fallback list, got %v", models)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected a failed fetch not to be cached, got %d requests", requests)
+	}
+}
+
+func TestConfigureModelDiscovery(t *testing.T) {
+	c := NewClient()
+	c.GatewayURL.Value = "https://gw.example.com"
+	c.SubscriptionKey.Value = "key"
+	c.ModelDiscovery.Value = "true"
+	if err := c.configure(); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if b, ok := c.backend.(*BedrockBackend); !ok || b.discoveryURL != "https://gw.example.com" {
+		t.Errorf("expected Bedrock model discovery to be enabled, got %+v", c.backend)
+	}
+
+	c.ModelDiscovery.Value = "sometimes"
+	if err := c.configure(); err == nil {
+		t.Error("configure() expected error for invalid model_discovery")
+	}
+}
+
 func TestBedrockPrepareRequestSystemMessages(t *testing.T) {
 	b := NewBedrockBackend("key")
 	msgs := []*chat.ChatCompletionMessage{
@@ -418,6 +506,145 @@ func TestVertexAIPrepareRequestAssistantRole(t *testing.T) {
 	}
 }
 
+func TestVertexAIPrepareRequestGenerationConfig(t *testing.T) {
+	b := NewVertexAIBackend("key")
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	seed := 42
+
+	bodyBytes, err := b.PrepareRequest(msgs, &domain.ChatOptions{
+		Temperature: 0.2,
+		TopP:        0.5,
+		MaxTokens:   1024,
+		Seed:        &seed,
+	})
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	config, ok := body["generationConfig"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected 'generationConfig' in request body, got %v", body)
+	}
+	want := map[string]float64{"temperature": 0.2, "topP": 0.5, "maxOutputTokens": 1024, "seed": 42}
+	for key, value := range want {
+		if config[key] != value {
+			t.Errorf("generationConfig[%q] = %v, want %v", key, config[key], value)
+		}
+	}
+	if _, ok := config["presencePenalty"]; ok {
+		t.Error("presencePenalty should be omitted when zero")
+	}
+
+	bodyBytes, err = b.PrepareRequest(msgs, &domain.ChatOptions{
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	})
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+	body = nil
+	json.Unmarshal(bodyBytes, &body)
+	if _, ok := body["generationConfig"]; ok {
+		t.Errorf("generationConfig should be omitted at defaults, got %v", body["generationConfig"])
+	}
+}
+
+func TestVertexAIPrepareRequestSafetySettings(t *testing.T) {
+	b := NewVertexAIBackend("key")
+	if err := b.SetSafetyThreshold("block_only_high"); err != nil {
+		t.Fatalf("SetSafetyThreshold() error = %v", err)
+	}
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+
+	bodyBytes, err := b.PrepareRequest(msgs, &domain.ChatOptions{
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	})
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	settings, ok := body["safetySettings"].([]any)
+	if !ok || len(settings) != len(vertexAIHarmCategories) {
+		t.Fatalf("expected %d safetySettings, got %v", len(vertexAIHarmCategories), body["safetySettings"])
+	}
+	for i, setting := range settings {
+		entry := setting.(map[string]any)
+		if entry["category"] != vertexAIHarmCategories[i] || entry["threshold"] != "BLOCK_ONLY_HIGH" {
+			t.Errorf("safetySettings[%d] = %v", i, entry)
+		}
+	}
+
+	if err := b.SetSafetyThreshold("BLOCK_EVERYTHING"); err == nil {
+		t.Error("SetSafetyThreshold() expected error for invalid threshold")
+	}
+}
+
+func TestConfigureSafetyThreshold(t *testing.T) {
+	c := NewClient()
+	c.BackendType.Value = "vertex-ai"
+	c.GatewayURL.Value = "https://gw.example.com"
+	c.SubscriptionKey.Value = "key"
+	c.SafetyThreshold.Value = "BLOCK_NONE"
+	if err := c.configure(); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if b, ok := c.backend.(*VertexAIBackend); !ok || b.safetyThreshold != "BLOCK_NONE" {
+		t.Errorf("expected Vertex AI safety threshold to be set, got %+v", c.backend)
+	}
+
+	c.SafetyThreshold.Value = "lenient"
+	if err := c.configure(); err == nil {
+		t.Error("configure() expected error for invalid safety_threshold")
+	}
+}
+
+func TestPrepareRequestStopSequences(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	opts := &domain.ChatOptions{
+		Temperature:   domain.DefaultTemperature,
+		TopP:          domain.DefaultTopP,
+		StopSequences: []string{"END", "###"},
+	}
+
+	tests := []struct {
+		name    string
+		backend Backend
+		stop    func(body map[string]any) any
+	}{
+		{"bedrock", NewBedrockBackend("key"), func(body map[string]any) any { return body["stop_sequences"] }},
+		{"azure-openai", NewAzureOpenAIBackend("key", ""), func(body map[string]any) any { return body["stop"] }},
+		{"vertex-ai", NewVertexAIBackend("key"), func(body map[string]any) any {
+			config, _ := body["generationConfig"].(map[string]any)
+			return config["stopSequences"]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, err := tt.backend.PrepareRequest(msgs, opts)
+			if err != nil {
+				t.Fatalf("PrepareRequest() error = %v", err)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(bodyBytes, &body); err != nil {
+				t.Fatalf("failed to unmarshal body: %v", err)
+			}
+			got, _ := tt.stop(body).([]any)
+			if len(got) != 2 || got[0] != "END" || got[1] != "###" {
+				t.Errorf("stop sequences = %v, want [END ###]", tt.stop(body))
+			}
+		})
+	}
+}
+
 func TestVertexAIParseResponse(t *testing.T) {
 	b := NewVertexAIBackend("key")
 	respJSON := `{"candidates":[{"content":{"parts":[{"text":"Hello world"}]}}]}`
@@ -1013,3 +1240,488 @@ func TestBedrockTemperatureTopPMutualExclusivity(t *testing.T) {
 		t.Error("temperature should not be present when topP is non-default (mutual exclusivity)")
 	}
 }
+
+// --- Retry Tests ---
+
+func TestSendRetriesTransientErrors(t *testing.T) {
+	oldDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = oldDelay }()
+
+	attempts := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": "upstream unavailable"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Recovered"}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewBedrockBackend("test-key")
+	c.maxRetries = defaultMaxRetries
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	result, err := c.Send(context.Background(), msgs, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result != "Recovered" {
+		t.Errorf("Send() = %q, want %q", result, "Recovered")
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestSendRetriesExhausted(t *testing.T) {
+	attempts := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": "rate limited"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewBedrockBackend("test-key")
+	c.maxRetries = 2
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	_, err := c.Send(context.Background(), msgs, &domain.ChatOptions{Model: "test-model"})
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("Send() expected 429 error, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts (1 + 2 retries), got %d", attempts)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if got := retryDelay(0, "7"); got != 7*time.Second {
+		t.Errorf("retryDelay() with Retry-After seconds = %s, want 7s", got)
+	}
+	if got := retryDelay(0, "3600"); got != maxRetryDelay {
+		t.Errorf("retryDelay() should cap Retry-After at %s, got %s", maxRetryDelay, got)
+	}
+	for attempt := range 3 {
+		base := retryBaseDelay << attempt
+		if got := retryDelay(attempt, ""); got < base || got > base+base/2 {
+			t.Errorf("retryDelay(%d) = %s, want between %s and %s", attempt, got, base, base+base/2)
+		}
+	}
+}
+
+func TestConfigureMaxRetries(t *testing.T) {
+	c := NewClient()
+	c.GatewayURL.Value = "https://gateway.example.com"
+	c.SubscriptionKey.Value = "test-key"
+
+	if err := c.configure(); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if c.maxRetries != defaultMaxRetries {
+		t.Errorf("maxRetries = %d, want default %d", c.maxRetries, defaultMaxRetries)
+	}
+
+	c.MaxRetries.Value = "5"
+	if err := c.configure(); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if c.maxRetries != 5 {
+		t.Errorf("maxRetries = %d, want 5", c.maxRetries)
+	}
+
+	c.MaxRetries.Value = "-1"
+	if err := c.configure(); err == nil {
+		t.Error("configure() expected error for negative max_retries")
+	}
+}
+
+// --- Streaming Tests ---
+
+func TestAzureOpenAIPrepareStreamRequest(t *testing.T) {
+	b := NewAzureOpenAIBackend("key", "")
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+
+	body, err := b.PrepareStreamRequest(msgs, &domain.ChatOptions{Temperature: domain.DefaultTemperature, TopP: domain.DefaultTopP})
+	if err != nil {
+		t.Fatalf("PrepareStreamRequest() error = %v", err)
+	}
+	var req map[string]any
+	json.Unmarshal(body, &req)
+	if req["stream"] != true {
+		t.Errorf("expected stream=true, got %v", req["stream"])
+	}
+	if _, ok := req["stream_options"]; !ok {
+		t.Error("expected stream_options in streaming request")
+	}
+}
+
+func TestSendStreamAzureOpenAISSE(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("expected streaming request body, got %s", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\" world\"}}]}\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewAzureOpenAIBackend("test-key", "")
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	channel := make(chan domain.StreamUpdate, 10)
+	if err := c.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "gpt-4o"}, channel); err != nil {
+		t.Fatalf("SendStream() error = %v", err)
+	}
+
+	var content []string
+	var usage *domain.UsageMetadata
+	for update := range channel {
+		switch update.Type {
+		case domain.StreamTypeContent:
+			content = append(content, update.Content)
+		case domain.StreamTypeUsage:
+			usage = update.Usage
+		}
+	}
+	if strings.Join(content, "|") != "Hello| world" {
+		t.Errorf("expected incremental chunks, got %q", content)
+	}
+	if usage == nil || usage.TotalTokens != 5 {
+		t.Errorf("expected usage with 5 total tokens, got %+v", usage)
+	}
+}
+
+func TestSendStreamAzureOpenAIHTTPError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "bad request"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewAzureOpenAIBackend("test-key", "")
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	channel := make(chan domain.StreamUpdate, 10)
+	err := c.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "gpt-4o"}, channel)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("SendStream() expected 400 error, got %v", err)
+	}
+}
+
+func TestVertexAIBuildStreamEndpoint(t *testing.T) {
+	b := NewVertexAIBackend("key")
+	got := b.BuildStreamEndpoint("https://gw.example.com/", "gemini-2.5-flash")
+	want := "https://gw.example.com/publishers/google/models/gemini-2.5-flash:streamGenerateContent?alt=sse"
+	if got != want {
+		t.Errorf("BuildStreamEndpoint() = %q, want %q", got, want)
+	}
+}
+
+func TestSendStreamVertexAISSE(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ":streamGenerateContent") || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("unexpected streaming endpoint: %s", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"The sky\"}]}}]}\r\n\r\n" +
+			"data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\" is \"},{\"text\":\"blue.\"}]}}]}\r\n\r\n" +
+			"data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":4,\"candidatesTokenCount\":5,\"totalTokenCount\":9}}\r\n\r\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewVertexAIBackend("test-key")
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Why is the sky blue?"}}
+	channel := make(chan domain.StreamUpdate, 10)
+	if err := c.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "gemini-2.5-flash"}, channel); err != nil {
+		t.Fatalf("SendStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	var chunks int
+	var usage *domain.UsageMetadata
+	for update := range channel {
+		switch update.Type {
+		case domain.StreamTypeContent:
+			content.WriteString(update.Content)
+			chunks++
+		case domain.StreamTypeUsage:
+			usage = update.Usage
+		}
+	}
+	if content.String() != "The sky is blue." {
+		t.Errorf("concatenated text = %q, want %q", content.String(), "The sky is blue.")
+	}
+	if chunks != 3 {
+		t.Errorf("expected 3 incremental chunks, got %d", chunks)
+	}
+	if usage == nil || usage.InputTokens != 4 || usage.OutputTokens != 5 || usage.TotalTokens != 9 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestAzureOpenAIPrepareRequestReasoningModelOmitsSampling(t *testing.T) {
+	b := NewAzureOpenAIBackend("key", "")
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+
+	body, err := b.PrepareRequest(msgs, &domain.ChatOptions{Model: "o1", Temperature: 0.2, TopP: 0.5})
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+	var req map[string]any
+	json.Unmarshal(body, &req)
+	if _, ok := req["temperature"]; ok {
+		t.Error("temperature should be omitted for o1 deployments")
+	}
+	if _, ok := req["top_p"]; ok {
+		t.Error("top_p should be omitted for o1 deployments")
+	}
+}
+
+func imageMessage(url string) *chat.ChatCompletionMessage {
+	return &chat.ChatCompletionMessage{
+		Role: chat.ChatMessageRoleUser,
+		MultiContent: []chat.ChatMessagePart{
+			{Type: chat.ChatMessagePartTypeText, Text: "What is this?"},
+			{Type: chat.ChatMessagePartTypeImageURL, ImageURL: &chat.ChatMessageImageURL{URL: url}},
+		},
+	}
+}
+
+func TestAzureOpenAIPrepareRequestImageParts(t *testing.T) {
+	b := NewAzureOpenAIBackend("key", "")
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "You are helpful."},
+		imageMessage("https://example.com/cat.png"),
+	}
+	opts := &domain.ChatOptions{Temperature: domain.DefaultTemperature, TopP: domain.DefaultTopP}
+
+	bodyBytes, err := b.PrepareRequest(msgs, opts)
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+
+	var body struct {
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	if len(body.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(body.Messages))
+	}
+	if got := string(body.Messages[0].Content); got != `"You are helpful."` {
+		t.Errorf("system content = %s, want plain string", got)
+	}
+	want := `[{"type":"text","text":"What is this?"},{"type":"image_url","image_url":{"url":"https://example.com/cat.png"}}]`
+	if got := string(body.Messages[1].Content); got != want {
+		t.Errorf("user content = %s, want %s", got, want)
+	}
+}
+
+func TestBedrockPrepareRequestImageParts(t *testing.T) {
+	b := NewBedrockBackend("key")
+	opts := &domain.ChatOptions{Temperature: domain.DefaultTemperature, TopP: domain.DefaultTopP}
+
+	bodyBytes, err := b.PrepareRequest([]*chat.ChatCompletionMessage{imageMessage("data:image/png;base64,aGVsbG8=")}, opts)
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+	var body struct {
+		Messages []struct {
+			Content []map[string]any `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	blocks := body.Messages[0].Content
+	if len(blocks) != 2 || blocks[0]["type"] != "text" || blocks[1]["type"] != "image" {
+		t.Fatalf("unexpected content blocks: %v", blocks)
+	}
+	source := blocks[1]["source"].(map[string]any)
+	if source["type"] != "base64" || source["media_type"] != "image/png" || source["data"] != "aGVsbG8=" {
+		t.Errorf("unexpected image source: %v", source)
+	}
+
+	if _, err := b.PrepareRequest([]*chat.ChatCompletionMessage{imageMessage("https://example.com/cat.png")}, opts); err == nil {
+		t.Error("expected an error for a remote image URL")
+	}
+}
+
+func TestVertexAIPrepareRequestImageParts(t *testing.T) {
+	b := NewVertexAIBackend("key")
+	opts := &domain.ChatOptions{Temperature: domain.DefaultTemperature, TopP: domain.DefaultTopP}
+
+	bodyBytes, err := b.PrepareRequest([]*chat.ChatCompletionMessage{imageMessage("data:image/jpeg;base64,aGVsbG8=")}, opts)
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+	var body struct {
+		Contents []struct {
+			Parts []map[string]any `json:"parts"`
+		} `json:"contents"`
+	}
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	parts := body.Contents[0].Parts
+	if len(parts) != 2 || parts[0]["text"] != "What is this?" {
+		t.Fatalf("unexpected parts: %v", parts)
+	}
+	inline, ok := parts[1]["inlineData"].(map[string]any)
+	if !ok || inline["mimeType"] != "image/jpeg" || inline["data"] != "aGVsbG8=" {
+		t.Errorf("unexpected inline data: %v", parts[1])
+	}
+
+	if _, err := b.PrepareRequest([]*chat.ChatCompletionMessage{imageMessage("data:image/png,raw")}, opts); err == nil {
+		t.Error("expected an error for a non-base64 data URL")
+	}
+}
+
+func TestSendDumpsRawResponse(t *testing.T) {
+	const rawBody = `{"content":[{"type":"text","text":"Dumped"}],"stop_reason":"end_turn"}`
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, rawBody)
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewBedrockBackend("test-key")
+
+	dumpFile := filepath.Join(t.TempDir(), "raw.json")
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	opts := &domain.ChatOptions{
+		Model:       "us.anthropic.claude-3-haiku-20240307-v1:0",
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+		DumpRawFile: dumpFile,
+	}
+
+	if _, err := c.Send(context.Background(), msgs, opts); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	dumped, err := os.ReadFile(dumpFile)
+	if err != nil {
+		t.Fatalf("failed to read dump file: %v", err)
+	}
+	if got := string(dumped); got != rawBody+"\n" {
+		t.Errorf("dump file = %q, want %q", got, rawBody+"\n")
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewBedrockBackend("test-key")
+	c.SetTimeout(100 * time.Millisecond)
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	opts := &domain.ChatOptions{
+		Model:       "test-model",
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	}
+
+	start := time.Now()
+	_, err := c.Send(context.Background(), msgs, opts)
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("Send() expected a timeout error")
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("Send() error = %v, want a timeout error", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Send() took %s, want it to fail near the 100ms timeout", elapsed)
+	}
+}
+
+func TestSendStreamTimeoutDoesNotCutOffStartedStream(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\" world\"}}]}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.GatewayURL.Value = server.URL
+	c.SubscriptionKey.Value = "test-key"
+	c.httpClient = server.Client()
+	c.backend = NewAzureOpenAIBackend("test-key", "")
+	c.SetTimeout(100 * time.Millisecond)
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+	channel := make(chan domain.StreamUpdate, 10)
+	if err := c.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "gpt-4o"}, channel); err != nil {
+		t.Fatalf("SendStream() error = %v", err)
+	}
+
+	var content []string
+	for update := range channel {
+		if update.Type == domain.StreamTypeContent {
+			content = append(content, update.Content)
+		}
+	}
+	if strings.Join(content, "|") != "Hello| world" {
+		t.Errorf("expected the whole stream, got %q", content)
+	}
+}
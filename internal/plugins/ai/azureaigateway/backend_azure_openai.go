@@ -15,7 +15,10 @@ import (
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 )
 
-// AzureOpenAIBackend implements the Backend interface for Azure OpenAI through Azure APIM Gateway
+// Ensure AzureOpenAIBackend supports SSE streaming
+var _ StreamingBackend = (*AzureOpenAIBackend)(nil)
+
+// AzureOpenAIBackend implements the StreamingBackend interface for Azure OpenAI through Azure APIM Gateway
 type AzureOpenAIBackend struct {
 	subscriptionKey string
 	apiVersion      string
@@ -61,15 +64,94 @@ func (b *AzureOpenAIBackend) AuthHeader() (string, string) {
 
 // PrepareRequest converts messages to Azure OpenAI (OpenAI-compatible) API format
 func (b *AzureOpenAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) ([]byte, error) {
-	var messages []map[string]string
+	body, err := b.buildRequestBody(msgs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(body)
+}
+
+// BuildStreamEndpoint returns the chat completions endpoint; streaming is
+// selected by the request body rather than the URL.
+func (b *AzureOpenAIBackend) BuildStreamEndpoint(baseURL, deploymentName string) string {
+	return b.BuildEndpoint(baseURL, deploymentName)
+}
+
+// PrepareStreamRequest builds a chat completions request with SSE streaming
+// and a final usage chunk enabled.
+func (b *AzureOpenAIBackend) PrepareStreamRequest(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) ([]byte, error) {
+	body, err := b.buildRequestBody(msgs, opts)
+	if err != nil {
+		return nil, err
+	}
+	body["stream"] = true
+	body["stream_options"] = map[string]any{"include_usage": true}
+	return json.Marshal(body)
+}
+
+// ParseStreamChunk parses one chat completions SSE event. The stream ends with
+// a literal "[DONE]" event.
+func (b *AzureOpenAIBackend) ParseStreamChunk(data []byte) ([]domain.StreamUpdate, bool, error) {
+	if string(data) == "[DONE]" {
+		return nil, true, nil
+	}
+
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, false, fmt.Errorf(i18n.T("azureaigateway_aoai_parse_response_failed"), err)
+	}
+
+	var updates []domain.StreamUpdate
+	for _, choice := range chunk.Choices {
+		if choice.Delta.Content != "" {
+			updates = append(updates, domain.StreamUpdate{
+				Type:    domain.StreamTypeContent,
+				Content: choice.Delta.Content,
+			})
+		}
+	}
+	if chunk.Usage != nil {
+		updates = append(updates, domain.StreamUpdate{
+			Type: domain.StreamTypeUsage,
+			Usage: &domain.UsageMetadata{
+				InputTokens:  chunk.Usage.PromptTokens,
+				OutputTokens: chunk.Usage.CompletionTokens,
+				TotalTokens:  chunk.Usage.TotalTokens,
+			},
+		})
+	}
+	return updates, false, nil
+}
+
+// buildRequestBody builds the chat completions request body shared by the
+// streaming and non-streaming calls.
+func (b *AzureOpenAIBackend) buildRequestBody(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (map[string]any, error) {
+	var messages []map[string]any
 	for _, msg := range msgs {
-		if strings.TrimSpace(msg.Content) == "" {
+		parts := messageParts(msg)
+		if len(parts) == 0 {
 			debuglog.Debug(debuglog.Basic, "Skipping empty message\n")
 			continue
 		}
-		messages = append(messages, map[string]string{
+		// Chat completions accepts the same content part array as chat.ChatMessagePart
+		var content any = partsText(parts)
+		if hasImageParts(parts) {
+			content = parts
+		}
+		messages = append(messages, map[string]any{
 			"role":    string(msg.Role),
-			"content": msg.Content,
+			"content": content,
 		})
 	}
 
@@ -82,14 +164,23 @@ func (b *AzureOpenAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage,
 	body := map[string]any{
 		"messages": messages,
 	}
-	if opts.TopP != domain.DefaultTopP {
+	// Reasoning deployments such as o1 reject sampling parameters entirely
+	samplingParams, _ := domain.ModelSamplingParams(opts.Model)
+	sendTemperature, sendTopP := samplingParams.Select(opts.TopP, opts.TopP != domain.DefaultTopP)
+	if sendTopP {
 		body["top_p"] = opts.TopP
 	}
-	if opts.Temperature != domain.DefaultTemperature {
+	if sendTemperature && opts.Temperature != domain.DefaultTemperature {
 		body["temperature"] = opts.Temperature
 	}
+	if len(opts.StopSequences) > 0 {
+		body["stop"] = opts.StopSequences
+	}
+	if opts.Seed != nil {
+		body["seed"] = *opts.Seed
+	}
 
-	return json.Marshal(body)
+	return body, nil
 }
 
 // ParseResponse parses Azure OpenAI API response (OpenAI chat completions format)
@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
@@ -17,9 +20,21 @@ import (
 
 const bedrockAnthropicVersion = "bedrock-2023-05-31"
 
+// bedrockModelsPath is the ListFoundationModels endpoint, filtered to the
+// Anthropic models this backend can prompt.
+const bedrockModelsPath = "/foundation-models?byProvider=anthropic&byOutputModality=TEXT"
+
 // BedrockBackend implements the Backend interface for AWS Bedrock through Azure APIM Gateway
 type BedrockBackend struct {
 	subscriptionKey string
+
+	// discoveryURL is the gateway base URL ListModels queries for the live
+	// model list; discovery is disabled while it is empty.
+	discoveryURL string
+	httpClient   *http.Client
+
+	modelsMu sync.Mutex
+	models   []string
 }
 
 // NewBedrockBackend creates a new Bedrock backend handler
@@ -27,8 +42,93 @@ func NewBedrockBackend(subscriptionKey string) *BedrockBackend {
 	return &BedrockBackend{subscriptionKey: subscriptionKey}
 }
 
-// ListModels returns the list of available Bedrock inference profiles
-func (b *BedrockBackend) ListModels(_ context.Context) ([]string, error) {
+// EnableModelDiscovery makes ListModels fetch the live model list from the
+// gateway's ListFoundationModels endpoint under baseURL.
+func (b *BedrockBackend) EnableModelDiscovery(baseURL string, httpClient *http.Client) {
+	b.discoveryURL = baseURL
+	b.httpClient = httpClient
+}
+
+// ListModels returns the available Bedrock models. With model discovery
+// enabled, the live list is fetched once and cached for the process; the
+// static list of inference profiles is returned when discovery is disabled
+// or the request fails.
+func (b *BedrockBackend) ListModels(ctx context.Context) ([]string, error) {
+	if b.discoveryURL == "" {
+		return bedrockStaticModels(), nil
+	}
+
+	b.modelsMu.Lock()
+	defer b.modelsMu.Unlock()
+	if b.models != nil {
+		return b.models, nil
+	}
+	models, err := b.fetchModels(ctx)
+	if err != nil {
+		debuglog.Debug(debuglog.Basic, "Bedrock ListFoundationModels failed, using static model list: %v\n", err)
+		return bedrockStaticModels(), nil
+	}
+	b.models = models
+	return models, nil
+}
+
+// fetchModels queries ListFoundationModels through the gateway.
+func (b *BedrockBackend) fetchModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(b.discoveryURL, "/")+bedrockModelsPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("azureaigateway_failed_create_request"), err)
+	}
+	headerName, headerValue := b.AuthHeader()
+	req.Header.Set(headerName, headerValue)
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("azureaigateway_http_request_failed"), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpError(resp.StatusCode, body)
+	}
+	return parseFoundationModels(body)
+}
+
+// parseFoundationModels returns the IDs of the text models in a
+// ListFoundationModels response, sorted.
+func parseFoundationModels(body []byte) ([]string, error) {
+	var resp struct {
+		ModelSummaries []struct {
+			ModelID          string   `json:"modelId"`
+			OutputModalities []string `json:"outputModalities"`
+		} `json:"modelSummaries"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf(i18n.T("azureaigateway_bedrock_parse_models_failed"), err)
+	}
+
+	var models []string
+	for _, summary := range resp.ModelSummaries {
+		if summary.ModelID == "" {
+			continue
+		}
+		if len(summary.OutputModalities) > 0 && !slices.Contains(summary.OutputModalities, "TEXT") {
+			continue
+		}
+		models = append(models, summary.ModelID)
+	}
+	if len(models) == 0 {
+		return nil, errors.New(i18n.T("azureaigateway_bedrock_no_models"))
+	}
+	slices.Sort(models)
+	return models, nil
+}
+
+// bedrockStaticModels returns the built-in list of Bedrock inference profiles.
+func bedrockStaticModels() []string {
 	return []string{
 		"us.anthropic.claude-3-haiku-20240307-v1:0",
 		"us.anthropic.claude-3-opus-20240229-v1:0",
@@ -44,7 +144,7 @@ func (b *BedrockBackend) ListModels(_ context.Context) ([]string, error) {
 		"us.anthropic.claude-opus-4-6-v1:0",
 		"us.anthropic.claude-sonnet-4-20250514-v1:0",
 		"us.anthropic.claude-sonnet-4-5-20250929-v1:0",
-	}, nil
+	}
 }
 
 // BuildEndpoint constructs the Bedrock API endpoint URL
@@ -63,17 +163,26 @@ func (b *BedrockBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opts
 	var systemParts []string
 	var messages []map[string]any
 	for _, msg := range msgs {
-		if strings.TrimSpace(msg.Content) == "" {
+		parts := messageParts(msg)
+		if len(parts) == 0 {
 			debuglog.Debug(debuglog.Basic, "Skipping empty message\n")
 			continue
 		}
 		if msg.Role == chat.ChatMessageRoleSystem {
-			systemParts = append(systemParts, msg.Content)
+			systemParts = append(systemParts, partsText(parts))
 			continue
 		}
+		var content any = partsText(parts)
+		if hasImageParts(parts) {
+			blocks, err := bedrockContentBlocks(parts)
+			if err != nil {
+				return nil, err
+			}
+			content = blocks
+		}
 		messages = append(messages, map[string]any{
 			"role":    string(msg.Role),
-			"content": msg.Content,
+			"content": content,
 		})
 	}
 
@@ -97,16 +206,58 @@ func (b *BedrockBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opts
 		body["system"] = strings.Join(systemParts, "\n\n")
 	}
 	// Anthropic API: temperature and top_p are mutually exclusive
-	// Set only the non-default parameter to avoid API conflicts
-	if opts.TopP != domain.DefaultTopP {
+	// Set only the non-default parameter to avoid API conflicts.
+	// This backend speaks the Anthropic Messages format, so unregistered models
+	// are treated as Claude models.
+	samplingParams, ok := domain.ModelSamplingParams(opts.Model)
+	if !ok {
+		samplingParams = domain.AnthropicSamplingParams
+	}
+	sendTemperature, sendTopP := samplingParams.Select(opts.TopP, opts.TopP != domain.DefaultTopP)
+	if sendTopP {
 		body["top_p"] = opts.TopP
-	} else {
+	}
+	if sendTemperature {
 		body["temperature"] = opts.Temperature
 	}
+	if len(opts.StopSequences) > 0 {
+		body["stop_sequences"] = opts.StopSequences
+	}
 
 	return json.Marshal(body)
 }
 
+// bedrockContentBlocks converts message parts to Anthropic content blocks.
+// Bedrock only accepts inline base64 sources, so images must be data URLs.
+func bedrockContentBlocks(parts []chat.ChatMessagePart) ([]map[string]any, error) {
+	blocks := make([]map[string]any, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == chat.ChatMessagePartTypeText {
+			blocks = append(blocks, map[string]any{"type": "text", "text": part.Text})
+			continue
+		}
+		mimeType, data, err := parseDataURL("Bedrock", part.ImageURL.URL)
+		if err != nil {
+			return nil, err
+		}
+		blockType := "image"
+		if strings.EqualFold(mimeType, "application/pdf") {
+			blockType = "document"
+		} else if !strings.HasPrefix(strings.ToLower(mimeType), "image/") {
+			return nil, fmt.Errorf(i18n.T("azureaigateway_unsupported_attachment_type"), "Bedrock", mimeType)
+		}
+		blocks = append(blocks, map[string]any{
+			"type": blockType,
+			"source": map[string]any{
+				"type":       "base64",
+				"media_type": mimeType,
+				"data":       data,
+			},
+		})
+	}
+	return blocks, nil
+}
+
 // ParseResponse parses Bedrock API response (Anthropic content blocks)
 func (b *BedrockBackend) ParseResponse(body []byte) (string, error) {
 	var resp struct {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/chat"
@@ -15,10 +16,31 @@ import (
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 )
 
-// VertexAIBackend implements the Backend interface for Google Vertex AI (Gemini)
+// Ensure VertexAIBackend supports SSE streaming
+var _ StreamingBackend = (*VertexAIBackend)(nil)
+
+// VertexAIBackend implements the StreamingBackend interface for Google Vertex AI (Gemini)
 // through Azure APIM Gateway.
 type VertexAIBackend struct {
 	subscriptionKey string
+	safetyThreshold string
+}
+
+// vertexAIHarmCategories are the harm categories a safety threshold applies to.
+var vertexAIHarmCategories = []string{
+	"HARM_CATEGORY_HATE_SPEECH",
+	"HARM_CATEGORY_DANGEROUS_CONTENT",
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"HARM_CATEGORY_HARASSMENT",
+}
+
+// vertexAISafetyThresholds lists the accepted HarmBlockThreshold values.
+var vertexAISafetyThresholds = []string{
+	"BLOCK_LOW_AND_ABOVE",
+	"BLOCK_MEDIUM_AND_ABOVE",
+	"BLOCK_ONLY_HIGH",
+	"BLOCK_NONE",
+	"OFF",
 }
 
 // NewVertexAIBackend creates a new Vertex AI backend handler
@@ -26,6 +48,17 @@ func NewVertexAIBackend(subscriptionKey string) *VertexAIBackend {
 	return &VertexAIBackend{subscriptionKey: subscriptionKey}
 }
 
+// SetSafetyThreshold sends a safetySettings block that applies threshold to
+// every harm category. An empty threshold leaves the model defaults in place.
+func (b *VertexAIBackend) SetSafetyThreshold(threshold string) error {
+	threshold = strings.ToUpper(strings.TrimSpace(threshold))
+	if threshold != "" && !slices.Contains(vertexAISafetyThresholds, threshold) {
+		return fmt.Errorf(i18n.T("azureaigateway_invalid_safety_threshold"), threshold, strings.Join(vertexAISafetyThresholds, ", "))
+	}
+	b.safetyThreshold = threshold
+	return nil
+}
+
 // ListModels returns the list of Gemini models available through Vertex AI
 func (b *VertexAIBackend) ListModels(_ context.Context) ([]string, error) {
 	return []string{
@@ -47,6 +80,13 @@ func (b *VertexAIBackend) BuildEndpoint(baseURL, model string) string {
 		strings.TrimSuffix(baseURL, "/"), url.PathEscape(model))
 }
 
+// BuildStreamEndpoint constructs the Vertex AI streaming endpoint URL.
+// alt=sse makes streamGenerateContent return Server-Sent Events instead of a JSON array.
+func (b *VertexAIBackend) BuildStreamEndpoint(baseURL, model string) string {
+	return fmt.Sprintf("%s/publishers/google/models/%s:streamGenerateContent?alt=sse",
+		strings.TrimSuffix(baseURL, "/"), url.PathEscape(model))
+}
+
 // AuthHeader returns the Vertex AI auth header (Google API key via APIM)
 func (b *VertexAIBackend) AuthHeader() (string, string) {
 	return "x-goog-api-key", b.subscriptionKey
@@ -58,23 +98,26 @@ func (b *VertexAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opt
 	var systemParts []string
 	var contents []map[string]any
 	for _, msg := range msgs {
-		if strings.TrimSpace(msg.Content) == "" {
+		parts := messageParts(msg)
+		if len(parts) == 0 {
 			debuglog.Debug(debuglog.Basic, "Skipping empty message\n")
 			continue
 		}
 		if msg.Role == chat.ChatMessageRoleSystem {
-			systemParts = append(systemParts, msg.Content)
+			systemParts = append(systemParts, partsText(parts))
 			continue
 		}
 		role := string(msg.Role)
 		if msg.Role == chat.ChatMessageRoleAssistant {
 			role = "model"
 		}
+		geminiParts, err := vertexAIParts(parts)
+		if err != nil {
+			return nil, err
+		}
 		contents = append(contents, map[string]any{
-			"role": role,
-			"parts": []map[string]string{
-				{"text": msg.Content},
-			},
+			"role":  role,
+			"parts": geminiParts,
 		})
 	}
 
@@ -95,6 +138,26 @@ func (b *VertexAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opt
 		}
 	}
 
+	if generationConfig := vertexAIGenerationConfig(opts); len(generationConfig) > 0 {
+		body["generationConfig"] = generationConfig
+	}
+	if b.safetyThreshold != "" {
+		safetySettings := make([]map[string]string, 0, len(vertexAIHarmCategories))
+		for _, category := range vertexAIHarmCategories {
+			safetySettings = append(safetySettings, map[string]string{
+				"category":  category,
+				"threshold": b.safetyThreshold,
+			})
+		}
+		body["safetySettings"] = safetySettings
+	}
+
+	return json.Marshal(body)
+}
+
+// vertexAIGenerationConfig maps the chat options that differ from Fabric's
+// defaults to Gemini generationConfig fields.
+func vertexAIGenerationConfig(opts *domain.ChatOptions) map[string]any {
 	generationConfig := make(map[string]any)
 	if opts.TopP != domain.DefaultTopP {
 		generationConfig["topP"] = opts.TopP
@@ -102,11 +165,45 @@ func (b *VertexAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opt
 	if opts.Temperature != domain.DefaultTemperature {
 		generationConfig["temperature"] = opts.Temperature
 	}
-	if len(generationConfig) > 0 {
-		body["generationConfig"] = generationConfig
+	if opts.MaxTokens > 0 {
+		generationConfig["maxOutputTokens"] = opts.MaxTokens
+	}
+	if opts.PresencePenalty != 0 {
+		generationConfig["presencePenalty"] = opts.PresencePenalty
+	}
+	if opts.FrequencyPenalty != 0 {
+		generationConfig["frequencyPenalty"] = opts.FrequencyPenalty
+	}
+	if opts.Seed != nil {
+		generationConfig["seed"] = *opts.Seed
+	}
+	if len(opts.StopSequences) > 0 {
+		generationConfig["stopSequences"] = opts.StopSequences
 	}
+	return generationConfig
+}
 
-	return json.Marshal(body)
+// vertexAIParts converts message parts to Gemini parts. Images are sent as
+// inline data, so they must be data URLs.
+func vertexAIParts(parts []chat.ChatMessagePart) ([]map[string]any, error) {
+	ret := make([]map[string]any, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == chat.ChatMessagePartTypeText {
+			ret = append(ret, map[string]any{"text": part.Text})
+			continue
+		}
+		mimeType, data, err := parseDataURL("Vertex AI", part.ImageURL.URL)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, map[string]any{
+			"inlineData": map[string]any{
+				"mimeType": mimeType,
+				"data":     data,
+			},
+		})
+	}
+	return ret, nil
 }
 
 // ParseResponse parses Gemini API response (candidates/content/parts)
@@ -135,3 +232,58 @@ func (b *VertexAIBackend) ParseResponse(body []byte) (string, error) {
 	}
 	return strings.Join(parts, ""), nil
 }
+
+// PrepareStreamRequest uses the same body as PrepareRequest; streaming is
+// selected by the endpoint.
+func (b *VertexAIBackend) PrepareStreamRequest(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) ([]byte, error) {
+	return b.PrepareRequest(msgs, opts)
+}
+
+// ParseStreamChunk parses one streamGenerateContent SSE event. Each event is a
+// partial GenerateContentResponse; the stream ends when the connection closes.
+func (b *VertexAIBackend) ParseStreamChunk(data []byte) ([]domain.StreamUpdate, bool, error) {
+	var chunk struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+		UsageMetadata *struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			TotalTokenCount      int `json:"totalTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, false, fmt.Errorf(i18n.T("azureaigateway_vertexai_parse_response_failed"), err)
+	}
+
+	var updates []domain.StreamUpdate
+	finished := false
+	if len(chunk.Candidates) > 0 {
+		finished = chunk.Candidates[0].FinishReason != ""
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			if part.Text != "" {
+				updates = append(updates, domain.StreamUpdate{
+					Type:    domain.StreamTypeContent,
+					Content: part.Text,
+				})
+			}
+		}
+	}
+	// Usage metadata may be repeated on intermediate events; report the final totals only.
+	if finished && chunk.UsageMetadata != nil {
+		updates = append(updates, domain.StreamUpdate{
+			Type: domain.StreamTypeUsage,
+			Usage: &domain.UsageMetadata{
+				InputTokens:  chunk.UsageMetadata.PromptTokenCount,
+				OutputTokens: chunk.UsageMetadata.CandidatesTokenCount,
+				TotalTokens:  chunk.UsageMetadata.TotalTokenCount,
+			},
+		})
+	}
+	return updates, false, nil
+}
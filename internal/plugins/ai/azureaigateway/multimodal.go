@@ -0,0 +1,76 @@
+// Package azureaigateway - helpers for translating multimodal message parts
+package azureaigateway
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// messageParts returns the non-empty parts of msg. Content, when set, becomes a
+// leading text part so callers only have to handle one representation.
+func messageParts(msg *chat.ChatCompletionMessage) []chat.ChatMessagePart {
+	var parts []chat.ChatMessagePart
+	if strings.TrimSpace(msg.Content) != "" {
+		parts = append(parts, chat.ChatMessagePart{Type: chat.ChatMessagePartTypeText, Text: msg.Content})
+	}
+	for _, part := range msg.MultiContent {
+		switch part.Type {
+		case chat.ChatMessagePartTypeText:
+			if strings.TrimSpace(part.Text) != "" {
+				parts = append(parts, part)
+			}
+		case chat.ChatMessagePartTypeImageURL:
+			if part.ImageURL != nil && part.ImageURL.URL != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return parts
+}
+
+// hasImageParts reports whether parts contain anything other than text.
+func hasImageParts(parts []chat.ChatMessagePart) bool {
+	for _, part := range parts {
+		if part.Type == chat.ChatMessagePartTypeImageURL {
+			return true
+		}
+	}
+	return false
+}
+
+// partsText joins the text parts of parts with newlines.
+func partsText(parts []chat.ChatMessagePart) string {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == chat.ChatMessagePartTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// parseDataURL splits a base64 data URL into its MIME type and payload.
+// Backends that embed images inline cannot fetch remote URLs, so anything
+// other than a data URL is reported as unsupported for backendName.
+func parseDataURL(backendName, value string) (mimeType, data string, err error) {
+	if !strings.HasPrefix(value, "data:") {
+		return "", "", fmt.Errorf(i18n.T("azureaigateway_image_url_not_supported"), backendName)
+	}
+	meta, data, found := strings.Cut(strings.TrimPrefix(value, "data:"), ",")
+	metaParts := strings.Split(meta, ";")
+	mimeType = strings.TrimSpace(metaParts[0])
+	isBase64 := false
+	for _, part := range metaParts[1:] {
+		if strings.EqualFold(strings.TrimSpace(part), "base64") {
+			isBase64 = true
+		}
+	}
+	data = strings.TrimSpace(data)
+	if !found || !isBase64 || mimeType == "" || data == "" {
+		return "", "", fmt.Errorf(i18n.T("azureaigateway_invalid_data_url"), backendName)
+	}
+	return mimeType, data, nil
+}
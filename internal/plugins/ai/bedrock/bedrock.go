@@ -504,13 +504,16 @@ func (c *BedrockClient) SendStream(_ context.Context, msgs []*chat.ChatCompletio
 	messages := c.toMessages(msgs)
 
 	// Some models (e.g., Claude on Bedrock) reject requests with both temperature
-	// and top_p set simultaneously. Only send temperature as it's the more common parameter.
+	// and top_p set simultaneously. Only send temperature as it's the more common parameter,
+	// and omit it for models registered as not accepting it.
+	inferenceConfig := &types.InferenceConfiguration{}
+	if samplingParams, _ := domain.ModelSamplingParams(opts.Model); samplingParams.Temperature {
+		inferenceConfig.Temperature = aws.Float32(float32(opts.Temperature))
+	}
 	var converseInput = bedrockruntime.ConverseStreamInput{
-		ModelId:  aws.String(opts.Model),
-		Messages: messages,
-		InferenceConfig: &types.InferenceConfiguration{
-			Temperature: aws.Float32(float32(opts.Temperature)),
-		},
+		ModelId:         aws.String(opts.Model),
+		Messages:        messages,
+		InferenceConfig: inferenceConfig,
 	}
 
 	response, err := c.runtimeClient.ConverseStream(context.Background(), &converseInput)
@@ -580,6 +583,7 @@ func (c *BedrockClient) Send(ctx context.Context, msgs []*chat.ChatCompletionMes
 	if err != nil {
 		return "", fmt.Errorf(i18n.T("bedrock_converse_failed"), opts.Model, err)
 	}
+	ai.DumpRawResponseObject(opts, response)
 
 	responseText, ok := response.Output.(*types.ConverseOutputMemberMessage)
 	if !ok {
@@ -18,6 +18,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	plugins "github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	openaivendor "github.com/danielmiessler/fabric/internal/plugins/ai/openai"
 	openaiapi "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -244,6 +245,7 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	}
 	streamedText := builder.String()
 	if completedResp != nil {
+		ai.DumpRawResponseObject(opts, completedResp)
 		if extractedText := c.ExtractText(completedResp); strings.TrimSpace(extractedText) != "" {
 			return extractedText, nil
 		}
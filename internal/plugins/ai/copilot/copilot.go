@@ -29,6 +29,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"golang.org/x/oauth2"
 )
 
@@ -178,7 +179,7 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	messageText := c.buildMessageText(msgs)
 
 	// Send the chat message
-	response, err := c.sendChatMessage(ctx, conversationID, messageText)
+	response, err := c.sendChatMessage(ctx, conversationID, messageText, opts)
 	if err != nil {
 		return "", fmt.Errorf(i18n.T("copilot_failed_send_message"), err)
 	}
@@ -264,7 +265,7 @@ func (c *Client) createConversation(ctx context.Context) (string, error) {
 }
 
 // sendChatMessage sends a message to an existing conversation (synchronous).
-func (c *Client) sendChatMessage(ctx context.Context, conversationID, messageText string) (string, error) {
+func (c *Client) sendChatMessage(ctx context.Context, conversationID, messageText string, opts *domain.ChatOptions) (string, error) {
 	url := fmt.Sprintf("%s%s/%s/chat", c.ApiBaseURL.Value, conversationsPath, conversationID)
 
 	reqBody := chatRequest{
@@ -300,8 +301,14 @@ func (c *Client) sendChatMessage(ctx context.Context, conversationID, messageTex
 		return "", fmt.Errorf(i18n.T("copilot_error_chat_request"), resp.Status, string(body))
 	}
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	ai.DumpRawResponse(opts, body)
+
 	var result conversationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return "", err
 	}
 
@@ -15,6 +15,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/geminicommon"
 	"google.golang.org/genai"
 )
@@ -85,6 +86,12 @@ func (o *Client) ListModels(_ context.Context) (ret []string, err error) {
 	return
 }
 
+// ModelCapabilities reports that every Gemini model streams and supports
+// Google Search grounding.
+func (o *Client) ModelCapabilities(string) ai.ModelCapabilities {
+	return ai.ModelCapabilities{Streaming: true, Search: true}
+}
+
 func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
 	// Check if this is a TTS model request
 	if o.isTTSModel(opts.Model) {
@@ -119,6 +126,7 @@ func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	if err != nil {
 		return "", err
 	}
+	ai.DumpRawResponseObject(opts, response)
 
 	// Extract text from response
 	ret = geminicommon.ExtractTextWithCitations(response)
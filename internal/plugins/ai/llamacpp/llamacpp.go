@@ -0,0 +1,344 @@
+package llamacpp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+const defaultBaseUrl = "http://localhost:8080"
+
+// NewClient creates a new llama.cpp server client.
+func NewClient() (ret *Client) {
+	ret = &Client{timeout: ai.DefaultTimeout}
+	ret.PluginBase = plugins.NewVendorPluginBase("LlamaCpp", ret.configure)
+	ret.ApiUrl = ret.AddSetupQuestionCustom("URL", true,
+		fmt.Sprintf(i18n.T("llamacpp_url_question"), ret.Name, defaultBaseUrl))
+	ret.UseChatCompletions = ret.AddSetupQuestionCustomBool("Use Chat Completions", false,
+		i18n.T("llamacpp_use_chat_completions_question"))
+	return
+}
+
+// Client talks to a llama.cpp server, either through its native /completion
+// endpoint or through its OpenAI-style /v1/chat/completions endpoint.
+type Client struct {
+	*plugins.PluginBase
+	ApiUrl             *plugins.SetupQuestion
+	UseChatCompletions *plugins.SetupQuestion
+	HttpClient         *http.Client
+	timeout            time.Duration
+}
+
+func (c *Client) configure() error {
+	c.HttpClient = &http.Client{Transport: &ai.ResponseTimeoutTransport{Timeout: c.timeout}}
+	return nil
+}
+
+// SetTimeout bounds how long each llama.cpp request waits for the response
+// to start. A stream that has started is not cut off.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	if c.HttpClient != nil {
+		ai.SetResponseTimeout(c.HttpClient, timeout)
+	}
+}
+
+func (c *Client) useChatCompletions() bool {
+	return plugins.ParseBoolElseFalse(c.UseChatCompletions.Value)
+}
+
+func (c *Client) url(path string) string {
+	return strings.TrimSuffix(c.ApiUrl.Value, "/") + path
+}
+
+// ListModels returns the model loaded by the server.
+func (c *Client) ListModels(ctx context.Context) (ret []string, err error) {
+	var resp *http.Response
+	if resp, err = c.do(ctx, http.MethodGet, "/v1/models", nil); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		err = fmt.Errorf(i18n.T("llamacpp_failed_decode_response"), err)
+		return
+	}
+	for _, model := range result.Data {
+		ret = append(ret, model.ID)
+	}
+	return
+}
+
+func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	var resp *http.Response
+	if resp, err = c.sendRequest(ctx, msgs, opts, false); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respBody []byte
+	if respBody, err = io.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf(i18n.T("llamacpp_error_reading_response"), err)
+		return
+	}
+	ai.DumpRawResponse(opts, respBody)
+
+	if c.useChatCompletions() {
+		var result chatCompletionResponse
+		if err = json.Unmarshal(respBody, &result); err != nil {
+			err = fmt.Errorf(i18n.T("llamacpp_failed_decode_response"), err)
+			return
+		}
+		if len(result.Choices) == 0 {
+			err = errors.New(i18n.T("llamacpp_invalid_response_missing_choices"))
+			return
+		}
+		ret = result.Choices[0].Message.Content
+		return
+	}
+
+	var result completionResponse
+	if err = json.Unmarshal(respBody, &result); err != nil {
+		err = fmt.Errorf(i18n.T("llamacpp_failed_decode_response"), err)
+		return
+	}
+	ret = result.Content
+	return
+}
+
+func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
+	defer close(channel)
+
+	var resp *http.Response
+	if resp, err = c.sendRequest(ctx, msgs, opts, true); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		var line []byte
+		if line, err = reader.ReadBytes('\n'); err != nil {
+			if err == io.EOF {
+				err = nil
+				return
+			}
+			err = fmt.Errorf(i18n.T("llamacpp_error_reading_stream"), err)
+			return
+		}
+
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			return
+		}
+
+		if c.useChatCompletions() {
+			var chunk chatCompletionChunk
+			if json.Unmarshal(data, &chunk) != nil {
+				continue
+			}
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				channel <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: chunk.Choices[0].Delta.Content}
+			}
+			if chunk.Usage != nil {
+				channel <- domain.StreamUpdate{Type: domain.StreamTypeUsage, Usage: &domain.UsageMetadata{
+					InputTokens:  chunk.Usage.PromptTokens,
+					OutputTokens: chunk.Usage.CompletionTokens,
+					TotalTokens:  chunk.Usage.TotalTokens,
+				}}
+			}
+			continue
+		}
+
+		var chunk completionResponse
+		if json.Unmarshal(data, &chunk) != nil {
+			continue
+		}
+		if chunk.Content != "" {
+			channel <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: chunk.Content}
+		}
+		if chunk.Stop {
+			channel <- domain.StreamUpdate{Type: domain.StreamTypeUsage, Usage: &domain.UsageMetadata{
+				InputTokens:  chunk.TokensEvaluated,
+				OutputTokens: chunk.TokensPredicted,
+				TotalTokens:  chunk.TokensEvaluated + chunk.TokensPredicted,
+			}}
+			return
+		}
+	}
+}
+
+// sendRequest posts the chat to the configured endpoint. In native mode the
+// messages are first rendered with the model's chat template via
+// /apply-template, because /completion only accepts a plain prompt.
+func (c *Client) sendRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, stream bool) (resp *http.Response, err error) {
+	messages := convertMessages(msgs)
+
+	if c.useChatCompletions() {
+		payload := map[string]any{
+			"model":    opts.Model,
+			"messages": messages,
+			"stream":   stream,
+		}
+		if stream {
+			payload["stream_options"] = map[string]any{"include_usage": true}
+		}
+		addSamplingOptions(payload, opts, "max_tokens")
+		return c.do(ctx, http.MethodPost, "/v1/chat/completions", payload)
+	}
+
+	var prompt string
+	if prompt, err = c.applyTemplate(ctx, messages); err != nil {
+		return
+	}
+	payload := map[string]any{
+		"prompt": prompt,
+		"stream": stream,
+	}
+	addSamplingOptions(payload, opts, "n_predict")
+	return c.do(ctx, http.MethodPost, "/completion", payload)
+}
+
+func (c *Client) applyTemplate(ctx context.Context, messages []message) (ret string, err error) {
+	var resp *http.Response
+	if resp, err = c.do(ctx, http.MethodPost, "/apply-template", map[string]any{"messages": messages}); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Prompt string `json:"prompt"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		err = fmt.Errorf(i18n.T("llamacpp_failed_decode_response"), err)
+		return
+	}
+	ret = result.Prompt
+	return
+}
+
+// do sends a JSON request and returns the response when the status is 200 OK.
+func (c *Client) do(ctx context.Context, method, path string, payload any) (resp *http.Response, err error) {
+	var body io.Reader
+	if payload != nil {
+		var jsonPayload []byte
+		if jsonPayload, err = json.Marshal(payload); err != nil {
+			err = fmt.Errorf(i18n.T("llamacpp_failed_marshal_payload"), err)
+			return
+		}
+		body = bytes.NewReader(jsonPayload)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, c.url(path), body); err != nil {
+		err = fmt.Errorf(i18n.T("llamacpp_failed_create_request"), err)
+		return
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if resp, err = c.HttpClient.Do(req); err != nil {
+		err = fmt.Errorf(i18n.T("llamacpp_failed_send_request"), err)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		err = fmt.Errorf(i18n.T("llamacpp_unexpected_status_code"), resp.StatusCode, strings.TrimSpace(string(errBody)))
+		resp = nil
+	}
+	return
+}
+
+func addSamplingOptions(payload map[string]any, opts *domain.ChatOptions, maxTokensKey string) {
+	if opts.Raw {
+		return
+	}
+	payload["temperature"] = opts.Temperature
+	if opts.TopP != 0 {
+		payload["top_p"] = opts.TopP
+	}
+	if opts.PresencePenalty != 0 {
+		payload["presence_penalty"] = opts.PresencePenalty
+	}
+	if opts.FrequencyPenalty != 0 {
+		payload["frequency_penalty"] = opts.FrequencyPenalty
+	}
+	if opts.Seed != nil {
+		payload["seed"] = *opts.Seed
+	}
+	if opts.MaxTokens != 0 {
+		payload[maxTokensKey] = opts.MaxTokens
+	}
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// convertMessages flattens messages to plain text, which is all the llama.cpp
+// chat template accepts.
+func convertMessages(msgs []*chat.ChatCompletionMessage) (ret []message) {
+	for _, msg := range msgs {
+		content := msg.Content
+		if content == "" && len(msg.MultiContent) > 0 {
+			var parts []string
+			for _, part := range msg.MultiContent {
+				if part.Type == chat.ChatMessagePartTypeText {
+					parts = append(parts, part.Text)
+				}
+			}
+			content = strings.Join(parts, "\n")
+		}
+		ret = append(ret, message{Role: msg.Role, Content: content})
+	}
+	return
+}
+
+type completionResponse struct {
+	Content         string `json:"content"`
+	Stop            bool   `json:"stop"`
+	TokensPredicted int    `json:"tokens_predicted"`
+	TokensEvaluated int    `json:"tokens_evaluated"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message message `json:"message"`
+	} `json:"choices"`
+}
+
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta message `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
@@ -0,0 +1,229 @@
+package llamacpp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(server *httptest.Server, useChatCompletions bool) *Client {
+	client := NewClient()
+	client.ApiUrl.Value = server.URL
+	client.UseChatCompletions.Value = "false"
+	if useChatCompletions {
+		client.UseChatCompletions.Value = "true"
+	}
+	client.HttpClient = server.Client()
+	return client
+}
+
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	return body
+}
+
+func collectStream(t *testing.T, client *Client, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (content string, usage *domain.UsageMetadata) {
+	channel := make(chan domain.StreamUpdate)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.SendStream(context.Background(), msgs, opts, channel)
+	}()
+
+	var builder strings.Builder
+	for update := range channel {
+		switch update.Type {
+		case domain.StreamTypeContent:
+			builder.WriteString(update.Content)
+		case domain.StreamTypeUsage:
+			usage = update.Usage
+		}
+	}
+	require.NoError(t, <-errCh)
+	return builder.String(), usage
+}
+
+func TestListModels(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/models", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"id":"qwen2.5-7b-instruct"}]}`))
+	}))
+	defer server.Close()
+
+	models, err := newTestClient(server, false).ListModels(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"qwen2.5-7b-instruct"}, models)
+}
+
+func TestNativeCompletion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apply-template":
+			body := decodeBody(t, r)
+			messages := body["messages"].([]any)
+			require.Len(t, messages, 2)
+			_, _ = w.Write([]byte(`{"prompt":"<system>be brief</system><user>hello</user>"}`))
+		case "/completion":
+			body := decodeBody(t, r)
+			require.Equal(t, "<system>be brief</system><user>hello</user>", body["prompt"])
+			require.Equal(t, false, body["stream"])
+			require.Equal(t, 0.5, body["temperature"])
+			require.Equal(t, float64(64), body["n_predict"])
+			_, _ = w.Write([]byte(`{"content":"hi there","stop":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "be brief"},
+		{Role: chat.ChatMessageRoleUser, Content: "hello"},
+	}
+	opts := &domain.ChatOptions{Temperature: 0.5, MaxTokens: 64}
+
+	result, err := newTestClient(server, false).Send(context.Background(), msgs, opts)
+	require.NoError(t, err)
+	require.Equal(t, "hi there", result)
+}
+
+func TestNativeCompletionStream(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apply-template":
+			_, _ = w.Write([]byte(`{"prompt":"hello"}`))
+		case "/completion":
+			require.Equal(t, true, decodeBody(t, r)["stream"])
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: {\"content\":\"hi\",\"stop\":false}\n\n" +
+				"data: {\"content\":\" there\",\"stop\":false}\n\n" +
+				"data: {\"content\":\"\",\"stop\":true,\"tokens_predicted\":2,\"tokens_evaluated\":5}\n\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	content, usage := collectStream(t, newTestClient(server, false), msgs, &domain.ChatOptions{})
+	require.Equal(t, "hi there", content)
+	require.Equal(t, &domain.UsageMetadata{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}, usage)
+}
+
+func TestChatCompletions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		body := decodeBody(t, r)
+		require.Equal(t, "local", body["model"])
+		require.Equal(t, float64(64), body["max_tokens"])
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi there"}}]}`))
+	}))
+	defer server.Close()
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	opts := &domain.ChatOptions{Model: "local", MaxTokens: 64}
+
+	result, err := newTestClient(server, true).Send(context.Background(), msgs, opts)
+	require.NoError(t, err)
+	require.Equal(t, "hi there", result)
+}
+
+func TestSendDumpsRawResponse(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"content":"raw body","stop":true}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dumpFile := filepath.Join(t.TempDir(), "raw.jsonl")
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	result, err := newTestClient(server, false).Send(context.Background(), msgs, &domain.ChatOptions{Model: "local", DumpRawFile: dumpFile})
+	require.NoError(t, err)
+	require.Equal(t, "raw body", result)
+
+	data, err := os.ReadFile(dumpFile)
+	require.NoError(t, err)
+	require.Equal(t, body+"\n", string(data))
+}
+
+func TestChatCompletionsStream(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		require.Equal(t, true, decodeBody(t, r)["stream"])
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\" there\"}}]}\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2,\"total_tokens\":7}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	content, usage := collectStream(t, newTestClient(server, true), msgs, &domain.ChatOptions{Model: "local"})
+	require.Equal(t, "hi there", content)
+	require.Equal(t, &domain.UsageMetadata{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}, usage)
+}
+
+func TestUnexpectedStatusCode(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	_, err := newTestClient(server, true).Send(context.Background(), msgs, &domain.ChatOptions{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "503")
+	require.Contains(t, err.Error(), "model not loaded")
+}
+
+func TestTimeoutBoundsWaitForResponse(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newTestClient(server, false)
+	require.NoError(t, client.configure())
+	client.SetTimeout(50 * time.Millisecond)
+
+	_, err := client.ListModels(context.Background())
+	var timeoutErr *ai.ResponseTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+}
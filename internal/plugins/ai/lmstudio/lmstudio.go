@@ -10,12 +10,14 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 )
 
 // NewClient creates a new LM Studio client with default configuration.
@@ -25,7 +27,7 @@ func NewClient() (ret *Client) {
 
 // NewClientCompatible creates a new LM Studio client with custom configuration.
 func NewClientCompatible(vendorName string, defaultBaseUrl string, configureCustom func() error) (ret *Client) {
-	ret = &Client{}
+	ret = &Client{timeout: ai.DefaultTimeout}
 
 	if configureCustom == nil {
 		configureCustom = ret.configure
@@ -43,14 +45,24 @@ type Client struct {
 	ApiUrl     *plugins.SetupQuestion
 	ApiKey     *plugins.SetupQuestion
 	HttpClient *http.Client
+	timeout    time.Duration
 }
 
 // configure sets up the HTTP client.
 func (c *Client) configure() error {
-	c.HttpClient = &http.Client{}
+	c.HttpClient = &http.Client{Transport: &ai.ResponseTimeoutTransport{Timeout: c.timeout}}
 	return nil
 }
 
+// SetTimeout bounds how long each LM Studio request waits for the response
+// to start. A stream that has started is not cut off.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	if c.HttpClient != nil {
+		ai.SetResponseTimeout(c.HttpClient, timeout)
+	}
+}
+
 // ListModels returns a list of available models.
 func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	url := fmt.Sprintf("%s/models", c.ApiUrl.Value)
@@ -100,6 +112,7 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 			"include_usage": true,
 		},
 	}
+	addSamplingOptions(payload, opts)
 
 	var jsonPayload []byte
 	if jsonPayload, err = json.Marshal(payload); err != nil {
@@ -208,6 +221,7 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 		"model":    opts.Model,
 		// Add other options from opts if supported by LM Studio
 	}
+	addSamplingOptions(payload, opts)
 
 	var jsonPayload []byte
 	if jsonPayload, err = json.Marshal(payload); err != nil {
@@ -236,8 +250,15 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 		return
 	}
 
+	var respBody []byte
+	if respBody, err = io.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf(i18n.T("lmstudio_error_reading_response"), err)
+		return
+	}
+	ai.DumpRawResponse(opts, respBody)
+
 	var result map[string]any
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = json.Unmarshal(respBody, &result); err != nil {
 		err = fmt.Errorf(i18n.T("lmstudio_failed_decode_response"), err)
 		return
 	}
@@ -271,6 +292,7 @@ func (c *Client) Complete(ctx context.Context, prompt string, opts *domain.ChatO
 		"model":  opts.Model,
 		// Add other options from opts if supported by LM Studio
 	}
+	addSamplingOptions(payload, opts)
 
 	var jsonPayload []byte
 	if jsonPayload, err = json.Marshal(payload); err != nil {
@@ -376,6 +398,17 @@ func (c *Client) GetEmbeddings(ctx context.Context, input string, opts *domain.C
 	return
 }
 
+// addSamplingOptions sets the OpenAI-compatible "stop" and "seed" fields when
+// they were requested.
+func addSamplingOptions(payload map[string]any, opts *domain.ChatOptions) {
+	if len(opts.StopSequences) > 0 {
+		payload["stop"] = opts.StopSequences
+	}
+	if opts.Seed != nil {
+		payload["seed"] = *opts.Seed
+	}
+}
+
 func (c *Client) addAuthorizationHeader(req *http.Request) {
 	if c.ApiKey == nil {
 		return
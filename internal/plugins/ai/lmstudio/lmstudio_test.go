@@ -2,12 +2,18 @@ package lmstudio
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/stretchr/testify/require"
 )
 
@@ -90,3 +96,108 @@ func TestListModelsDoesNotSendBearerForWhitespaceOnlyKey(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{"model-1"}, models)
 }
+
+func TestSendIncludesSamplingOptions(t *testing.T) {
+	t.Parallel()
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ApiUrl.Value = server.URL
+	client.HttpClient = server.Client()
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	seed := 7
+	opts := &domain.ChatOptions{Model: "test-model", StopSequences: []string{"END"}, Seed: &seed}
+
+	_, err := client.Send(context.Background(), msgs, opts)
+	require.NoError(t, err)
+	require.Equal(t, []any{"END"}, payload["stop"])
+	require.Equal(t, float64(7), payload["seed"])
+}
+
+func TestSendDumpsRawResponse(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"choices":[{"message":{"content":"ok"}}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ApiUrl.Value = server.URL
+	client.HttpClient = server.Client()
+
+	dumpFile := filepath.Join(t.TempDir(), "raw.jsonl")
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hello"}}
+	result, err := client.Send(context.Background(), msgs, &domain.ChatOptions{Model: "test-model", DumpRawFile: dumpFile})
+	require.NoError(t, err)
+	require.Equal(t, "ok", result)
+
+	data, err := os.ReadFile(dumpFile)
+	require.NoError(t, err)
+	require.Equal(t, body+"\n", string(data))
+}
+
+func TestTimeoutBoundsWaitForResponse(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient()
+	client.ApiUrl.Value = server.URL
+	require.NoError(t, client.configure())
+	client.SetTimeout(50 * time.Millisecond)
+
+	_, err := client.ListModels(context.Background())
+	var timeoutErr *ai.ResponseTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+}
+
+func TestTimeoutDoesNotCutOffStartedStream(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"choices":[{"delta":{"content":"hel"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`data: {"choices":[{"delta":{"content":"lo"}}]}` + "\n\n" + "data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ApiUrl.Value = server.URL
+	require.NoError(t, client.configure())
+	client.SetTimeout(50 * time.Millisecond)
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+	channel := make(chan domain.StreamUpdate)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "model-1"}, channel)
+	}()
+
+	var content strings.Builder
+	for update := range channel {
+		content.WriteString(update.Content)
+	}
+	require.NoError(t, <-errCh)
+	require.Equal(t, "hello", content.String())
+}
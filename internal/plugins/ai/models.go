@@ -2,8 +2,10 @@ package ai
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/util"
@@ -82,3 +84,67 @@ func (o *VendorsModels) PrintWithVendor(shellCompleteList bool, defaultVendor, d
 		}
 	}
 }
+
+// ModelInfo is a model annotated with its vendor and capabilities.
+type ModelInfo struct {
+	Vendor string
+	Model  string
+	ModelCapabilities
+}
+
+// ModelInfos annotates every model with the capabilities its vendor reports,
+// sorted case-insensitively by vendor and then model. Models whose vendor is
+// not registered with vendors are reported with default capabilities.
+func (o *VendorsModels) ModelInfos(vendors *VendorsManager) (ret []ModelInfo) {
+	for _, groupItems := range o.GroupsItems {
+		vendor := vendors.FindByName(groupItems.Group)
+		for _, item := range groupItems.Items {
+			capabilities := ModelCapabilities{Streaming: true}
+			if vendor != nil {
+				capabilities = CapabilitiesOf(vendor, item)
+			}
+			ret = append(ret, ModelInfo{Vendor: groupItems.Group, Model: item, ModelCapabilities: capabilities})
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if vi, vj := strings.ToLower(ret[i].Vendor), strings.ToLower(ret[j].Vendor); vi != vj {
+			return vi < vj
+		}
+		return strings.ToLower(ret[i].Model) < strings.ToLower(ret[j].Model)
+	})
+	return
+}
+
+// PrintModelInfos writes infos to w grouped by vendor, with the streaming and
+// search columns aligned across all groups.
+func PrintModelInfos(w io.Writer, infos []ModelInfo) {
+	yes, no := i18n.T("models_capability_yes"), i18n.T("models_capability_no")
+	modelHeader, streamingHeader := i18n.T("models_column_model"), i18n.T("models_column_streaming")
+
+	modelWidth := utf8.RuneCountInString(modelHeader)
+	for _, info := range infos {
+		modelWidth = max(modelWidth, utf8.RuneCountInString(info.Model))
+	}
+	streamingWidth := max(utf8.RuneCountInString(streamingHeader), utf8.RuneCountInString(yes), utf8.RuneCountInString(no))
+
+	row := func(model, streaming, search string) {
+		fmt.Fprintf(w, "  %-*s  %-*s  %s\n", modelWidth, model, streamingWidth, streaming, search)
+	}
+	yesNo := func(supported bool) string {
+		if supported {
+			return yes
+		}
+		return no
+	}
+
+	for i, info := range infos {
+		if i == 0 || info.Vendor != infos[i-1].Vendor {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "%s:\n", info.Vendor)
+			row(modelHeader, streamingHeader, i18n.T("models_column_search"))
+		}
+		row(info.Model, yesNo(info.Streaming), yesNo(info.Search))
+	}
+}
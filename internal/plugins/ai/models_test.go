@@ -102,3 +102,53 @@ func TestFindModelNameCaseInsensitive(t *testing.T) {
 		})
 	}
 }
+
+type capabilityVendor struct {
+	stubVendor
+	searchModels map[string]bool
+}
+
+func (v *capabilityVendor) ModelCapabilities(model string) ModelCapabilities {
+	return ModelCapabilities{Streaming: true, Search: v.searchModels[model]}
+}
+
+func TestModelInfos(t *testing.T) {
+	manager := NewVendorsManager()
+	manager.AddVendors(
+		&capabilityVendor{stubVendor: stubVendor{name: "Searcher"}, searchModels: map[string]bool{"web-model": true}},
+		&stubVendor{name: "Plain"},
+	)
+
+	models := NewVendorsModels()
+	models.AddGroupItems("Searcher", "web-model", "offline-model")
+	models.AddGroupItems("Plain", "basic")
+
+	want := []ModelInfo{
+		{Vendor: "Plain", Model: "basic", ModelCapabilities: ModelCapabilities{Streaming: true}},
+		{Vendor: "Searcher", Model: "offline-model", ModelCapabilities: ModelCapabilities{Streaming: true}},
+		{Vendor: "Searcher", Model: "web-model", ModelCapabilities: ModelCapabilities{Streaming: true, Search: true}},
+	}
+	got := models.ModelInfos(manager)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d model infos, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("model info %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	var out strings.Builder
+	PrintModelInfos(&out, got)
+	wantOut := "Plain:\n" +
+		"  Model          Streaming  Search\n" +
+		"  basic          yes        no\n" +
+		"\n" +
+		"Searcher:\n" +
+		"  Model          Streaming  Search\n" +
+		"  offline-model  yes        no\n" +
+		"  web-model      yes        yes\n"
+	if out.String() != wantOut {
+		t.Errorf("unexpected table:\n%s\nwant:\n%s", out.String(), wantOut)
+	}
+}
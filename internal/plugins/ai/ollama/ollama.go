@@ -17,6 +17,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	ollamaapi "github.com/ollama/ollama/api"
 )
 
@@ -149,6 +150,7 @@ func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	req.Stream = &bf
 
 	respFunc := func(resp ollamaapi.ChatResponse) (streamErr error) {
+		ai.DumpRawResponseObject(opts, resp)
 		ret = resp.Message.Content
 		return
 	}
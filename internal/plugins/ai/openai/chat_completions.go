@@ -6,25 +6,35 @@ package openai
 
 import (
 	"context"
+	"math"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	openai "github.com/openai/openai-go"
 	"github.com/openai/openai-go/shared"
 )
 
 // sendChatCompletions sends a request using the Chat Completions API
-func (o *Client) sendChatCompletions(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+func (o *Client) sendChatCompletions(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, usage *domain.UsageMetadata, err error) {
 	req := o.buildChatCompletionParams(msgs, opts)
 
 	var resp *openai.ChatCompletion
-	if resp, err = o.ApiClient.Chat.Completions.New(ctx, req); err != nil {
+	if resp, err = o.ApiClient.Chat.Completions.New(ctx, req, o.requestOptions()...); err != nil {
 		return
 	}
+	ai.DumpRawResponseObject(opts, resp)
 	if len(resp.Choices) > 0 {
 		ret = resp.Choices[0].Message.Content
 	}
+	if resp.Usage.TotalTokens > 0 {
+		usage = &domain.UsageMetadata{
+			InputTokens:  int(resp.Usage.PromptTokens),
+			OutputTokens: int(resp.Usage.CompletionTokens),
+			TotalTokens:  int(resp.Usage.TotalTokens),
+		}
+	}
 	return
 }
 
@@ -39,7 +49,7 @@ func (o *Client) sendStreamChatCompletions(
 	req.StreamOptions = openai.ChatCompletionStreamOptionsParam{
 		IncludeUsage: openai.Bool(true),
 	}
-	stream := o.ApiClient.Chat.Completions.NewStreaming(ctx, req)
+	stream := o.ApiClient.Chat.Completions.NewStreaming(ctx, req, o.requestOptions()...)
 	for stream.Next() {
 		chunk := stream.Current()
 		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
@@ -74,12 +84,16 @@ func (o *Client) buildChatCompletionParams(
 	inputMsgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions,
 ) (ret openai.ChatCompletionNewParams) {
 
+	developerRole := o.useDeveloperRole(opts)
 	messages := make([]openai.ChatCompletionMessageParamUnion, len(inputMsgs))
 	for i, msgPtr := range inputMsgs {
 		msg := *msgPtr
 		if strings.Contains(opts.Model, "deepseek") && len(inputMsgs) == 1 && msg.Role == chat.ChatMessageRoleSystem {
 			msg.Role = chat.ChatMessageRoleUser
 		}
+		if developerRole && msg.Role == chat.ChatMessageRoleSystem {
+			msg.Role = chat.ChatMessageRoleDeveloper
+		}
 		messages[i] = o.convertChatMessage(msg)
 	}
 
@@ -89,8 +103,12 @@ func (o *Client) buildChatCompletionParams(
 	}
 
 	if !opts.Raw {
-		ret.Temperature = openai.Float(opts.Temperature)
-		if opts.TopP != 0 {
+		samplingParams, _ := domain.ModelSamplingParams(opts.Model)
+		sendTemperature, sendTopP := samplingParams.Select(opts.TopP, opts.TopP != 0)
+		if sendTemperature {
+			ret.Temperature = openai.Float(opts.Temperature)
+		}
+		if sendTopP {
 			ret.TopP = openai.Float(opts.TopP)
 		}
 		if opts.MaxTokens != 0 {
@@ -102,8 +120,17 @@ func (o *Client) buildChatCompletionParams(
 		if opts.FrequencyPenalty != 0 {
 			ret.FrequencyPenalty = openai.Float(opts.FrequencyPenalty)
 		}
-		if opts.Seed != 0 {
-			ret.Seed = openai.Int(int64(opts.Seed))
+		if opts.Seed != nil {
+			ret.Seed = openai.Int(int64(*opts.Seed))
+		}
+	}
+	if len(opts.StopSequences) > 0 {
+		ret.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: opts.StopSequences}
+	}
+	if len(opts.LogitBias) > 0 {
+		ret.LogitBias = make(map[string]int64, len(opts.LogitBias))
+		for token, bias := range opts.LogitBias {
+			ret.LogitBias[token] = int64(math.Round(bias))
 		}
 	}
 	if eff, ok := parseReasoningEffort(opts.Thinking); ok {
@@ -119,6 +146,8 @@ func (o *Client) convertChatMessage(msg chat.ChatCompletionMessage) openai.ChatC
 	switch result.Role {
 	case chat.ChatMessageRoleSystem:
 		return openai.SystemMessage(result.Content)
+	case chat.ChatMessageRoleDeveloper:
+		return openai.DeveloperMessage(result.Content)
 	case chat.ChatMessageRoleUser:
 		// Handle multi-content messages (text + images)
 		if result.HasMultiContent {
@@ -1,6 +1,11 @@
 package openai
 
-import "github.com/danielmiessler/fabric/internal/chat"
+import (
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
 
 // MessageConversionResult holds the common conversion result
 type MessageConversionResult struct {
@@ -19,3 +24,33 @@ func convertMessageCommon(msg chat.ChatCompletionMessage) MessageConversionResul
 		HasMultiContent: len(msg.MultiContent) > 0,
 	}
 }
+
+// developerRoleModelPrefixes lists OpenAI models that prefer instructions in a
+// developer message rather than a system message.
+var developerRoleModelPrefixes = []string{
+	"gpt-5",
+	"o1-pro",
+	"o3",
+	"o4",
+	"codex",
+}
+
+// useDeveloperRole reports whether system content should be sent with the
+// developer role, either because the caller asked for it or because the model
+// is known to prefer it. Only vendors that accept the role use it; other
+// OpenAI-compatible providers keep the system role.
+func (o *Client) useDeveloperRole(opts *domain.ChatOptions) bool {
+	if !o.developerRoleSupported {
+		return false
+	}
+	if opts.UseDeveloperRole {
+		return true
+	}
+	model := strings.ToLower(opts.Model)
+	for _, prefix := range developerRoleModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
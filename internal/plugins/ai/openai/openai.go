@@ -14,6 +14,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	openai "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/packages/pagination"
@@ -23,7 +24,9 @@ import (
 )
 
 func NewClient() (ret *Client) {
-	return NewClientCompatibleWithResponses("OpenAI", "https://api.openai.com/v1", true, nil)
+	ret = NewClientCompatibleWithResponses("OpenAI", "https://api.openai.com/v1", true, nil)
+	ret.developerRoleSupported = true
+	return
 }
 
 func NewClientCompatible(vendorName string, defaultBaseUrl string, configureCustom func() error) (ret *Client) {
@@ -48,7 +51,7 @@ func NewClientCompatibleWithResponses(vendorName string, defaultBaseUrl string,
 }
 
 func NewClientCompatibleNoSetupQuestions(vendorName string, configureCustom func() error) (ret *Client) {
-	ret = &Client{}
+	ret = &Client{requestTimeout: ai.DefaultTimeout}
 
 	if configureCustom == nil {
 		configureCustom = ret.configure
@@ -75,6 +78,28 @@ type Client struct {
 	// entry alongside the web search tool when Search is enabled.
 	// This is an xAI-specific live search grounding tool.
 	enableXSearch bool
+	// developerRoleSupported, when true, lets system content go out with
+	// the developer role. Only OpenAI and Azure OpenAI accept that role.
+	developerRoleSupported bool
+	// requestTimeout bounds how long each API call waits for the response
+	// to start; zero leaves it unbounded.
+	requestTimeout time.Duration
+}
+
+// SetTimeout bounds how long each API request waits for the response to
+// start. A stream that has started is not cut off.
+func (o *Client) SetTimeout(timeout time.Duration) {
+	o.requestTimeout = timeout
+}
+
+// requestOptions returns the per-request options shared by every API call.
+func (o *Client) requestOptions() (ret []option.RequestOption) {
+	if timeout := o.requestTimeout; timeout > 0 {
+		ret = append(ret, option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			return ai.DoWithResponseTimeout(req, timeout, next)
+		}))
+	}
+	return
 }
 
 // SetResponsesAPIEnabled configures whether to use the Responses API
@@ -97,6 +122,12 @@ func (o *Client) SetEnableXSearch(enabled bool) {
 	o.enableXSearch = enabled
 }
 
+// SetDeveloperRoleSupported records whether the vendor accepts developer-role
+// messages. Providers that only know the system role should leave this false.
+func (o *Client) SetDeveloperRoleSupported(supported bool) {
+	o.developerRoleSupported = supported
+}
+
 // checkImageGenerationCompatibility warns if the model doesn't support image generation
 func checkImageGenerationCompatibility(model string) {
 	if !supportsImageGeneration(model) {
@@ -122,7 +153,7 @@ func (o *Client) configure() (ret error) {
 
 func (o *Client) ListModels(ctx context.Context) (ret []string, err error) {
 	var page *pagination.Page[openai.Model]
-	if page, err = o.ApiClient.Models.List(ctx); err == nil {
+	if page, err = o.ApiClient.Models.List(ctx, o.requestOptions()...); err == nil {
 		for _, mod := range page.Data {
 			ret = append(ret, mod.ID)
 		}
@@ -141,7 +172,7 @@ func (o *Client) SendStream(
 	ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate,
 ) (err error) {
 	// Use Responses API for OpenAI, Chat Completions API for other providers
-	if o.supportsResponsesAPI() {
+	if o.usesResponsesAPI(opts.Model) {
 		return o.sendStreamResponses(ctx, msgs, opts, channel)
 	}
 	return o.sendStreamChatCompletions(ctx, msgs, opts, channel)
@@ -153,7 +184,7 @@ func (o *Client) sendStreamResponses(
 	defer close(channel)
 
 	req := o.buildResponseParams(msgs, opts)
-	stream := o.ApiClient.Responses.NewStreaming(ctx, req)
+	stream := o.ApiClient.Responses.NewStreaming(ctx, req, o.requestOptions()...)
 	for stream.Next() {
 		event := stream.Current()
 		switch event.Type {
@@ -180,14 +211,21 @@ func (o *Client) sendStreamResponses(
 }
 
 func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	ret, _, err = o.SendWithUsage(ctx, msgs, opts)
+	return
+}
+
+// SendWithUsage sends a non-streaming request and returns the response text
+// together with the token usage reported by the API.
+func (o *Client) SendWithUsage(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, usage *domain.UsageMetadata, err error) {
 	// Use Responses API for OpenAI, Chat Completions API for other providers
-	if o.supportsResponsesAPI() {
+	if o.usesResponsesAPI(opts.Model) {
 		return o.sendResponses(ctx, msgs, opts)
 	}
 	return o.sendChatCompletions(ctx, msgs, opts)
 }
 
-func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, usage *domain.UsageMetadata, err error) {
 	// Warn if model doesn't support image generation when image file is specified
 	if opts.ImageFile != "" {
 		checkImageGenerationCompatibility(opts.Model)
@@ -195,15 +233,16 @@ func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionM
 
 	// Validate model supports image generation if image file is specified
 	if opts.ImageFile != "" && !supportsImageGeneration(opts.Model) {
-		return "", fmt.Errorf("%s", fmt.Sprintf(i18n.T("openai_model_no_image_generation"), opts.Model, strings.Join(ImageGenerationSupportedModels, ", ")))
+		return "", nil, fmt.Errorf("%s", fmt.Sprintf(i18n.T("openai_model_no_image_generation"), opts.Model, strings.Join(ImageGenerationSupportedModels, ", ")))
 	}
 
 	req := o.buildResponseParams(msgs, opts)
 
 	var resp *responses.Response
-	if resp, err = o.ApiClient.Responses.New(ctx, req); err != nil {
+	if resp, err = o.ApiClient.Responses.New(ctx, req, o.requestOptions()...); err != nil {
 		return
 	}
+	ai.DumpRawResponseObject(opts, resp)
 
 	// Extract and save images if requested
 	if err = o.extractAndSaveImages(resp, opts); err != nil {
@@ -211,6 +250,13 @@ func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionM
 	}
 
 	ret = o.extractText(resp)
+	if resp.Usage.TotalTokens > 0 {
+		usage = &domain.UsageMetadata{
+			InputTokens:  int(resp.Usage.InputTokens),
+			OutputTokens: int(resp.Usage.OutputTokens),
+			TotalTokens:  int(resp.Usage.TotalTokens),
+		}
+	}
 	return
 }
 
@@ -219,6 +265,19 @@ func (o *Client) supportsResponsesAPI() bool {
 	return o.ImplementsResponses
 }
 
+// usesResponsesAPI determines whether a request for model goes through the
+// Responses API. The provider must support it and the model must not be one
+// that is only served via Chat Completions.
+func (o *Client) usesResponsesAPI(model string) bool {
+	return o.supportsResponsesAPI() && domain.OpenAIUsesResponsesAPI(model)
+}
+
+// ModelCapabilities reports web search support, which is only available
+// through the Responses API.
+func (o *Client) ModelCapabilities(model string) ai.ModelCapabilities {
+	return ai.ModelCapabilities{Streaming: true, Search: o.usesResponsesAPI(model)}
+}
+
 func (o *Client) NeedsRawMode(modelName string) bool {
 	openaiModelsPrefixes := []string{
 		"glm",
@@ -258,12 +317,16 @@ func (o *Client) buildResponseParams(
 	inputMsgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions,
 ) (ret responses.ResponseNewParams) {
 
+	developerRole := o.useDeveloperRole(opts)
 	items := make([]responses.ResponseInputItemUnionParam, len(inputMsgs))
 	for i, msgPtr := range inputMsgs {
 		msg := *msgPtr
 		if strings.Contains(opts.Model, "deepseek") && len(inputMsgs) == 1 && msg.Role == chat.ChatMessageRoleSystem {
 			msg.Role = chat.ChatMessageRoleUser
 		}
+		if developerRole && msg.Role == chat.ChatMessageRoleSystem {
+			msg.Role = chat.ChatMessageRoleDeveloper
+		}
 		items[i] = convertMessage(msg)
 	}
 
@@ -325,8 +388,12 @@ func (o *Client) buildResponseParams(
 	}
 
 	if !opts.Raw {
-		ret.Temperature = openai.Float(opts.Temperature)
-		if opts.TopP != 0 {
+		samplingParams, _ := domain.ModelSamplingParams(opts.Model)
+		sendTemperature, sendTopP := samplingParams.Select(opts.TopP, opts.TopP != 0)
+		if sendTemperature {
+			ret.Temperature = openai.Float(opts.Temperature)
+		}
+		if sendTopP {
 			ret.TopP = openai.Float(opts.TopP)
 		}
 		if opts.MaxTokens != 0 {
@@ -341,13 +408,20 @@ func (o *Client) buildResponseParams(
 		if opts.FrequencyPenalty != 0 {
 			extraFields["frequency_penalty"] = opts.FrequencyPenalty
 		}
-		if opts.Seed != 0 {
-			extraFields["seed"] = opts.Seed
+		if opts.Seed != nil {
+			extraFields["seed"] = *opts.Seed
 		}
 		if len(extraFields) > 0 {
 			ret.SetExtraFields(extraFields)
 		}
 	}
+	// The Responses API has no stop or logit_bias parameters
+	if len(opts.StopSequences) > 0 {
+		debuglog.Warn("%s\n", i18n.T("openai_responses_stop_unsupported"))
+	}
+	if len(opts.LogitBias) > 0 {
+		debuglog.Warn("%s\n", i18n.T("openai_responses_logit_bias_unsupported"))
+	}
 	return
 }
 
@@ -588,7 +588,7 @@ func TestSendResponses_WithWarningIntegration(t *testing.T) {
 			}
 
 			// Call sendResponses - this will trigger the warning and potentially error
-			_, err := client.sendResponses(context.TODO(), msgs, opts)
+			_, _, err := client.sendResponses(context.TODO(), msgs, opts)
 
 			// Close writer and read warning output
 			w.Close()
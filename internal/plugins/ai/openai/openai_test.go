@@ -1,12 +1,19 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/responses"
 	"github.com/openai/openai-go/shared"
 	"github.com/stretchr/testify/assert"
@@ -257,3 +264,250 @@ func TestCitationFormatting(t *testing.T) {
 	citationCount := strings.Count(result, "- [")
 	assert.Equal(t, 2, citationCount, "Expected 2 unique citations")
 }
+
+func TestUsesResponsesAPI(t *testing.T) {
+	t.Setenv(domain.OpenAIAPIOverridesEnv, "")
+
+	client := NewClient()
+	assert.True(t, client.usesResponsesAPI("o3-pro"), "reasoning model should use Responses API")
+	assert.False(t, client.usesResponsesAPI("gpt-3.5-turbo-instruct"), "legacy model should use Chat Completions")
+	assert.True(t, client.usesResponsesAPI("brand-new-model"), "unknown model should default to Responses API")
+
+	client.SetResponsesAPIEnabled(false)
+	assert.False(t, client.usesResponsesAPI("o3-pro"), "disabled Responses API should always use Chat Completions")
+}
+
+func TestModelCapabilities(t *testing.T) {
+	t.Setenv(domain.OpenAIAPIOverridesEnv, "")
+
+	client := NewClient()
+	assert.True(t, client.ModelCapabilities("gpt-4o").Search, "Responses API model should support search")
+	assert.False(t, client.ModelCapabilities("gpt-3.5-turbo-instruct").Search, "Chat Completions model should not support search")
+
+	client.SetResponsesAPIEnabled(false)
+	assert.False(t, client.ModelCapabilities("gpt-4o").Search, "search requires the Responses API")
+	assert.True(t, client.ModelCapabilities("gpt-4o").Streaming)
+}
+
+func TestDeveloperRole(t *testing.T) {
+	tests := []struct {
+		name          string
+		model         string
+		enabled       bool
+		wantDeveloper bool
+	}{
+		{name: "disabled for older model", model: "gpt-4o", enabled: false, wantDeveloper: false},
+		{name: "enabled explicitly", model: "gpt-4o", enabled: true, wantDeveloper: true},
+		{name: "automatic for known model", model: "gpt-5-mini", enabled: false, wantDeveloper: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := []*chat.ChatCompletionMessage{
+				{Role: chat.ChatMessageRoleSystem, Content: "pattern"},
+				{Role: chat.ChatMessageRoleUser, Content: "input"},
+			}
+			opts := &domain.ChatOptions{Model: tt.model, UseDeveloperRole: tt.enabled}
+			client := NewClient()
+
+			chatParams := client.buildChatCompletionParams(msgs, opts)
+			assert.Equal(t, tt.wantDeveloper, chatParams.Messages[0].OfDeveloper != nil)
+			assert.Equal(t, !tt.wantDeveloper, chatParams.Messages[0].OfSystem != nil)
+
+			responseParams := client.buildResponseParams(msgs, opts)
+			wantRole := responses.EasyInputMessageRoleSystem
+			if tt.wantDeveloper {
+				wantRole = responses.EasyInputMessageRoleDeveloper
+			}
+			assert.Equal(t, wantRole, responseParams.Input.OfInputItemList[0].OfMessage.Role)
+			assert.Equal(t, chat.ChatMessageRoleSystem, msgs[0].Role, "input messages should not be modified")
+		})
+	}
+}
+
+func TestDeveloperRoleCompatibleProvider(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "pattern"},
+		{Role: chat.ChatMessageRoleUser, Content: "input"},
+	}
+	opts := &domain.ChatOptions{Model: "gpt-5-mini", UseDeveloperRole: true}
+	client := NewClientCompatibleWithResponses("Compatible", "https://example.com/v1", true, nil)
+
+	chatParams := client.buildChatCompletionParams(msgs, opts)
+	assert.NotNil(t, chatParams.Messages[0].OfSystem, "compatible providers should keep the system role")
+	assert.Nil(t, chatParams.Messages[0].OfDeveloper)
+
+	responseParams := client.buildResponseParams(msgs, opts)
+	assert.Equal(t, responses.EasyInputMessageRoleSystem, responseParams.Input.OfInputItemList[0].OfMessage.Role)
+}
+
+func TestBuildParamsOmitUnsupportedSamplingParams(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "My msg"}}
+	opts := &domain.ChatOptions{Model: "o1-mini", Temperature: 0.8, TopP: 0.9}
+
+	client := NewClient()
+	request := client.buildResponseParams(msgs, opts)
+	assert.False(t, request.Temperature.Valid())
+	assert.False(t, request.TopP.Valid())
+
+	chatParams := client.buildChatCompletionParams(msgs, opts)
+	assert.False(t, chatParams.Temperature.Valid())
+	assert.False(t, chatParams.TopP.Valid())
+
+	opts.Model = "gpt-4o"
+	request = client.buildResponseParams(msgs, opts)
+	assert.Equal(t, openai.Float(opts.Temperature), request.Temperature)
+	assert.Equal(t, openai.Float(opts.TopP), request.TopP)
+}
+
+func TestBuildChatCompletionParamsStopSequences(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "My msg"}}
+	opts := &domain.ChatOptions{Model: "gpt-4o", StopSequences: []string{"END", "\n\n"}}
+
+	client := NewClient()
+	body, err := json.Marshal(client.buildChatCompletionParams(msgs, opts))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"stop":["END","\n\n"]`)
+
+	opts.StopSequences = nil
+	body, err = json.Marshal(client.buildChatCompletionParams(msgs, opts))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), `"stop"`)
+}
+
+func TestBuildChatCompletionParamsLogitBias(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "My msg"}}
+	opts := &domain.ChatOptions{Model: "gpt-4o", LogitBias: map[string]float64{"50256": -100, "1234": 5.6}}
+
+	client := NewClient()
+	chatParams := client.buildChatCompletionParams(msgs, opts)
+	assert.Equal(t, map[string]int64{"50256": -100, "1234": 6}, chatParams.LogitBias)
+
+	opts.LogitBias = nil
+	chatParams = client.buildChatCompletionParams(msgs, opts)
+	assert.Nil(t, chatParams.LogitBias)
+}
+
+func TestBuildParamsSeed(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "My msg"}}
+	opts := &domain.ChatOptions{Model: "gpt-4o"}
+
+	client := NewClient()
+	chatParams := client.buildChatCompletionParams(msgs, opts)
+	assert.False(t, chatParams.Seed.Valid())
+	body, err := json.Marshal(client.buildResponseParams(msgs, opts))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), `"seed"`)
+
+	// Zero is a valid seed once it has been set explicitly
+	seed := 0
+	opts.Seed = &seed
+	chatParams = client.buildChatCompletionParams(msgs, opts)
+	assert.Equal(t, openai.Int(0), chatParams.Seed)
+	body, err = json.Marshal(client.buildResponseParams(msgs, opts))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"seed":0`)
+}
+
+func TestSendWithUsage(t *testing.T) {
+	tests := []struct {
+		name                string
+		implementsResponses bool
+		path                string
+		body                string
+	}{
+		{
+			name: "chat completions",
+			path: "/chat/completions",
+			body: `{"choices":[{"message":{"role":"assistant","content":"hello"}}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`,
+		},
+		{
+			name:                "responses",
+			implementsResponses: true,
+			path:                "/responses",
+			body:                `{"output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}],"usage":{"input_tokens":12,"output_tokens":3,"total_tokens":15}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.path, r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				_, err := w.Write([]byte(tt.body))
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+
+			client := NewClient()
+			client.ApiKey.Value = "test-key"
+			client.ApiBaseURL.Value = srv.URL
+			client.ImplementsResponses = tt.implementsResponses
+			assert.NoError(t, client.configure())
+
+			msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+			ret, usage, err := client.SendWithUsage(context.Background(), msgs, &domain.ChatOptions{Model: "gpt-4o"})
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", ret)
+			assert.Equal(t, &domain.UsageMetadata{InputTokens: 12, OutputTokens: 3, TotalTokens: 15}, usage)
+		})
+	}
+}
+
+func newTimeoutTestClient(t *testing.T, srv *httptest.Server, timeout time.Duration) *Client {
+	client := NewClient()
+	client.ApiKey.Value = "test-key"
+	client.ApiBaseURL.Value = srv.URL
+	client.ImplementsResponses = false
+	assert.NoError(t, client.configure())
+	apiClient := openai.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(srv.URL), option.WithMaxRetries(0))
+	client.ApiClient = &apiClient
+	client.SetTimeout(timeout)
+	return client
+}
+
+func TestTimeoutBoundsWaitForResponse(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := newTimeoutTestClient(t, srv, 50*time.Millisecond)
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+	start := time.Now()
+	_, err := client.Send(context.Background(), msgs, &domain.ChatOptions{Model: "gpt-4o"})
+	var timeoutErr *ai.ResponseTimeoutError
+	assert.ErrorAs(t, err, &timeoutErr)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTimeoutDoesNotCutOffStartedStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"hel"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"lo"}}]}` + "\n\n" + "data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	client := newTimeoutTestClient(t, srv, 50*time.Millisecond)
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+	channel := make(chan domain.StreamUpdate)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "gpt-4o"}, channel)
+	}()
+
+	var content strings.Builder
+	for update := range channel {
+		content.WriteString(update.Content)
+	}
+	assert.NoError(t, <-errCh)
+	assert.Equal(t, "hello\n", content.String())
+}
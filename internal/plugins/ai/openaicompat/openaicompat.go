@@ -0,0 +1,61 @@
+// Package openaicompat provides a vendor for any endpoint that speaks the
+// OpenAI chat completions API, configured entirely through setup questions.
+package openaicompat
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
+)
+
+const vendorName = "OpenAI Compatible"
+
+// NewClient creates a vendor whose base URL, API key source and display name
+// are chosen during setup. Requests go through the OpenAI chat completions code.
+func NewClient() (ret *Client) {
+	ret = &Client{}
+	ret.Client = openai.NewClientCompatibleNoSetupQuestions(vendorName, nil)
+	openaiConfigure := ret.ConfigureCustom
+	ret.ConfigureCustom = func() error {
+		if err := ret.configure(); err != nil {
+			return err
+		}
+		return openaiConfigure()
+	}
+
+	ret.DisplayName = ret.AddSetupQuestionCustom("Display Name", false,
+		fmt.Sprintf(i18n.T("openaicompat_display_name_question"), ret.Name))
+	ret.ApiBaseURL = ret.AddSetupQuestionCustom("API Base URL", true,
+		fmt.Sprintf(i18n.T("openaicompat_base_url_question"), ret.Name))
+	ret.ApiKeyEnvVar = ret.AddSetupQuestionCustom("API Key Env Var", false,
+		fmt.Sprintf(i18n.T("openaicompat_api_key_env_var_question"), ret.Name))
+	ret.ApiKey = plugins.NewSetupQuestion("")
+	return
+}
+
+// Client is an OpenAI-compatible vendor. The API key is read from the
+// environment variable named by ApiKeyEnvVar, so the key itself never has to
+// be stored under this vendor's settings.
+type Client struct {
+	*openai.Client
+	DisplayName  *plugins.SetupQuestion
+	ApiKeyEnvVar *plugins.SetupQuestion
+}
+
+func (o *Client) configure() error {
+	if name := strings.TrimSpace(o.DisplayName.Value); name != "" {
+		o.SetupDescription = fmt.Sprintf("%s (%s)", o.Name, name)
+	}
+
+	o.ApiKey.Value = ""
+	if envVar := strings.TrimSpace(o.ApiKeyEnvVar.Value); envVar != "" {
+		if o.ApiKey.Value = os.Getenv(envVar); o.ApiKey.Value == "" {
+			return fmt.Errorf(i18n.T("openaicompat_api_key_env_var_not_set"), envVar)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package openaicompat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, wantKey, reply string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+		require.Equal(t, wantKey, token)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + reply + `"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newConfiguredClient(t *testing.T, baseURL, apiKeyEnvVar string) *Client {
+	t.Helper()
+	client := NewClient()
+	client.ApiBaseURL.Value = baseURL
+	client.ApiKeyEnvVar.Value = apiKeyEnvVar
+	require.NoError(t, client.Configure())
+	return client
+}
+
+func TestInstancesTargetTheirOwnBaseURL(t *testing.T) {
+	t.Setenv("TOGETHER_TEST_KEY", "together-secret")
+
+	together := newTestServer(t, "together-secret", "from together")
+	local := newTestServer(t, "", "from local")
+
+	togetherClient := newConfiguredClient(t, together.URL+"/v1", "TOGETHER_TEST_KEY")
+	localClient := newConfiguredClient(t, local.URL+"/v1", "")
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+	opts := &domain.ChatOptions{Model: "test-model"}
+
+	got, err := togetherClient.Send(context.Background(), msgs, opts)
+	require.NoError(t, err)
+	require.Equal(t, "from together", got)
+
+	got, err = localClient.Send(context.Background(), msgs, opts)
+	require.NoError(t, err)
+	require.Equal(t, "from local", got)
+}
+
+func TestConfigureRequiresBaseURL(t *testing.T) {
+	client := NewClient()
+	require.False(t, client.IsConfigured())
+	require.Error(t, client.Configure())
+}
+
+func TestConfigureFailsWhenKeyEnvVarUnset(t *testing.T) {
+	client := NewClient()
+	client.ApiBaseURL.Value = "http://localhost:8000/v1"
+	client.ApiKeyEnvVar.Value = "OPENAICOMPAT_TEST_MISSING_KEY"
+
+	err := client.Configure()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "OPENAICOMPAT_TEST_MISSING_KEY")
+}
+
+func TestConfigureUsesDisplayName(t *testing.T) {
+	client := NewClient()
+	client.ApiBaseURL.Value = "http://localhost:8000/v1"
+	client.DisplayName.Value = "vLLM"
+
+	require.NoError(t, client.Configure())
+	require.Equal(t, "OpenAI Compatible", client.GetName())
+	require.Equal(t, "OpenAI Compatible (vLLM)", client.GetSetupDescription())
+}
@@ -0,0 +1,70 @@
+package perplexity_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/perplexity"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools"
+)
+
+// TestChatterSendStripsThinkBeforeCitations streams a reply with a thinking
+// block and sources through Chatter.Send, which removes the thinking block,
+// and checks the citations follow the answer exactly once.
+func TestChatterSendStripsThinkBeforeCitations(t *testing.T) {
+	const sources = `"search_results":[{"title":"A","url":"https://a.example"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"<think>checking"}}],` + sources + `}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"content":" sources</think>"}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"content":"The answer"}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"content":" is 42 [1]."}}]}`,
+		} {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	vm := ai.NewVendorsManager()
+	vm.AddVendors(perplexity.NewTestClient(server.URL))
+	registry := &core.PluginRegistry{
+		Db:            fsdb.NewDb(t.TempDir()),
+		VendorManager: vm,
+		Defaults: &tools.Defaults{
+			PluginBase:         &plugins.PluginBase{},
+			Vendor:             &plugins.Setting{Value: "Perplexity"},
+			Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "sonar"}},
+			ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+		},
+	}
+	chatter, err := registry.GetChatter("", 0, "", true, false)
+	if err != nil {
+		t.Fatalf("GetChatter returned error: %v", err)
+	}
+
+	request := &domain.ChatRequest{Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"}}
+	opts := &domain.ChatOptions{Quiet: true, SuppressThink: true, ThinkStartTag: "<think>", ThinkEndTag: "</think>"}
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	got := session.GetLastMessage().Content
+	if !strings.HasPrefix(got, "The answer is 42 [1].") || strings.Contains(got, "checking") {
+		t.Errorf("expected the answer without the thinking block, got %q", got)
+	}
+	if !strings.HasSuffix(got, "- [1] [A](https://a.example)\n") || strings.Count(got, "https://a.example") != 1 {
+		t.Errorf("expected the source to follow the answer once, got %q", got)
+	}
+}
@@ -0,0 +1,11 @@
+package perplexity
+
+import perplexity "github.com/sgaunet/perplexity-go/v2"
+
+// NewTestClient returns a client that sends its requests to endpoint.
+func NewTestClient(endpoint string) *Client {
+	c := NewClient()
+	c.client = perplexity.NewClient("test-key")
+	c.client.SetEndpoint(endpoint)
+	return c
+}
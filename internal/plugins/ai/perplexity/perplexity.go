@@ -3,15 +3,18 @@ package perplexity
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	perplexity "github.com/sgaunet/perplexity-go/v2"
 )
 
@@ -25,12 +28,13 @@ var models = []string{
 
 type Client struct {
 	*plugins.PluginBase
-	APIKey *plugins.SetupQuestion
-	client *perplexity.Client
+	APIKey  *plugins.SetupQuestion
+	client  *perplexity.Client
+	timeout time.Duration
 }
 
 func NewClient() *Client {
-	c := &Client{}
+	c := &Client{timeout: ai.DefaultTimeout}
 	c.PluginBase = plugins.NewVendorPluginBase(providerName, c.Configure)
 	c.APIKey = c.AddSetupQuestion("API_KEY", true)
 	return c
@@ -50,9 +54,19 @@ func (c *Client) Configure() error {
 		}
 	}
 	c.client = perplexity.NewClient(c.APIKey.Value)
+	c.client.SetHTTPClient(&http.Client{Transport: &ai.ResponseTimeoutTransport{Timeout: c.timeout}})
 	return nil
 }
 
+// SetTimeout bounds how long each Perplexity API request waits for the
+// response to start. A stream that has started is not cut off.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	if c.client != nil {
+		c.client.SetHTTPClient(&http.Client{Transport: &ai.ResponseTimeoutTransport{Timeout: timeout}})
+	}
+}
+
 func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	// Perplexity API does not have a ListModels endpoint.
 	// We return a predefined list.
@@ -60,23 +74,22 @@ func (c *Client) ListModels(_ context.Context) ([]string, error) {
 }
 
 func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	ret, _, err := c.SendWithUsage(ctx, msgs, opts)
+	return ret, err
+}
+
+// SendWithUsage sends a non-streaming request and returns the response text,
+// including any citations, together with the token usage reported by the API.
+func (c *Client) SendWithUsage(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, *domain.UsageMetadata, error) {
 	if c.client == nil {
 		if err := c.Configure(); err != nil {
-			return "", fmt.Errorf(i18n.T("perplexity_failed_configure"), err)
+			return "", nil, fmt.Errorf(i18n.T("perplexity_failed_configure"), err)
 		}
 	}
 
-	var perplexityMessages []perplexity.Message
-	for _, msg := range msgs {
-		perplexityMessages = append(perplexityMessages, perplexity.Message{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
-	}
-
 	requestOptions := []perplexity.CompletionRequestOption{
 		perplexity.WithModel(opts.Model),
-		perplexity.WithMessages(perplexityMessages),
+		messagesOption(msgs),
 	}
 	if opts.MaxTokens > 0 {
 		requestOptions = append(requestOptions, perplexity.WithMaxTokens(opts.MaxTokens))
@@ -95,49 +108,150 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 		// Corrected: Pass float64 directly
 		requestOptions = append(requestOptions, perplexity.WithFrequencyPenalty(opts.FrequencyPenalty))
 	}
+	// The Perplexity client library has no stop sequence option
+	if len(opts.StopSequences) > 0 {
+		debuglog.Warn("%s\n", i18n.T("perplexity_stop_unsupported"))
+	}
+	requestOptions = append(requestOptions, searchFilterOptions(opts)...)
 
 	request := perplexity.NewCompletionRequest(requestOptions...)
 
 	// Corrected: Use SendCompletionRequest method from perplexity-go library
 	resp, err := c.client.SendCompletionRequest(request) // Pass request directly
 	if err != nil {
-		return "", fmt.Errorf(i18n.T("perplexity_api_request_failed"), err)
+		return "", nil, fmt.Errorf(i18n.T("perplexity_api_request_failed"), err)
+	}
+	ai.DumpRawResponseObject(opts, resp)
+
+	var usage *domain.UsageMetadata
+	if resp.Usage.TotalTokens != 0 {
+		usage = &domain.UsageMetadata{
+			InputTokens:  int(resp.Usage.PromptTokens),
+			OutputTokens: int(resp.Usage.CompletionTokens),
+			TotalTokens:  int(resp.Usage.TotalTokens),
+		}
 	}
-
-	var content strings.Builder
-	content.WriteString(resp.GetLastContent())
 
 	// Append citations if available
-	citations := resp.GetCitations()
-	if len(citations) > 0 {
-		content.WriteString(i18n.T("perplexity_citations_header"))
-		for i, citation := range citations {
-			content.WriteString(fmt.Sprintf("- [%d] %s\n", i+1, citation))
+	return resp.GetLastContent() + buildCitationBlock(responseSources(resp)), usage, nil
+}
+
+// citationSource is one entry of the sources block appended to responses.
+type citationSource struct {
+	Title string
+	URL   string
+}
+
+// responseSources returns the sources cited by resp. Structured search results
+// are preferred because they carry titles; bare citation URLs are the fallback.
+func responseSources(resp *perplexity.CompletionResponse) []citationSource {
+	var sources []citationSource
+	for _, result := range resp.GetSearchResults() {
+		if result.URL != "" {
+			sources = append(sources, citationSource{Title: result.Title, URL: result.URL})
 		}
 	}
+	if len(sources) > 0 {
+		return sources
+	}
+	for _, citation := range resp.GetCitations() {
+		sources = append(sources, citationSource{URL: citation})
+	}
+	return sources
+}
+
+// buildCitationBlock renders sources as a numbered markdown link list under the
+// sources header, using an autolink for sources without a title. Numbering is
+// 1-based to match the [n] markers in the answer.
+// It returns "" when there are no sources, so callers can append the block to
+// the response content without inspecting or slicing that content.
+func buildCitationBlock(sources []citationSource) string {
+	if len(sources) == 0 {
+		return ""
+	}
 
-	return content.String(), nil
+	var block strings.Builder
+	block.WriteString(i18n.T("perplexity_citations_header"))
+	for i, source := range sources {
+		if source.Title == "" {
+			block.WriteString(fmt.Sprintf("- [%d] <%s>\n", i+1, source.URL))
+			continue
+		}
+		block.WriteString(fmt.Sprintf("- [%d] [%s](%s)\n", i+1, source.Title, source.URL))
+	}
+	return block.String()
 }
 
-func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
-	if c.client == nil {
-		if err := c.Configure(); err != nil {
-			close(channel) // Ensure channel is closed on error
-			return fmt.Errorf(i18n.T("perplexity_failed_configure"), err)
+// messagesOption maps msgs to the request messages. Plain text messages are
+// sent as is; when any message carries image parts the whole conversation is
+// sent in the multimodal content format, since the two cannot be mixed.
+func messagesOption(msgs []*chat.ChatCompletionMessage) perplexity.CompletionRequestOption {
+	multimodal := false
+	for _, msg := range msgs {
+		if len(msg.MultiContent) > 0 {
+			multimodal = true
+			break
+		}
+	}
+
+	if !multimodal {
+		var perplexityMessages []perplexity.Message
+		for _, msg := range msgs {
+			perplexityMessages = append(perplexityMessages, perplexity.Message{
+				Role:    msg.Role,
+				Content: msg.Content,
+			})
 		}
+		return perplexity.WithMessages(perplexityMessages)
 	}
 
-	var perplexityMessages []perplexity.Message
+	var multimodalMessages []perplexity.MultimodalMessage
 	for _, msg := range msgs {
-		perplexityMessages = append(perplexityMessages, perplexity.Message{
+		var content []perplexity.Content
+		if msg.Content != "" {
+			content = append(content, perplexity.NewTextContent(msg.Content))
+		}
+		for _, part := range msg.MultiContent {
+			switch part.Type {
+			case chat.ChatMessagePartTypeText:
+				content = append(content, perplexity.NewTextContent(part.Text))
+			case chat.ChatMessagePartTypeImageURL:
+				if part.ImageURL != nil && part.ImageURL.URL != "" {
+					content = append(content, perplexity.NewImageURLContent(part.ImageURL.URL))
+				}
+			}
+		}
+		multimodalMessages = append(multimodalMessages, perplexity.MultimodalMessage{
 			Role:    msg.Role,
-			Content: msg.Content,
+			Content: content,
 		})
 	}
+	return perplexity.WithMultimodalMessages(multimodalMessages)
+}
+
+// searchFilterOptions maps the search domain and recency filters onto request
+// options, omitting any that are unset.
+func searchFilterOptions(opts *domain.ChatOptions) (ret []perplexity.CompletionRequestOption) {
+	if len(opts.SearchDomains) > 0 {
+		ret = append(ret, perplexity.WithSearchDomainFilter(opts.SearchDomains))
+	}
+	if opts.SearchRecency != "" {
+		ret = append(ret, perplexity.WithSearchRecencyFilter(opts.SearchRecency))
+	}
+	return
+}
+
+func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+	if c.client == nil {
+		if err := c.Configure(); err != nil {
+			close(channel) // Ensure channel is closed on error
+			return fmt.Errorf(i18n.T("perplexity_failed_configure"), err)
+		}
+	}
 
 	requestOptions := []perplexity.CompletionRequestOption{
 		perplexity.WithModel(opts.Model),
-		perplexity.WithMessages(perplexityMessages),
+		messagesOption(msgs),
 		perplexity.WithStream(true), // Enable streaming
 	}
 
@@ -158,6 +272,11 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 		// Corrected: Pass float64 directly
 		requestOptions = append(requestOptions, perplexity.WithFrequencyPenalty(opts.FrequencyPenalty))
 	}
+	// The Perplexity client library has no stop sequence option
+	if len(opts.StopSequences) > 0 {
+		debuglog.Warn("%s\n", i18n.T("perplexity_stop_unsupported"))
+	}
+	requestOptions = append(requestOptions, searchFilterOptions(opts)...)
 
 	request := perplexity.NewCompletionRequest(requestOptions...)
 
@@ -179,9 +298,12 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 
 	go func() {
 		defer close(channel) // Ensure the output channel is closed when this goroutine finishes
-		var lastResponse *perplexity.CompletionResponse
+		// Sources may arrive on any chunk; keep the most recent non-empty set
+		var sources []citationSource
 		for resp := range responseChan {
-			lastResponse = &resp
+			if chunkSources := responseSources(&resp); len(chunkSources) > 0 {
+				sources = chunkSources
+			}
 			if len(resp.Choices) > 0 {
 				content := ""
 				// Corrected: Check Delta.Content and Message.Content directly for non-emptiness
@@ -212,18 +334,10 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 		}
 
 		// Send citations at the end if available
-		if lastResponse != nil {
-			citations := lastResponse.GetCitations()
-			if len(citations) > 0 {
-				var citationsText strings.Builder
-				citationsText.WriteString(i18n.T("perplexity_citations_header"))
-				for i, citation := range citations {
-					citationsText.WriteString(fmt.Sprintf("- [%d] %s\n", i+1, citation))
-				}
-				channel <- domain.StreamUpdate{
-					Type:    domain.StreamTypeContent,
-					Content: citationsText.String(),
-				}
+		if citations := buildCitationBlock(sources); citations != "" {
+			channel <- domain.StreamUpdate{
+				Type:    domain.StreamTypeContent,
+				Content: citations,
 			}
 		}
 	}()
@@ -231,6 +345,12 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 	return nil
 }
 
+// ModelCapabilities reports that every Perplexity model streams and searches
+// the web.
+func (c *Client) ModelCapabilities(string) ai.ModelCapabilities {
+	return ai.ModelCapabilities{Streaming: true, Search: true}
+}
+
 func (c *Client) NeedsRawMode(modelName string) bool {
 	return true
 }
@@ -0,0 +1,247 @@
+package perplexity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	perplexity "github.com/sgaunet/perplexity-go/v2"
+)
+
+func TestBuildCitationBlock(t *testing.T) {
+	if got := buildCitationBlock(nil); got != "" {
+		t.Errorf("expected no block without citations, got %q", got)
+	}
+
+	block := buildCitationBlock([]citationSource{
+		{Title: "A", URL: "https://a.example"},
+		{URL: "https://b.example"},
+	})
+	if !strings.HasSuffix(block, "## Sources\n\n- [1] [A](https://a.example)\n- [2] <https://b.example>\n") {
+		t.Errorf("unexpected citation block %q", block)
+	}
+}
+
+func TestResponseSourcesPrefersSearchResults(t *testing.T) {
+	citations := []string{"https://old.example"}
+	results := []perplexity.SearchResult{{Title: "A", URL: "https://a.example"}}
+
+	resp := &perplexity.CompletionResponse{Citations: &citations}
+	if got := responseSources(resp); len(got) != 1 || got[0] != (citationSource{URL: "https://old.example"}) {
+		t.Errorf("expected citation fallback, got %+v", got)
+	}
+
+	resp.SearchResults = &results
+	if got := responseSources(resp); len(got) != 1 || got[0] != (citationSource{Title: "A", URL: "https://a.example"}) {
+		t.Errorf("expected search results, got %+v", got)
+	}
+}
+
+func TestBuildCitationBlockWithThinkContent(t *testing.T) {
+	streamed := []string{"<think>checking", " sources</think>", "The answer", " is 42."}
+	block := buildCitationBlock([]citationSource{{URL: "https://a.example"}})
+
+	message := strings.Join(streamed, "") + block
+	got := domain.StripThinkBlocks(message, "<think>", "</think>")
+
+	want := "The answer is 42." + block
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if strings.Count(got, "https://a.example") != 1 {
+		t.Errorf("expected citation to appear once, got %q", got)
+	}
+}
+
+func TestSendWithUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"sonar","object":"chat.completion","created":1,` +
+			`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Answer"}}],` +
+			`"usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.client = perplexity.NewClient("test-key")
+	c.client.SetEndpoint(server.URL)
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "question"}}
+	ret, usage, err := c.SendWithUsage(context.Background(), msgs, &domain.ChatOptions{Model: "sonar"})
+	if err != nil {
+		t.Fatalf("SendWithUsage returned error: %v", err)
+	}
+	if ret != "Answer" {
+		t.Errorf("expected %q, got %q", "Answer", ret)
+	}
+	want := domain.UsageMetadata{InputTokens: 10, OutputTokens: 4, TotalTokens: 14}
+	if usage == nil || *usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, usage)
+	}
+}
+
+func TestSendStreamSourcesMatchSend(t *testing.T) {
+	const sources = `"search_results":[{"title":"A","url":"https://a.example"},{"title":"B","url":"https://b.example"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","model":"sonar","object":"chat.completion","created":1,` +
+				`"choices":[{"index":0,"message":{"role":"assistant","content":"Answer [1][2]"}}],` + sources + `}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"Answer "}}],` + sources + "}\n\n"))
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"[1][2]"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.client = perplexity.NewClient("test-key")
+	c.client.SetEndpoint(server.URL)
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "question"}}
+	opts := &domain.ChatOptions{Model: "sonar"}
+
+	sent, err := c.Send(context.Background(), msgs, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	channel := make(chan domain.StreamUpdate)
+	if err = c.SendStream(context.Background(), msgs, opts, channel); err != nil {
+		t.Fatalf("SendStream returned error: %v", err)
+	}
+	var streamed strings.Builder
+	for update := range channel {
+		if update.Type == domain.StreamTypeContent {
+			streamed.WriteString(update.Content)
+		}
+	}
+
+	want := "Answer [1][2]" + buildCitationBlock([]citationSource{
+		{Title: "A", URL: "https://a.example"},
+		{Title: "B", URL: "https://b.example"},
+	})
+	if sent != want {
+		t.Errorf("expected Send output %q, got %q", want, sent)
+	}
+	if streamed.String() != sent {
+		t.Errorf("expected streamed output %q to match Send output %q", streamed.String(), sent)
+	}
+}
+
+func TestSendStreamTimeoutDoesNotCutOffStartedStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"hel"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"lo"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	c := NewTestClient(server.URL)
+	c.SetTimeout(100 * time.Millisecond)
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "question"}}
+
+	channel := make(chan domain.StreamUpdate)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.SendStream(context.Background(), msgs, &domain.ChatOptions{Model: "sonar"}, channel)
+	}()
+	var streamed strings.Builder
+	for update := range channel {
+		if update.Type == domain.StreamTypeContent {
+			streamed.WriteString(update.Content)
+		}
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("SendStream returned error: %v", err)
+	}
+	if streamed.String() != "hello" {
+		t.Errorf("expected the whole stream %q, got %q", "hello", streamed.String())
+	}
+}
+
+func TestSendIncludesSearchFilters(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"sonar","object":"chat.completion","created":1,` +
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"Answer"}}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.client = perplexity.NewClient("test-key")
+	c.client.SetEndpoint(server.URL)
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "question"}}
+	opts := &domain.ChatOptions{Model: "sonar", SearchDomains: []string{"arxiv.org", "-reddit.com"}, SearchRecency: "week"}
+
+	if _, err := c.Send(context.Background(), msgs, opts); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if got, want := fmt.Sprint(body["search_domain_filter"]), "[arxiv.org -reddit.com]"; got != want {
+		t.Errorf("expected search_domain_filter %s, got %s", want, got)
+	}
+	if got := body["search_recency_filter"]; got != "week" {
+		t.Errorf("expected search_recency_filter week, got %v", got)
+	}
+}
+
+func TestSendEncodesImageParts(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Role    string           `json:"role"`
+			Content []map[string]any `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"sonar","object":"chat.completion","created":1,` +
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"A cat"}}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.client = perplexity.NewClient("test-key")
+	c.client.SetEndpoint(server.URL)
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "Describe images."},
+		{Role: chat.ChatMessageRoleUser, MultiContent: []chat.ChatMessagePart{
+			{Type: chat.ChatMessagePartTypeText, Text: "What is this?"},
+			{Type: chat.ChatMessagePartTypeImageURL, ImageURL: &chat.ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+		}},
+	}
+
+	if _, err := c.Send(context.Background(), msgs, &domain.ChatOptions{Model: "sonar"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if len(body.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(body.Messages))
+	}
+	if got := body.Messages[0].Content; len(got) != 1 || got[0]["text"] != "Describe images." {
+		t.Errorf("unexpected system content: %v", got)
+	}
+	user := body.Messages[1].Content
+	if len(user) != 2 {
+		t.Fatalf("expected 2 user content parts, got %d", len(user))
+	}
+	if user[1]["type"] != "image_url" || fmt.Sprint(user[1]["image_url"]) != "map[url:https://example.com/cat.png]" {
+		t.Errorf("unexpected image part: %v", user[1])
+	}
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+// MaxRawDumpSize caps how much of a single response DumpRawResponse writes.
+const MaxRawDumpSize = domain.MaxFileSize
+
+// rawDumpMu serializes appends so concurrent requests do not interleave.
+var rawDumpMu sync.Mutex
+
+// rawJSONer is implemented by SDK responses that keep the JSON they were
+// decoded from.
+type rawJSONer interface {
+	RawJSON() string
+}
+
+// DumpRawResponse hands data to opts.RawResponseHandler and appends it,
+// followed by a newline, to opts.DumpRawFile when either is set. Bodies over
+// MaxRawDumpSize are truncated. Failures are only reported as warnings so that
+// debugging output never fails a request.
+func DumpRawResponse(opts *domain.ChatOptions, data []byte) {
+	if !wantsRawResponse(opts) {
+		return
+	}
+	if len(data) > MaxRawDumpSize {
+		debuglog.Warn(i18n.T("raw_dump_truncated"), len(data), MaxRawDumpSize)
+		data = data[:MaxRawDumpSize]
+	}
+
+	if opts.RawResponseHandler != nil {
+		opts.RawResponseHandler(data)
+	}
+	if opts.DumpRawFile == "" {
+		return
+	}
+	if err := appendRawDump(opts.DumpRawFile, data); err != nil {
+		debuglog.Warn(i18n.T("raw_dump_failed"), opts.DumpRawFile, err)
+	}
+}
+
+func wantsRawResponse(opts *domain.ChatOptions) bool {
+	return opts != nil && (opts.DumpRawFile != "" || opts.RawResponseHandler != nil)
+}
+
+func appendRawDump(path string, data []byte) (err error) {
+	rawDumpMu.Lock()
+	defer rawDumpMu.Unlock()
+
+	var file *os.File
+	if file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if _, err = file.Write(data); err != nil {
+		return
+	}
+	_, err = file.Write([]byte{'\n'})
+	return
+}
+
+// DumpRawResponseObject dumps an SDK response object. The original response
+// JSON is used when the object retains it; otherwise the object is marshaled.
+func DumpRawResponseObject(opts *domain.ChatOptions, response any) {
+	if !wantsRawResponse(opts) {
+		return
+	}
+	if raw, ok := response.(rawJSONer); ok && raw.RawJSON() != "" {
+		DumpRawResponse(opts, []byte(raw.RawJSON()))
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		debuglog.Warn(i18n.T("raw_dump_failed"), opts.DumpRawFile, err)
+		return
+	}
+	DumpRawResponse(opts, data)
+}
@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+type rawJSONResponse struct {
+	Content string `json:"content"`
+	raw     string
+}
+
+func (r rawJSONResponse) RawJSON() string { return r.raw }
+
+func TestDumpRawResponseObject(t *testing.T) {
+	dumpFile := filepath.Join(t.TempDir(), "raw.jsonl")
+	opts := &domain.ChatOptions{DumpRawFile: dumpFile}
+
+	DumpRawResponseObject(opts, rawJSONResponse{Content: "parsed", raw: `{"content": "original"}`})
+	DumpRawResponseObject(opts, rawJSONResponse{Content: "marshaled"})
+	DumpRawResponseObject(&domain.ChatOptions{}, rawJSONResponse{Content: "ignored"})
+
+	data, err := os.ReadFile(dumpFile)
+	if err != nil {
+		t.Fatalf("failed to read dump file: %v", err)
+	}
+	want := "{\"content\": \"original\"}\n{\"content\":\"marshaled\"}\n"
+	if string(data) != want {
+		t.Errorf("dump file = %q, want %q", data, want)
+	}
+}
+
+func TestDumpRawResponseHandler(t *testing.T) {
+	var got []string
+	opts := &domain.ChatOptions{RawResponseHandler: func(data []byte) { got = append(got, string(data)) }}
+
+	DumpRawResponse(opts, []byte(`{"content":"first"}`))
+	DumpRawResponseObject(opts, rawJSONResponse{Content: "parsed", raw: `{"content":"second"}`})
+
+	want := []string{`{"content":"first"}`, `{"content":"second"}`}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("handler received %q, want %q", got, want)
+	}
+}
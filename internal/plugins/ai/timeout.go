@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// ResponseTimeoutError reports that a vendor did not start responding within
+// the configured timeout.
+type ResponseTimeoutError struct {
+	After time.Duration
+}
+
+func (e *ResponseTimeoutError) Error() string {
+	return fmt.Sprintf(i18n.T("vendor_response_timeout"), e.After)
+}
+
+// Timeout reports true so callers checking for net.Error timeouts match.
+func (e *ResponseTimeoutError) Timeout() bool { return true }
+
+// Temporary reports true, as the vendor may respond in time on a retry.
+func (e *ResponseTimeoutError) Temporary() bool { return true }
+
+// DoWithResponseTimeout sends req with do and fails with a
+// ResponseTimeoutError when the response headers do not arrive within timeout.
+// Reading the body is not bounded, so long streams are not cut off once the
+// vendor has started answering. A timeout of zero or less disables the bound.
+func DoWithResponseTimeout(req *http.Request, timeout time.Duration, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if timeout <= 0 {
+		return do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(timeout, cancel)
+	resp, err := do(req.WithContext(ctx))
+	if !timer.Stop() {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, &ResponseTimeoutError{After: timeout}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the request context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// ResponseTimeoutTransport is an http.RoundTripper that applies
+// DoWithResponseTimeout to every request. A nil Base uses
+// http.DefaultTransport.
+type ResponseTimeoutTransport struct {
+	Base    http.RoundTripper
+	Timeout time.Duration
+}
+
+func (t *ResponseTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return DoWithResponseTimeout(req, t.Timeout, base.RoundTrip)
+}
+
+// SetResponseTimeout makes client fail requests whose response does not start
+// within timeout, keeping its existing transport. The client's own Timeout,
+// which would also cut off a long body, is cleared.
+func SetResponseTimeout(client *http.Client, timeout time.Duration) {
+	client.Timeout = 0
+	if transport, ok := client.Transport.(*ResponseTimeoutTransport); ok {
+		transport.Timeout = timeout
+		return
+	}
+	client.Transport = &ResponseTimeoutTransport{Base: client.Transport, Timeout: timeout}
+}
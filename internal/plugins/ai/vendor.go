@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/plugins"
@@ -16,3 +17,41 @@ type Vendor interface {
 	Send(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error)
 	NeedsRawMode(modelName string) bool
 }
+
+// UsageReporter is implemented by vendors that can report token usage for
+// non-streaming requests. Streaming requests report usage through
+// domain.StreamTypeUsage updates instead.
+type UsageReporter interface {
+	SendWithUsage(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, *domain.UsageMetadata, error)
+}
+
+// TimeoutSetter is implemented by vendors whose HTTP requests can be bounded
+// by a timeout chosen on the command line.
+type TimeoutSetter interface {
+	SetTimeout(timeout time.Duration)
+}
+
+// DefaultTimeout bounds a vendor request when no timeout is configured.
+const DefaultTimeout = 300 * time.Second
+
+// ModelCapabilities describes optional features a vendor offers for a model.
+type ModelCapabilities struct {
+	Streaming bool
+	Search    bool
+}
+
+// CapabilityReporter is implemented by vendors that can describe which
+// optional features they support for a given model.
+type CapabilityReporter interface {
+	ModelCapabilities(model string) ModelCapabilities
+}
+
+// CapabilitiesOf returns the capabilities vendor reports for model. Vendors
+// that do not implement CapabilityReporter are assumed to stream and to have
+// no web search support.
+func CapabilitiesOf(vendor Vendor, model string) ModelCapabilities {
+	if reporter, ok := vendor.(CapabilityReporter); ok {
+		return reporter.ModelCapabilities(model)
+	}
+	return ModelCapabilities{Streaming: true}
+}
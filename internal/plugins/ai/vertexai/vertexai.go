@@ -13,6 +13,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/geminicommon"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -118,6 +119,12 @@ func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	return sortModels(filtered), nil
 }
 
+// ModelCapabilities reports Google Search grounding for Gemini models only;
+// Claude models on Vertex AI are sent without search tools.
+func (c *Client) ModelCapabilities(model string) ai.ModelCapabilities {
+	return ai.ModelCapabilities{Streaming: true, Search: isGeminiModel(model)}
+}
+
 func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
 	if isGeminiModel(opts.Model) {
 		return c.sendGemini(ctx, msgs, opts)
@@ -163,6 +170,7 @@ func (c *Client) sendClaude(ctx context.Context, msgs []*chat.ChatCompletionMess
 	if err != nil {
 		return "", err
 	}
+	ai.DumpRawResponseObject(opts, response)
 
 	// Extract text from response
 	var textParts []string
@@ -287,6 +295,7 @@ func (c *Client) sendGemini(ctx context.Context, msgs []*chat.ChatCompletionMess
 	if err != nil {
 		return "", err
 	}
+	ai.DumpRawResponseObject(opts, response)
 
 	return geminicommon.ExtractTextWithCitations(response), nil
 }
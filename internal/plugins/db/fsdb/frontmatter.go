@@ -0,0 +1,68 @@
+package fsdb
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontmatterDelimiter = "---"
+
+// parseFrontmatter splits a leading YAML frontmatter block, delimited by "---"
+// lines, from content. Content without a well-formed block is returned
+// unchanged with nil metadata.
+func parseFrontmatter(content string) (metadata map[string]any, body string) {
+	normalized := strings.TrimPrefix(content, "\ufeff")
+	firstLine, rest, found := strings.Cut(normalized, "\n")
+	if !found || strings.TrimRight(firstLine, "\r") != frontmatterDelimiter {
+		return nil, content
+	}
+
+	var block []string
+	for {
+		var line string
+		line, rest, found = strings.Cut(rest, "\n")
+		if strings.TrimRight(line, "\r") == frontmatterDelimiter {
+			break
+		}
+		if !found {
+			return nil, content
+		}
+		block = append(block, line)
+	}
+
+	if err := yaml.Unmarshal([]byte(strings.Join(block, "\n")), &metadata); err != nil {
+		return nil, content
+	}
+	return metadata, rest
+}
+
+// frontmatterTags returns the lowercased tags declared in metadata. Tags may be
+// given as a YAML list or as a comma-separated string.
+func frontmatterTags(metadata map[string]any) (ret []string) {
+	var raw []string
+	switch tags := metadata["tags"].(type) {
+	case string:
+		raw = strings.Split(tags, ",")
+	case []any:
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+
+	for _, tag := range raw {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			ret = append(ret, tag)
+		}
+	}
+	return
+}
+
+// frontmatterString returns the trimmed string value of key in metadata, or ""
+// when the key is missing or not a string.
+func frontmatterString(metadata map[string]any, key string) string {
+	value, _ := metadata[key].(string)
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,58 @@
+package fsdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantMetadata map[string]any
+		wantBody     string
+	}{
+		{
+			name:     "no frontmatter",
+			content:  "# IDENTITY\nYou summarize.",
+			wantBody: "# IDENTITY\nYou summarize.",
+		},
+		{
+			name:         "frontmatter block",
+			content:      "---\ntitle: Summarize\ntags: [writing, summary]\n---\n# IDENTITY\n",
+			wantMetadata: map[string]any{"title": "Summarize", "tags": []any{"writing", "summary"}},
+			wantBody:     "# IDENTITY\n",
+		},
+		{
+			name:         "windows line endings",
+			content:      "---\r\ntitle: Summarize\r\n---\r\nbody",
+			wantMetadata: map[string]any{"title": "Summarize"},
+			wantBody:     "body",
+		},
+		{
+			name:     "unterminated block",
+			content:  "---\ntitle: Summarize\nbody",
+			wantBody: "---\ntitle: Summarize\nbody",
+		},
+		{
+			name:     "invalid yaml",
+			content:  "---\n: [\n---\nbody",
+			wantBody: "---\n: [\n---\nbody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, body := parseFrontmatter(tt.content)
+			assert.Equal(t, tt.wantMetadata, metadata)
+			assert.Equal(t, tt.wantBody, body)
+		})
+	}
+}
+
+func TestFrontmatterTags(t *testing.T) {
+	assert.Equal(t, []string{"writing", "summary"}, frontmatterTags(map[string]any{"tags": []any{"Writing", " summary ", 3}}))
+	assert.Equal(t, []string{"writing", "summary"}, frontmatterTags(map[string]any{"tags": "writing, summary,"}))
+	assert.Nil(t, frontmatterTags(nil))
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins/template"
 	"github.com/danielmiessler/fabric/internal/util"
@@ -24,6 +27,15 @@ type Pattern struct {
 	Name        string
 	Description string
 	Pattern     string
+	// SuggestedTemperature is an advisory temperature derived from phrases in
+	// the pattern text, such as "be precise" or "be creative".
+	SuggestedTemperature *float64 `json:"suggested_temperature,omitempty"`
+	// Language is the response language declared by the pattern's
+	// "language:" frontmatter field, if any.
+	Language string `json:"language,omitempty"`
+	// Metadata holds the pattern's YAML frontmatter, such as title, tags and
+	// description. It is nil for patterns without frontmatter.
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 // GetApplyVariables main entry point for getting patterns from any source
@@ -51,8 +63,16 @@ func (o *PatternsEntity) GetWithoutVariables(source, input string) (pattern *Pat
 }
 
 // GetRaw returns a pattern from storage without applying variable processing.
-func (o *PatternsEntity) GetRaw(name string) (*Pattern, error) {
-	return o.getFromDB(name)
+// The frontmatter is parsed into Metadata but kept in Pattern, so the stored
+// file can be edited and saved back unchanged.
+func (o *PatternsEntity) GetRaw(name string) (pattern *Pattern, err error) {
+	if pattern, err = o.getFromDB(name); err != nil {
+		return
+	}
+	pattern.Metadata, _ = parseFrontmatter(pattern.Pattern)
+	setTemperatureHint(pattern)
+	setPatternLanguage(pattern)
+	return
 }
 
 func (o *PatternsEntity) loadPattern(source string) (pattern *Pattern, err error) {
@@ -78,9 +98,29 @@ func (o *PatternsEntity) loadPattern(source string) (pattern *Pattern, err error
 		pattern, err = o.getFromDB(source)
 	}
 
+	if err == nil {
+		// Frontmatter describes the pattern and must not reach the prompt
+		pattern.Metadata, pattern.Pattern = parseFrontmatter(pattern.Pattern)
+		setTemperatureHint(pattern)
+		setPatternLanguage(pattern)
+	}
 	return
 }
 
+// setTemperatureHint records the temperature suggested by the raw pattern text,
+// before any user input is merged into it.
+func setTemperatureHint(pattern *Pattern) {
+	if temperature, ok := domain.SuggestTemperature(pattern.Pattern); ok {
+		pattern.SuggestedTemperature = &temperature
+	}
+}
+
+// setPatternLanguage records the response language declared in the pattern's
+// frontmatter.
+func setPatternLanguage(pattern *Pattern) {
+	pattern.Language = frontmatterString(pattern.Metadata, "language")
+}
+
 func (o *PatternsEntity) ensureInput(pattern *Pattern) {
 	if !strings.Contains(pattern.Pattern, "{{input}}") {
 		if !strings.HasSuffix(pattern.Pattern, "\n") {
@@ -253,6 +293,140 @@ func (o *PatternsEntity) GetNames() (ret []string, err error) {
 	return ret, nil
 }
 
+// patternVariablePattern matches a {{...}} placeholder, as in template.ApplyTemplate.
+var patternVariablePattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// ListVariables returns the variables the named pattern, or pattern file,
+// expects to be supplied with -v.
+func (o *PatternsEntity) ListVariables(source string) (ret []string, err error) {
+	var pattern *Pattern
+	if pattern, err = o.loadPattern(source); err != nil {
+		return
+	}
+	return pattern.ListVariables(), nil
+}
+
+// ListVariables returns the names of the unique {{variable}} placeholders in
+// the pattern, in order of first appearance, without their {{name:default}}
+// defaults. Variables tested by {{#if name}} blocks are included. {{input}} and
+// plugin and extension calls are not variables and are left out.
+func (p *Pattern) ListVariables() (ret []string) {
+	seen := make(map[string]bool)
+	for _, match := range patternVariablePattern.FindAllStringSubmatch(p.Pattern, -1) {
+		raw := match[1]
+		if strings.HasPrefix(raw, "plugin:") || strings.HasPrefix(raw, "ext:") || raw == "/if" {
+			continue
+		}
+		if condition, ok := strings.CutPrefix(raw, "#if "); ok {
+			raw = strings.TrimSpace(condition)
+		}
+		name, _, _ := template.ParseVariable(raw)
+		if name == "input" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		ret = append(ret, name)
+	}
+	return
+}
+
+// maxSearchSnippetLength caps the length, in runes, of a search result snippet.
+const maxSearchSnippetLength = 120
+
+// PatternSearchResult is a pattern whose system prompt matched a search query.
+type PatternSearchResult struct {
+	Name string
+	// Line is the 1-based number of the first matching line.
+	Line int
+	// Snippet is the trimmed text of the first matching line.
+	Snippet string
+}
+
+// Search returns the patterns whose system prompt contains query, matched
+// case-insensitively, sorted by name. Custom patterns override main patterns
+// of the same name, as in getFromDB; patterns that cannot be read are skipped.
+func (o *PatternsEntity) Search(query string) (ret []PatternSearchResult, err error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return
+	}
+
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	for _, name := range names {
+		pattern, readErr := o.getFromDB(name)
+		if readErr != nil {
+			continue
+		}
+		for i, line := range strings.Split(pattern.Pattern, "\n") {
+			if strings.Contains(strings.ToLower(line), query) {
+				ret = append(ret, PatternSearchResult{Name: name, Line: i + 1, Snippet: searchSnippet(line)})
+				break
+			}
+		}
+	}
+	return
+}
+
+// searchSnippet trims line and shortens it to maxSearchSnippetLength runes.
+func searchSnippet(line string) string {
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) <= maxSearchSnippetLength {
+		return string(runes)
+	}
+	return string(runes[:maxSearchSnippetLength-1]) + "…"
+}
+
+// GetByTag returns the names of patterns whose frontmatter declares tag.
+// Matching is case-insensitive; patterns that cannot be read are skipped.
+func (o *PatternsEntity) GetByTag(tag string) (ret []string) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for name, tags := range o.tagsByPattern() {
+		if slices.Contains(tags, tag) {
+			ret = append(ret, name)
+		}
+	}
+	sort.Strings(ret)
+	return
+}
+
+// AllTags returns the sorted union of the tags declared by all patterns.
+func (o *PatternsEntity) AllTags() (ret []string) {
+	seen := make(map[string]bool)
+	for _, tags := range o.tagsByPattern() {
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				ret = append(ret, tag)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return
+}
+
+// tagsByPattern reads the frontmatter tags of every pattern.
+func (o *PatternsEntity) tagsByPattern() (ret map[string][]string) {
+	ret = make(map[string][]string)
+	names, err := o.GetNames()
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		pattern, err := o.getFromDB(name)
+		if err != nil {
+			continue
+		}
+		metadata, _ := parseFrontmatter(pattern.Pattern)
+		if tags := frontmatterTags(metadata); len(tags) > 0 {
+			ret[name] = tags
+		}
+	}
+	return
+}
+
 // ListNames overrides StorageEntity.ListNames to use PatternsEntity.GetNames
 func (o *PatternsEntity) ListNames(shellCompleteList bool) (err error) {
 	var names []string
@@ -284,7 +458,7 @@ func (o *PatternsEntity) Save(name string, content []byte) (err error) {
 		return fmt.Errorf(i18n.T("patterns_error_create_directory"), err)
 	}
 	patternPath := filepath.Join(patternDir, o.SystemPatternFile)
-	if err = os.WriteFile(patternPath, content, 0644); err != nil {
+	if err = writeFileAtomic(patternPath, content, 0644); err != nil {
 		return fmt.Errorf(i18n.T("patterns_error_save_pattern"), err)
 	}
 	return nil
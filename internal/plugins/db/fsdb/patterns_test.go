@@ -3,7 +3,9 @@ package fsdb
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -98,6 +100,7 @@ func TestGetApplyVariables(t *testing.T) {
 
 	// Create a test pattern
 	createTestPattern(t, entity, "test-pattern", "You are a {{role}}.\n{{input}}")
+	createTestPattern(t, entity, "conditional-pattern", "Summarize.{{#if language}} Respond in {{language}}.{{/if}}\n{{input}}")
 
 	tests := []struct {
 		name      string
@@ -123,6 +126,19 @@ func TestGetApplyVariables(t *testing.T) {
 			input:     "test input",
 			wantErr:   true,
 		},
+		{
+			name:      "conditional section with variable set",
+			source:    "conditional-pattern",
+			variables: map[string]string{"language": "French"},
+			input:     "text",
+			want:      "Summarize. Respond in French.\ntext",
+		},
+		{
+			name:   "conditional markers in input are left alone",
+			source: "conditional-pattern",
+			input:  "{{#if x}}literal{{/if}}",
+			want:   "Summarize.\n{{#if x}}literal{{/if}}",
+		},
 		{
 			name:    "non-existent pattern",
 			source:  "non-existent",
@@ -368,3 +384,124 @@ func TestPatternsEntity_CustomPatternsEmpty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Main pattern content", pattern.Pattern)
 }
+
+func TestPatternTags(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "summarize", "---\ntags: [writing, summary]\n---\nSummarize {{input}}")
+	createTestPattern(t, entity, "review_code", "---\ntags: development, Review\n---\nReview {{input}}")
+	createTestPattern(t, entity, "write_essay", "---\ntags:\n  - writing\n---\nWrite {{input}}")
+	createTestPattern(t, entity, "untagged", "Plain pattern {{input}}")
+
+	assert.Equal(t, []string{"summarize", "write_essay"}, entity.GetByTag("writing"))
+	assert.Equal(t, []string{"review_code"}, entity.GetByTag("REVIEW"))
+	assert.Empty(t, entity.GetByTag("missing"))
+	assert.Equal(t, []string{"development", "review", "summary", "writing"}, entity.AllTags())
+}
+
+func TestPatternLanguage(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "french", "---\nlanguage: fr\n---\nSummarize {{input}}")
+	createTestPattern(t, entity, "plain", "Summarize {{input}}")
+
+	pattern, err := entity.GetApplyVariables("french", nil, "text")
+	require.NoError(t, err)
+	assert.Equal(t, "fr", pattern.Language)
+
+	pattern, err = entity.GetApplyVariables("plain", nil, "text")
+	require.NoError(t, err)
+	assert.Empty(t, pattern.Language)
+}
+
+func TestPatternsEntity_Search(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	customDir := t.TempDir()
+	entity.CustomPatternsDir = customDir
+	custom := &PatternsEntity{
+		StorageEntity:     &StorageEntity{Dir: customDir, Label: "patterns", ItemIsDir: true},
+		SystemPatternFile: "system.md",
+	}
+
+	createTestPattern(t, entity, "create_threat_model", "# IDENTITY\nYou build a Threat Model for the input.\n{{input}}")
+	createTestPattern(t, entity, "analyze_threat_report", "# IDENTITY\nYou summarize threat reports.\nThen write a threat model summary.")
+	createTestPattern(t, entity, "summarize", "Summarize {{input}}")
+	createTestPattern(t, entity, "overridden", "Main version mentions a threat model")
+	createTestPattern(t, custom, "overridden", "Custom version does not")
+
+	results, err := entity.Search("threat MODEL")
+	require.NoError(t, err)
+	assert.Equal(t, []PatternSearchResult{
+		{Name: "analyze_threat_report", Line: 3, Snippet: "Then write a threat model summary."},
+		{Name: "create_threat_model", Line: 2, Snippet: "You build a Threat Model for the input."},
+	}, results)
+
+	results, err = entity.Search("custom version")
+	require.NoError(t, err)
+	assert.Equal(t, []PatternSearchResult{{Name: "overridden", Line: 1, Snippet: "Custom version does not"}}, results)
+
+	results, err = entity.Search("  ")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPatternsEntity_ListVariables(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "repeated", "---\ntitle: Repeated\n---\nYou are a {{role}} writing for {{audience}}.\n{{input}}\nStay {{role}}, use {{plugin:text:upper:x}} and {{points}} points in a {{tone:calm}} tone.{{#if language}} Respond in {{language}}.{{/if}}")
+	createTestPattern(t, entity, "plain", "Summarize the following.\n{{input}}")
+
+	variables, err := entity.ListVariables("repeated")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role", "audience", "points", "tone", "language"}, variables)
+
+	variables, err = entity.ListVariables("plain")
+	require.NoError(t, err)
+	assert.Empty(t, variables)
+
+	_, err = entity.ListVariables("missing")
+	assert.Error(t, err)
+}
+
+func TestSearchSnippet(t *testing.T) {
+	assert.Equal(t, "short line", searchSnippet("  short line\t"))
+
+	long := strings.Repeat("a", maxSearchSnippetLength+10)
+	snippet := searchSnippet(long)
+	assert.Equal(t, maxSearchSnippetLength, utf8.RuneCountInString(snippet))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+}
+
+func TestPatternFrontmatterMetadata(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	content := "---\ntitle: Summarize\ntags: [writing, summary]\n---\n# IDENTITY\nSummarize {{input}}"
+	createTestPattern(t, entity, "summarize", content)
+	createTestPattern(t, entity, "plain", "# IDENTITY\nSummarize {{input}}")
+
+	pattern, err := entity.GetApplyVariables("summarize", nil, "text")
+	require.NoError(t, err)
+	assert.Equal(t, "# IDENTITY\nSummarize text", pattern.Pattern)
+	assert.Equal(t, "Summarize", pattern.Metadata["title"])
+	assert.Equal(t, []any{"writing", "summary"}, pattern.Metadata["tags"])
+
+	pattern, err = entity.GetWithoutVariables("summarize", "text")
+	require.NoError(t, err)
+	assert.NotContains(t, pattern.Pattern, "title:")
+
+	pattern, err = entity.GetRaw("summarize")
+	require.NoError(t, err)
+	assert.Equal(t, content, pattern.Pattern, "raw pattern keeps its frontmatter")
+	assert.Equal(t, "Summarize", pattern.Metadata["title"])
+
+	pattern, err = entity.GetApplyVariables("plain", nil, "text")
+	require.NoError(t, err)
+	assert.Equal(t, "# IDENTITY\nSummarize text", pattern.Pattern)
+	assert.Nil(t, pattern.Metadata)
+}
@@ -1,7 +1,13 @@
 package fsdb
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
@@ -33,6 +39,83 @@ func (o *SessionsEntity) PrintSession(name string) (err error) {
 	return
 }
 
+// ExportMarkdown renders the saved session name as a Markdown transcript. Meta
+// messages are included as blockquotes only when includeMeta is true.
+func (o *SessionsEntity) ExportMarkdown(name string, includeMeta bool) (ret string, err error) {
+	if !o.Exists(name) {
+		err = fmt.Errorf(i18n.T("sessions_not_found"), name)
+		return
+	}
+	session := Session{Name: name}
+	if err = o.LoadAsJson(name, &session.Messages); err != nil {
+		return
+	}
+	ret = session.Markdown(includeMeta)
+	return
+}
+
+// importableRoles lists the message roles accepted by ImportSession.
+var importableRoles = []string{
+	chat.ChatMessageRoleSystem,
+	chat.ChatMessageRoleUser,
+	chat.ChatMessageRoleAssistant,
+	chat.ChatMessageRoleDeveloper,
+	chat.ChatMessageRoleFunction,
+	chat.ChatMessageRoleTool,
+	domain.ChatMessageRoleMeta,
+}
+
+// ImportSession stores the messages in the JSON file at path as session name.
+// The file uses the same layout as saved session files: an array of messages.
+// Every message must have a known role and some content, and an existing
+// session is only replaced when force is true. It returns the imported session.
+func (o *SessionsEntity) ImportSession(path, name string, force bool) (session *Session, err error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		err = fmt.Errorf(i18n.T("sessions_import_invalid_name"), name)
+		return
+	}
+	if o.Exists(name) && !force {
+		err = fmt.Errorf(i18n.T("sessions_import_already_exists"), name)
+		return
+	}
+
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		err = fmt.Errorf(i18n.T("sessions_import_read_failed"), path, err)
+		return
+	}
+	var messages []*chat.ChatCompletionMessage
+	if err = json.Unmarshal(data, &messages); err != nil {
+		err = fmt.Errorf(i18n.T("sessions_import_invalid_json"), path, err)
+		return
+	}
+	if err = validateImportedMessages(messages); err != nil {
+		return
+	}
+
+	session = &Session{Name: name, Messages: messages}
+	err = o.SaveSession(session)
+	return
+}
+
+func validateImportedMessages(messages []*chat.ChatCompletionMessage) error {
+	if len(messages) == 0 {
+		return errors.New(i18n.T("sessions_import_no_messages"))
+	}
+	for i, message := range messages {
+		if message == nil {
+			return fmt.Errorf(i18n.T("sessions_import_empty_message"), i+1)
+		}
+		if !slices.Contains(importableRoles, message.Role) {
+			return fmt.Errorf(i18n.T("sessions_import_unknown_role"), i+1, message.Role)
+		}
+		if message.Content == "" && len(message.MultiContent) == 0 && len(message.ToolCalls) == 0 && message.FunctionCall == nil {
+			return fmt.Errorf(i18n.T("sessions_import_empty_message"), i+1)
+		}
+	}
+	return nil
+}
+
 func (o *SessionsEntity) SaveSession(session *Session) (err error) {
 	return o.SaveAsJson(session.Name, session.Messages)
 }
@@ -40,6 +123,13 @@ func (o *SessionsEntity) SaveSession(session *Session) (err error) {
 type Session struct {
 	Name     string
 	Messages []*chat.ChatCompletionMessage
+	// RawResponse holds the body of the last vendor response, as written by
+	// --dump-raw. It is only set when ChatOptions.ReturnRawResponse is enabled
+	// and the vendor captures its response, and is not persisted.
+	RawResponse string `json:"-"`
+	// Usage holds the token usage reported by the vendor for the last request,
+	// or nil when the vendor did not report it. It is not persisted.
+	Usage *domain.UsageMetadata `json:"-"`
 
 	vendorMessages []*chat.ChatCompletionMessage
 }
@@ -98,3 +188,64 @@ func (o *Session) String() (ret string) {
 	}
 	return
 }
+
+// Markdown renders the messages as a transcript with one "## Role" section per
+// message. Content is only trimmed of surrounding whitespace, so code fences
+// survive unchanged. Meta messages are rendered as blockquotes when
+// includeMeta is true and skipped otherwise.
+func (o *Session) Markdown(includeMeta bool) string {
+	var sections []string
+	for _, message := range o.Messages {
+		body := messageMarkdown(message)
+		if message.Role == domain.ChatMessageRoleMeta {
+			if includeMeta && body != "" {
+				sections = append(sections, markdownBlockquote(body))
+			}
+			continue
+		}
+		sections = append(sections, fmt.Sprintf("## %s\n\n%s", markdownRoleHeading(message.Role), body))
+	}
+	if len(sections) == 0 {
+		return ""
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+func messageMarkdown(message *chat.ChatCompletionMessage) string {
+	parts := []string{}
+	if content := strings.TrimSpace(message.Content); content != "" {
+		parts = append(parts, content)
+	}
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case chat.ChatMessagePartTypeText:
+			if text := strings.TrimSpace(part.Text); text != "" {
+				parts = append(parts, text)
+			}
+		case chat.ChatMessagePartTypeImageURL:
+			if part.ImageURL != nil {
+				parts = append(parts, fmt.Sprintf("![image](<%s>)", part.ImageURL.URL))
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func markdownRoleHeading(role string) string {
+	if role == "" {
+		return role
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
+
+func markdownBlockquote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
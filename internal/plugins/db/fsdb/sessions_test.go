@@ -1,9 +1,12 @@
 package fsdb
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
 )
 
 func TestSessions_GetOrCreateSession(t *testing.T) {
@@ -36,3 +39,106 @@ func TestSessions_SaveSession(t *testing.T) {
 		t.Errorf("expected session to be saved")
 	}
 }
+
+func TestSessions_ExportMarkdown(t *testing.T) {
+	sessions := &SessionsEntity{
+		StorageEntity: &StorageEntity{Dir: t.TempDir(), FileExtension: ".json"},
+	}
+	session := &Session{Name: "transcript", Messages: []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "You are helpful."},
+		{Role: domain.ChatMessageRoleMeta, Content: "source: notes.txt\n\nfetched today"},
+		{Role: chat.ChatMessageRoleUser, Content: "Show me hello world"},
+		{Role: chat.ChatMessageRoleAssistant, Content: "Here it is:\n\n```go\nfmt.Println(\"hello\")\n```"},
+	}}
+	if err := sessions.SaveSession(session); err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+
+	conversation := "## System\n\nYou are helpful.\n\n" +
+		"## User\n\nShow me hello world\n\n" +
+		"## Assistant\n\nHere it is:\n\n```go\nfmt.Println(\"hello\")\n```\n"
+	got, err := sessions.ExportMarkdown("transcript", false)
+	if err != nil {
+		t.Fatalf("ExportMarkdown returned error: %v", err)
+	}
+	if got != conversation {
+		t.Errorf("unexpected transcript without meta:\n%s", got)
+	}
+
+	withMeta := "## System\n\nYou are helpful.\n\n" +
+		"> source: notes.txt\n>\n> fetched today\n\n" +
+		"## User\n\nShow me hello world\n\n" +
+		"## Assistant\n\nHere it is:\n\n```go\nfmt.Println(\"hello\")\n```\n"
+	if got, err = sessions.ExportMarkdown("transcript", true); err != nil {
+		t.Fatalf("ExportMarkdown returned error: %v", err)
+	}
+	if got != withMeta {
+		t.Errorf("unexpected transcript with meta:\n%s", got)
+	}
+
+	if _, err = sessions.ExportMarkdown("missing", false); err == nil {
+		t.Error("expected error for missing session")
+	}
+}
+
+func TestSessions_ImportSession(t *testing.T) {
+	sessions := &SessionsEntity{
+		StorageEntity: &StorageEntity{Dir: t.TempDir(), FileExtension: ".json"},
+	}
+	writeFile := func(content string) string {
+		path := filepath.Join(t.TempDir(), "transcript.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write import file: %v", err)
+		}
+		return path
+	}
+	valid := writeFile(`[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]`)
+
+	session, err := sessions.ImportSession(valid, "moved", false)
+	if err != nil {
+		t.Fatalf("ImportSession returned error: %v", err)
+	}
+	if len(session.Messages) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(session.Messages))
+	}
+	saved, err := sessions.Get("moved")
+	if err != nil {
+		t.Fatalf("failed to load imported session: %v", err)
+	}
+	if got := saved.GetLastMessage(); got.Role != chat.ChatMessageRoleAssistant || got.Content != "hello" {
+		t.Errorf("unexpected last message %+v", got)
+	}
+
+	if _, err = sessions.ImportSession(valid, "moved", false); err == nil {
+		t.Error("expected error when importing over an existing session")
+	}
+	if _, err = sessions.ImportSession(valid, "moved", true); err != nil {
+		t.Errorf("expected --force import to succeed, got %v", err)
+	}
+
+	invalid := []struct {
+		name    string
+		content string
+	}{
+		{name: "not json", content: `{"role":`},
+		{name: "not an array", content: `{"role":"user","content":"hi"}`},
+		{name: "no messages", content: `[]`},
+		{name: "unknown role", content: `[{"role":"narrator","content":"once upon a time"}]`},
+		{name: "missing content", content: `[{"role":"user"}]`},
+		{name: "null message", content: `[null]`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sessions.ImportSession(writeFile(tt.content), "broken", false); err == nil {
+				t.Error("expected error for malformed input")
+			}
+			if sessions.Exists("broken") {
+				t.Error("malformed input should not create a session")
+			}
+		})
+	}
+
+	if _, err = sessions.ImportSession(valid, "../escape", false); err == nil {
+		t.Error("expected error for a session name with a path")
+	}
+}
@@ -88,12 +88,50 @@ func (o *StorageEntity) Rename(oldName, newName string) (err error) {
 }
 
 func (o *StorageEntity) Save(name string, content []byte) (err error) {
-	if err = os.WriteFile(o.BuildFilePathByName(name), content, 0644); err != nil {
+	if err = writeFileAtomic(o.BuildFilePathByName(name), content, 0644); err != nil {
 		err = fmt.Errorf(i18n.T("storage_error_save"), name, err)
 	}
 	return
 }
 
+// writeTempContent writes content to the temporary file; tests replace it to
+// simulate a write interrupted part way through.
+var writeTempContent = func(file *os.File, content []byte) (err error) {
+	_, err = file.Write(content)
+	return
+}
+
+// writeFileAtomic writes content to a temporary file in the target's directory
+// and renames it over path, so an interrupted write never leaves a truncated
+// file behind.
+func writeFileAtomic(path string, content []byte, perm os.FileMode) (err error) {
+	var file *os.File
+	if file, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*"); err != nil {
+		return
+	}
+	tempPath := file.Name()
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	if err = writeTempContent(file, content); err != nil {
+		return
+	}
+	if err = file.Sync(); err != nil {
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	if err = os.Chmod(tempPath, perm); err != nil {
+		return
+	}
+	return os.Rename(tempPath, path)
+}
+
 func (o *StorageEntity) Load(name string) (ret []byte, err error) {
 	if ret, err = os.ReadFile(o.BuildFilePathByName(name)); err != nil {
 		err = fmt.Errorf(i18n.T("storage_error_load"), name, err)
@@ -1,6 +1,8 @@
 package fsdb
 
 import (
+	"errors"
+	"os"
 	"testing"
 )
 
@@ -50,3 +52,39 @@ func TestStorage_Delete(t *testing.T) {
 		t.Errorf("expected file to be deleted")
 	}
 }
+
+func TestStorage_SaveInterruptedKeepsPrevious(t *testing.T) {
+	dir := t.TempDir()
+	storage := &StorageEntity{Dir: dir, FileExtension: ".json"}
+	if err := storage.Save("session", []byte(`[{"role":"user","content":"hello"}]`)); err != nil {
+		t.Fatalf("failed to save content: %v", err)
+	}
+
+	original := writeTempContent
+	defer func() { writeTempContent = original }()
+	writeTempContent = func(file *os.File, content []byte) error {
+		if _, err := file.Write(content[:len(content)/2]); err != nil {
+			return err
+		}
+		return errors.New("interrupted")
+	}
+
+	if err := storage.Save("session", []byte(`[{"role":"user","content":"a much longer replacement"}]`)); err == nil {
+		t.Fatal("expected interrupted save to fail")
+	}
+
+	loaded, err := storage.Load("session")
+	if err != nil {
+		t.Fatalf("failed to load content: %v", err)
+	}
+	if string(loaded) != `[{"role":"user","content":"hello"}]` {
+		t.Errorf("expected previous content to remain intact, got %q", loaded)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected temporary file to be removed, found %d entries", len(entries))
+	}
+}
@@ -224,6 +224,37 @@ func LoadStrategy(filename string) (*Strategy, error) {
 	return &strategy, nil
 }
 
+// ListStrategies returns the strategies in the strategies directory, sorted
+// by name.
+func ListStrategies() (ret []Strategy, err error) {
+	var strategies map[string]Strategy
+	if strategies, err = LoadAllFiles(); err != nil {
+		return
+	}
+	ret = sortedStrategies(strategies)
+	return
+}
+
+// sortedStrategies returns the strategies ordered by name.
+func sortedStrategies(strategies map[string]Strategy) (ret []Strategy) {
+	ret = make([]Strategy, 0, len(strategies))
+	for _, strategy := range strategies {
+		ret = append(ret, strategy)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return
+}
+
+// DescribeStrategy prints the prompt of the named strategy.
+func DescribeStrategy(name string) (err error) {
+	var strategy *Strategy
+	if strategy, err = LoadStrategy(name); err != nil {
+		return
+	}
+	fmt.Println(strategy.Prompt)
+	return
+}
+
 // ListStrategies prints available strategies
 func (sm *StrategiesManager) ListStrategies(shellCompleteList bool) error {
 	if len(sm.Strategies) == 0 {
@@ -232,27 +263,19 @@ func (sm *StrategiesManager) ListStrategies(shellCompleteList bool) error {
 	if !shellCompleteList {
 		fmt.Print(i18n.T("strategies_available_header"), "\n\n")
 	}
-	// Get all strategy names for sorting
-	names := []string{}
-	for name := range sm.Strategies {
-		names = append(names, name)
-	}
-
-	// Sort the strategy names alphabetically
-	sort.Strings(names)
+	strategies := sortedStrategies(sm.Strategies)
 
 	// Find the longest name to align descriptions
 	maxNameLength := 0
-	for _, name := range names {
-		if len(name) > maxNameLength {
-			maxNameLength = len(name)
+	for _, strategy := range strategies {
+		if len(strategy.Name) > maxNameLength {
+			maxNameLength = len(strategy.Name)
 		}
 	}
 
 	// Print each strategy with its description aligned
 	formatString := "%-" + fmt.Sprintf("%d", maxNameLength+2) + "s %s\n"
-	for _, name := range names {
-		strategy := sm.Strategies[name]
+	for _, strategy := range strategies {
 		if shellCompleteList {
 			fmt.Printf("%s\n", strategy.Name)
 		} else {
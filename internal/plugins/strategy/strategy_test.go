@@ -1,8 +1,10 @@
 package strategy
 
 import (
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -154,3 +156,61 @@ func TestLoadStrategy_NotFound(t *testing.T) {
 		t.Fatal("expected error for nonexistent strategy")
 	}
 }
+
+func TestListStrategies(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	strategyDir := filepath.Join(homeDir, ".config", "fabric", "strategies")
+	if err := os.MkdirAll(strategyDir, 0o755); err != nil {
+		t.Fatalf("failed to create strategy dir: %v", err)
+	}
+	files := map[string]string{
+		"tot.json": `{"description":"Tree of thought","prompt":"Explore branches."}`,
+		"cot.json": `{"description":"Chain of thought","prompt":"Think step by step."}`,
+		"notes.md": "not a strategy",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(strategyDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	strategies, err := ListStrategies()
+	if err != nil {
+		t.Fatalf("ListStrategies() error = %v", err)
+	}
+	want := []Strategy{
+		{Name: "cot", Description: "Chain of thought", Prompt: "Think step by step."},
+		{Name: "tot", Description: "Tree of thought", Prompt: "Explore branches."},
+	}
+	if !reflect.DeepEqual(strategies, want) {
+		t.Errorf("ListStrategies() = %+v, want %+v", strategies, want)
+	}
+
+	readStdout := func(print func() error) string {
+		t.Helper()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("failed to create pipe: %v", err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		err = print()
+		os.Stdout = stdout
+		w.Close()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		output, _ := io.ReadAll(r)
+		return string(output)
+	}
+
+	if got := readStdout(func() error { return DescribeStrategy("cot") }); strings.TrimSpace(got) != "Think step by step." {
+		t.Errorf("expected the strategy prompt, got %q", got)
+	}
+
+	if err := DescribeStrategy("missing"); err == nil {
+		t.Error("expected an error for a missing strategy")
+	}
+}
@@ -32,9 +32,22 @@ func init() {
 	// Extensions will work if registry exists, otherwise they'll just fail gracefully
 }
 
+// MissingVariableError reports a template variable that has neither a value
+// nor a default.
+type MissingVariableError struct {
+	Name string
+}
+
+func (e *MissingVariableError) Error() string {
+	return fmt.Sprintf(i18n.T("template_missing_required_variable"), e.Name)
+}
+
 var pluginPattern = regexp.MustCompile(`\{\{plugin:([^:]+):([^:]+)(?::([^}]+))?\}\}`)
 var extensionPattern = regexp.MustCompile(`\{\{ext:([^:]+):([^:]+)(?::([^}]+))?\}\}`)
 
+// conditionalPattern matches a {{#if name}}...{{/if}} block. Blocks do not nest.
+var conditionalPattern = regexp.MustCompile(`(?s)\{\{#if\s+([^{}\s]+)\s*\}\}(.*?)\{\{/if\}\}`)
+
 func debugf(format string, a ...any) {
 	debuglog.Debug(debuglog.Trace, format, a...)
 }
@@ -53,11 +66,45 @@ func matchTriple(r *regexp.Regexp, full string) (string, string, string, bool) {
 	return "", "", "", false
 }
 
+// ParseVariable splits the contents of a {{name:default}} placeholder into the
+// variable name and its default value. Everything after the first colon is the
+// default, so defaults may contain spaces and colons. Plugin and extension calls
+// never carry a default.
+func ParseVariable(raw string) (name, defaultValue string, hasDefault bool) {
+	if strings.HasPrefix(raw, "plugin:") || strings.HasPrefix(raw, "ext:") {
+		return raw, "", false
+	}
+	return strings.Cut(raw, ":")
+}
+
+// applyConditionals keeps the body of each {{#if name}}...{{/if}} block whose
+// variable is set to a non-empty value and drops the others. It runs once,
+// before any other token is resolved.
+func applyConditionals(content string, variables map[string]string) (string, error) {
+	content = conditionalPattern.ReplaceAllStringFunc(content, func(block string) string {
+		parts := conditionalPattern.FindStringSubmatch(block)
+		if variables[parts[1]] == "" {
+			debugf("Omitting conditional block for %s\n", parts[1])
+			return ""
+		}
+		return parts[2]
+	})
+	if strings.Contains(content, "{{#if") || strings.Contains(content, "{{/if}}") {
+		return "", errors.New(i18n.T("template_unmatched_conditional"))
+	}
+	return content, nil
+}
+
 func ApplyTemplate(content string, variables map[string]string, input string) (string, error) {
 	tokenPattern := regexp.MustCompile(`\{\{([^{}]+)\}\}`)
 
 	debugf("Starting template processing with input='%s'\n", input)
 
+	content, err := applyConditionals(content, variables)
+	if err != nil {
+		return "", err
+	}
+
 	for {
 		if !strings.Contains(content, "{{") {
 			break
@@ -134,9 +181,14 @@ func ApplyTemplate(content string, variables map[string]string, input string) (s
 				content = strings.ReplaceAll(content, full, input)
 				progress = true
 			default:
-				val, ok := variables[raw]
+				name, defaultValue, hasDefault := ParseVariable(raw)
+				val, ok := variables[name]
 				if !ok {
-					return "", fmt.Errorf(i18n.T("template_missing_required_variable"), raw)
+					if !hasDefault {
+						return "", &MissingVariableError{Name: name}
+					}
+					debugf("Using default value for variable %s\n", name)
+					val = defaultValue
 				}
 				content = strings.ReplaceAll(content, full, val)
 				progress = true
@@ -38,6 +38,70 @@ func TestApplyTemplate(t *testing.T) {
 			want:     "Content: test content",
 		},
 
+		// Default values
+		{
+			name:     "supplied value wins over default",
+			template: "Write in a {{tone:professional}} tone.",
+			vars:     map[string]string{"tone": "casual"},
+			want:     "Write in a casual tone.",
+		},
+		{
+			name:     "default used when variable missing",
+			template: "Write in a {{tone:calm and professional}} tone, {{style:a: b}}.",
+			want:     "Write in a calm and professional tone, a: b.",
+		},
+		{
+			name:     "input alongside defaults",
+			template: "{{tone:neutral}}: {{input}}",
+			input:    "test content",
+			want:     "neutral: test content",
+		},
+		{
+			name:     "empty default",
+			template: "[{{suffix:}}]",
+			want:     "[]",
+		},
+
+		// Conditional sections
+		{
+			name:     "conditional kept when variable set",
+			template: "Summarize.{{#if language}} Respond in {{language}}.{{/if}}",
+			vars:     map[string]string{"language": "French"},
+			want:     "Summarize. Respond in French.",
+		},
+		{
+			name:     "conditional omitted when variable missing",
+			template: "Summarize.{{#if language}} Respond in {{language}}.{{/if}}",
+			want:     "Summarize.",
+		},
+		{
+			name:     "conditional omitted when variable empty",
+			template: "Summarize.{{#if language}} Respond in {{language}}.{{/if}}",
+			vars:     map[string]string{"language": ""},
+			want:     "Summarize.",
+		},
+		{
+			name:     "multiline and multiple conditionals",
+			template: "{{#if audience}}Audience: {{audience}}\n{{/if}}{{#if tone}}Tone: {{tone}}\n{{/if}}{{input}}",
+			vars:     map[string]string{"tone": "formal"},
+			input:    "text",
+			want:     "Tone: formal\ntext",
+		},
+		{
+			name:        "unclosed conditional",
+			template:    "{{#if language}}Respond in {{language}}.",
+			vars:        map[string]string{"language": "French"},
+			wantErr:     true,
+			errContains: "conditional block",
+		},
+		{
+			name:        "nested conditional",
+			template:    "{{#if a}}x{{#if b}}y{{/if}}z{{/if}}",
+			vars:        map[string]string{"a": "1", "b": "1"},
+			wantErr:     true,
+			errContains: "conditional block",
+		},
+
 		// Nested variable substitution
 		{
 			name:     "nested variables",
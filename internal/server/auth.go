@@ -1,6 +1,7 @@
 package restapi
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -9,26 +10,31 @@ import (
 
 const APIKeyHeader = "X-API-Key"
 
-// APIKeyMiddleware validates API key for protected endpoints.
-// Swagger documentation endpoints (/swagger/*) are exempt from authentication
-// to allow users to browse and test the API documentation freely.
+// APIKeyMiddleware validates the API key, sent either in the X-API-Key header
+// or as an "Authorization: Bearer" token, in constant time.
+// Swagger documentation endpoints (/swagger/*) and /health are exempt from
+// authentication to allow users to browse the API documentation freely and
+// monitoring to probe the server.
 func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip authentication for Swagger documentation endpoints
 		// This allows public access to API docs even when authentication is enabled
-		if strings.HasPrefix(c.Request.URL.Path, "/swagger/") {
+		path := c.Request.URL.Path
+		if strings.HasPrefix(path, "/swagger/") || path == HealthPath {
 			c.Next()
 			return
 		}
 
-		headerApiKey := c.GetHeader(APIKeyHeader)
+		headerApiKey := requestAPIKey(c)
 
 		if headerApiKey == "" {
+			c.Header("WWW-Authenticate", "Bearer")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API Key"})
 			return
 		}
 
-		if headerApiKey != apiKey {
+		if subtle.ConstantTimeCompare([]byte(headerApiKey), []byte(apiKey)) != 1 {
+			c.Header("WWW-Authenticate", "Bearer")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Wrong API Key"})
 			return
 		}
@@ -36,3 +42,16 @@ func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// requestAPIKey returns the key from the X-API-Key header, falling back to a
+// bearer token in the Authorization header.
+func requestAPIKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,59 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newAuthTestRouter(apiKey string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if apiKey != "" {
+		r.Use(APIKeyMiddleware(apiKey))
+	}
+	r.GET(HealthPath, Health)
+	r.GET("/patterns/names", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"bearer token", "secret", "/patterns/names", map[string]string{"Authorization": "Bearer secret"}, http.StatusOK},
+		{"lowercase bearer scheme", "secret", "/patterns/names", map[string]string{"Authorization": "bearer secret"}, http.StatusOK},
+		{"api key header", "secret", "/patterns/names", map[string]string{APIKeyHeader: "secret"}, http.StatusOK},
+		{"missing key", "secret", "/patterns/names", nil, http.StatusUnauthorized},
+		{"wrong bearer token", "secret", "/patterns/names", map[string]string{"Authorization": "Bearer guess"}, http.StatusUnauthorized},
+		{"non-bearer scheme", "secret", "/patterns/names", map[string]string{"Authorization": "Basic secret"}, http.StatusUnauthorized},
+		{"health is public", "secret", HealthPath, nil, http.StatusOK},
+		{"auth disabled", "", "/patterns/names", nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			newAuthTestRouter(tt.apiKey).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want %q", w.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
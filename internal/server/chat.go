@@ -1,6 +1,7 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -39,11 +40,24 @@ type ChatRequest struct {
 	domain.ChatOptions                 // Embed the ChatOptions from common package
 }
 
+// PatternStreamRequest runs a single pattern against Input.
+type PatternStreamRequest struct {
+	Pattern            string             `json:"pattern"`
+	Vendor             string             `json:"vendor,omitempty"`
+	Model              string             `json:"model"`
+	Input              string             `json:"input"`
+	Variables          map[string]string  `json:"variables,omitempty"`
+	Language           string             `json:"language,omitempty"`
+	ModelContextLength int                `json:"modelContextLength,omitempty"`
+	Options            domain.ChatOptions `json:"options"`
+}
+
 type StreamResponse struct {
-	Type    string                `json:"type"`             // "content", "usage", "error", "complete"
-	Format  string                `json:"format,omitempty"` // "markdown", "mermaid", "plain"
-	Content string                `json:"content,omitempty"`
-	Usage   *domain.UsageMetadata `json:"usage,omitempty"`
+	Type       string                     `json:"type"`             // "content", "usage", "error", "file_change", "complete"
+	Format     string                     `json:"format,omitempty"` // "markdown", "mermaid", "plain"
+	Content    string                     `json:"content,omitempty"`
+	Usage      *domain.UsageMetadata      `json:"usage,omitempty"`
+	FileChange *domain.FileChangeProgress `json:"file_change,omitempty"`
 }
 
 func NewChatHandler(r *gin.Engine, registry *core.PluginRegistry, db *fsdb.Db) *ChatHandler {
@@ -53,6 +67,7 @@ func NewChatHandler(r *gin.Engine, registry *core.PluginRegistry, db *fsdb.Db) *
 	}
 
 	r.POST("/chat", handler.HandleChat)
+	r.POST("/chat/stream", handler.HandleChatStream)
 
 	return handler
 }
@@ -82,108 +97,164 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	log.Printf("Received chat request - Language: '%s', Prompts: %d", request.Language, len(request.Prompts))
 
 	// Set headers for SSE
-	c.Writer.Header().Set("Content-Type", "text/readystream")
-	c.Writer.Header().Set("Cache-Control", "no-cache")
-	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-	c.Writer.Header().Set("X-Accel-Buffering", "no")
-
-	clientGone := c.Writer.CloseNotify()
+	setSSEHeaders(c)
 
 	for i, prompt := range request.Prompts {
+		log.Printf("Processing prompt %d: Model=%s Pattern=%s Context=%s",
+			i+1, prompt.Model, prompt.PatternName, prompt.ContextName)
+
+		if !h.streamPrompt(c, prompt, request.ModelContextLength, request.Language, request.ChatOptions) {
+			return
+		}
+	}
+}
+
+// HandleChatStream godoc
+// @Summary Run a pattern and stream the result
+// @Description Run a single pattern against the given input and stream the response using Server-Sent Events (SSE)
+// @Tags chat
+// @Accept json
+// @Produce text/event-stream
+// @Param request body PatternStreamRequest true "Pattern, model, input and options"
+// @Success 200 {object} StreamResponse "Streaming response"
+// @Failure 400 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /chat/stream [post]
+func (h *ChatHandler) HandleChatStream(c *gin.Context) {
+	var request PatternStreamRequest
+
+	if err := c.BindJSON(&request); err != nil {
+		log.Printf("Error binding JSON: %v", err)
+		c.Writer.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_invalid_request_format"), err)})
+		return
+	}
+
+	setSSEHeaders(c)
+
+	prompt := PromptRequest{
+		UserInput:   request.Input,
+		Vendor:      request.Vendor,
+		Model:       request.Model,
+		PatternName: request.Pattern,
+		Variables:   request.Variables,
+	}
+	h.streamPrompt(c, prompt, request.ModelContextLength, request.Language, request.Options)
+}
+
+// streamPrompt runs a single prompt and writes its stream updates as SSE
+// events, followed by a "complete" event. The vendor call is canceled when the
+// client disconnects. It reports false when the client is gone or a write
+// failed, in which case nothing more should be written.
+func (h *ChatHandler) streamPrompt(c *gin.Context, p PromptRequest, modelContextLength int, language string, options domain.ChatOptions) bool {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+
+	streamChan := make(chan domain.StreamUpdate)
+	// failure is set before streamChan is closed, so it is safe to read once
+	// the channel is drained
+	var failure string
+	go func() {
+		defer close(streamChan)
+
+		chatter, err := h.registry.GetChatter(p.Model, modelContextLength, p.Vendor, true, false)
+		if err != nil {
+			log.Printf("Error creating chatter: %v", err)
+			failure = fmt.Sprintf(i18n.T("server_chat_error"), err)
+			return
+		}
+
+		opts := options
+		opts.Model = p.Model
+		opts.UpdateChan = streamChan
+		opts.Quiet = true
+		// Options come from the client, so nothing may write files or run
+		// commands on the server
+		opts.ImageFile = ""
+		opts.DumpRawFile = ""
+		opts.Notification = false
+		opts.NotificationCommand = ""
+
+		if _, err = chatter.Send(ctx, buildPromptChatRequest(p, language), &opts); err != nil {
+			log.Printf("Error from chatter.Send: %v", err)
+			failure = err.Error()
+		}
+	}()
+	// Drain the channel so the goroutine above can exit once the context is canceled.
+	defer func() {
+		cancel()
+		for range streamChan {
+		}
+	}()
+
+	clientGone := ctx.Done()
+	vendorErrorSent := false
+	for update := range streamChan {
 		select {
 		case <-clientGone:
 			log.Printf("Client disconnected")
-			return
+			return false
 		default:
-			log.Printf("Processing prompt %d: Model=%s Pattern=%s Context=%s",
-				i+1, prompt.Model, prompt.PatternName, prompt.ContextName)
-
-			streamChan := make(chan domain.StreamUpdate)
-
-			go func(p PromptRequest) {
-				defer close(streamChan)
-
-				chatter, err := h.registry.GetChatter(
-					p.Model,
-					request.ModelContextLength,
-					p.Vendor,
-					true,
-					false,
-				)
-				if err != nil {
-					log.Printf("Error creating chatter: %v", err)
-					streamChan <- domain.StreamUpdate{Type: domain.StreamTypeError, Content: fmt.Sprintf(i18n.T("server_chat_error"), err)}
-					return
-				}
-
-				chatReq := buildPromptChatRequest(p, request.Language)
-
-				opts := &domain.ChatOptions{
-					Model:            p.Model,
-					Temperature:      request.Temperature,
-					TopP:             request.TopP,
-					FrequencyPenalty: request.FrequencyPenalty,
-					PresencePenalty:  request.PresencePenalty,
-					Thinking:         request.Thinking,
-					Search:           request.Search,
-					SearchLocation:   request.SearchLocation,
-					UpdateChan:       streamChan,
-					Quiet:            true,
-				}
-
-				_, err = chatter.Send(c.Request.Context(), chatReq, opts)
-				if err != nil {
-					log.Printf("Error from chatter.Send: %v", err)
-					// Error already sent to streamChan via domain.StreamTypeError if occurred in Send loop
-					return
-				}
-			}(prompt)
-
-			for update := range streamChan {
-				select {
-				case <-clientGone:
-					return
-				default:
-					var response StreamResponse
-					switch update.Type {
-					case domain.StreamTypeContent:
-						response = StreamResponse{
-							Type:    "content",
-							Format:  detectFormat(update.Content),
-							Content: update.Content,
-						}
-					case domain.StreamTypeUsage:
-						response = StreamResponse{
-							Type:  "usage",
-							Usage: update.Usage,
-						}
-					case domain.StreamTypeError:
-						response = StreamResponse{
-							Type:    "error",
-							Format:  "plain",
-							Content: update.Content,
-						}
-					}
-
-					if err := writeSSEResponse(c.Writer, response); err != nil {
-						log.Printf("Error writing response: %v", err)
-						return
-					}
-				}
-			}
+		}
 
-			completeResponse := StreamResponse{
-				Type:    "complete",
+		var response StreamResponse
+		switch update.Type {
+		case domain.StreamTypeContent:
+			response = StreamResponse{
+				Type:    "content",
+				Format:  detectFormat(update.Content),
+				Content: update.Content,
+			}
+		case domain.StreamTypeUsage:
+			response = StreamResponse{
+				Type:  "usage",
+				Usage: update.Usage,
+			}
+		case domain.StreamTypeError:
+			vendorErrorSent = true
+			response = StreamResponse{
+				Type:    "error",
 				Format:  "plain",
-				Content: "",
+				Content: update.Content,
 			}
-			if err := writeSSEResponse(c.Writer, completeResponse); err != nil {
-				log.Printf("Error writing completion response: %v", err)
-				return
+		case domain.StreamTypeFileChange:
+			response = StreamResponse{
+				Type:       "file_change",
+				FileChange: update.FileChange,
 			}
 		}
+
+		if err := writeSSEResponse(c.Writer, response); err != nil {
+			log.Printf("Error writing response: %v", err)
+			return false
+		}
+	}
+
+	// An error the vendor already streamed is not reported a second time
+	if failure != "" && !vendorErrorSent {
+		if err := writeSSEResponse(c.Writer, StreamResponse{Type: "error", Format: "plain", Content: failure}); err != nil {
+			log.Printf("Error writing response: %v", err)
+			return false
+		}
+	}
+
+	completeResponse := StreamResponse{
+		Type:    "complete",
+		Format:  "plain",
+		Content: "",
 	}
+	if err := writeSSEResponse(c.Writer, completeResponse); err != nil {
+		log.Printf("Error writing completion response: %v", err)
+		return false
+	}
+	return true
+}
+
+func setSSEHeaders(c *gin.Context) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
 }
 
 func buildPromptChatRequest(p PromptRequest, language string) *domain.ChatRequest {
@@ -1,6 +1,27 @@
 package restapi
 
-import "testing"
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools"
+	"github.com/gin-gonic/gin"
+)
 
 func TestBuildPromptChatRequest_PreservesStrategyAndUserInput(t *testing.T) {
 	prompt := PromptRequest{
@@ -43,3 +64,173 @@ func TestBuildPromptChatRequest_PreservesStrategyAndUserInput(t *testing.T) {
 		t.Fatalf("expected variables to be preserved, got %q", got)
 	}
 }
+
+// streamVendor streams chunks and then fails with err, if set.
+type streamVendor struct {
+	chunks   []string
+	err      error
+	messages []*chat.ChatCompletionMessage
+	opts     *domain.ChatOptions
+}
+
+func (v *streamVendor) GetName() string                       { return "Stub" }
+func (v *streamVendor) GetSetupDescription() string           { return "Stub" }
+func (v *streamVendor) IsConfigured() bool                    { return true }
+func (v *streamVendor) Configure() error                      { return nil }
+func (v *streamVendor) Setup() error                          { return nil }
+func (v *streamVendor) SetupFillEnvFileContent(*bytes.Buffer) {}
+func (v *streamVendor) ListModels(context.Context) ([]string, error) {
+	return []string{"stub-model"}, nil
+}
+func (v *streamVendor) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, updates chan domain.StreamUpdate) error {
+	defer close(updates)
+	v.messages = msgs
+	v.opts = opts
+	for _, chunk := range v.chunks {
+		updates <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: chunk}
+	}
+	return v.err
+}
+func (v *streamVendor) Send(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+	return strings.Join(v.chunks, ""), v.err
+}
+func (v *streamVendor) NeedsRawMode(string) bool { return false }
+
+func newChatStreamServer(t *testing.T, vendor ai.Vendor) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := fsdb.NewDb(t.TempDir())
+	patternFile := filepath.Join(db.Patterns.Dir, "summarize", db.Patterns.SystemPatternFile)
+	if err := os.MkdirAll(filepath.Dir(patternFile), 0755); err != nil {
+		t.Fatalf("failed to create pattern dir: %v", err)
+	}
+	if err := os.WriteFile(patternFile, []byte("Summarize the input."), 0644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+
+	vm := ai.NewVendorsManager()
+	vm.AddVendors(vendor)
+	registry := &core.PluginRegistry{
+		Db:            db,
+		VendorManager: vm,
+		Defaults: &tools.Defaults{
+			PluginBase:         &plugins.PluginBase{},
+			Vendor:             &plugins.Setting{Value: "Stub"},
+			Model:              &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "stub-model"}},
+			ModelContextLength: &plugins.SetupQuestion{Setting: &plugins.Setting{Value: "0"}},
+		},
+	}
+
+	r := gin.New()
+	NewChatHandler(r, registry, db)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func readSSEEvents(t *testing.T, server *httptest.Server, body string) []StreamResponse {
+	t.Helper()
+	resp, err := http.Post(server.URL+"/chat/stream", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected Content-Type text/event-stream, got %q", got)
+	}
+
+	var events []StreamResponse
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event StreamResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("invalid SSE payload %q: %v", data, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read SSE stream: %v", err)
+	}
+	return events
+}
+
+func TestHandleChatStream_StreamsPatternResult(t *testing.T) {
+	vendor := &streamVendor{chunks: []string{"Hello", ", ", "world"}}
+	server := newChatStreamServer(t, vendor)
+
+	events := readSSEEvents(t, server, `{"pattern":"summarize","model":"stub-model","input":"some text","options":{"Temperature":0.7}}`)
+
+	var content strings.Builder
+	for _, event := range events {
+		switch event.Type {
+		case "content":
+			content.WriteString(event.Content)
+		case "error":
+			t.Fatalf("unexpected error event: %q", event.Content)
+		}
+	}
+	if got := content.String(); got != "Hello, world" {
+		t.Fatalf("expected concatenated content %q, got %q", "Hello, world", got)
+	}
+	if last := events[len(events)-1]; last.Type != "complete" {
+		t.Fatalf("expected stream to end with a complete event, got %q", last.Type)
+	}
+
+	var sent strings.Builder
+	for _, msg := range vendor.messages {
+		sent.WriteString(msg.Content)
+	}
+	if !strings.Contains(sent.String(), "Summarize the input.") || !strings.Contains(sent.String(), "some text") {
+		t.Fatalf("expected pattern and input to reach the vendor, got %q", sent.String())
+	}
+}
+
+func TestHandleChatStream_ReportsVendorError(t *testing.T) {
+	server := newChatStreamServer(t, &streamVendor{chunks: []string{"partial"}, err: errors.New("upstream unavailable")})
+
+	events := readSSEEvents(t, server, `{"pattern":"summarize","model":"stub-model","input":"some text"}`)
+
+	var errorEvents []string
+	for _, event := range events {
+		if event.Type == "error" {
+			errorEvents = append(errorEvents, event.Content)
+		}
+	}
+	if len(errorEvents) != 1 || !strings.Contains(errorEvents[0], "upstream unavailable") {
+		t.Fatalf("expected a single error event for the vendor failure, got %q", errorEvents)
+	}
+	if last := events[len(events)-1]; last.Type != "complete" {
+		t.Fatalf("expected stream to end with a complete event, got %q", last.Type)
+	}
+}
+
+func TestHandleChatStream_PassesChatOptions(t *testing.T) {
+	vendor := &streamVendor{chunks: []string{"ok"}}
+	server := newChatStreamServer(t, vendor)
+
+	readSSEEvents(t, server, `{"pattern":"summarize","model":"stub-model","input":"some text","options":{`+
+		`"Model":"ignored","Seed":7,"StopSequences":["END"],"MaxTokens":64,"LogitBias":{"50256":-100},"SearchDomains":["arxiv.org"],`+
+		`"NotificationCommand":"touch /tmp/pwned","DumpRawFile":"/tmp/raw.json","ImageFile":"/tmp/image.png"}}`)
+
+	opts := vendor.opts
+	if opts == nil {
+		t.Fatal("expected the vendor to receive options")
+	}
+	if opts.Model != "stub-model" || opts.Seed == nil || *opts.Seed != 7 || opts.MaxTokens != 64 {
+		t.Errorf("expected model, seed and max tokens to be passed through, got %+v", opts)
+	}
+	if len(opts.StopSequences) != 1 || opts.StopSequences[0] != "END" || opts.LogitBias["50256"] != -100 || len(opts.SearchDomains) != 1 {
+		t.Errorf("expected stop sequences, logit bias and search domains to be passed through, got %+v", opts)
+	}
+	if opts.NotificationCommand != "" || opts.DumpRawFile != "" || opts.ImageFile != "" {
+		t.Errorf("expected server-side file and command options to be cleared, got %+v", opts)
+	}
+}
@@ -1,6 +1,8 @@
 package restapi
 
 import (
+	"net/http"
+
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/gin-gonic/gin"
 )
@@ -13,7 +15,55 @@ type ContextsHandler struct {
 
 // NewContextsHandler creates a new ContextsHandler
 func NewContextsHandler(r *gin.Engine, contexts *fsdb.ContextsEntity) (ret *ContextsHandler) {
-	ret = &ContextsHandler{
-		StorageHandler: NewStorageHandler(r, "contexts", contexts), contexts: contexts}
+	storageHandler := &StorageHandler[fsdb.Context]{storage: contexts}
+	ret = &ContextsHandler{StorageHandler: storageHandler, contexts: contexts}
+
+	// Register routes manually - use custom list and Get for contexts, others from StorageHandler
+	r.GET("/contexts", ret.List)                            // Custom method listing context names
+	r.GET("/contexts/:name", ret.Get)                       // Custom method returning 404 for missing contexts
+	r.GET("/contexts/names", ret.GetNames)                  // From StorageHandler
+	r.DELETE("/contexts/:name", ret.Delete)                 // From StorageHandler
+	r.GET("/contexts/exists/:name", ret.Exists)             // From StorageHandler
+	r.PUT("/contexts/rename/:oldName/:newName", ret.Rename) // From StorageHandler
+	r.POST("/contexts/:name", ret.Save)                     // From StorageHandler
 	return
 }
+
+// List handles the GET /contexts route
+// @Summary List contexts
+// @Description Retrieve the names of all available contexts
+// @Tags contexts
+// @Produce json
+// @Success 200 {array} string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /contexts [get]
+func (h *ContextsHandler) List(c *gin.Context) {
+	h.GetNames(c)
+}
+
+// Get handles the GET /contexts/:name route
+// @Summary Get a context
+// @Description Retrieve a context and its content by name
+// @Tags contexts
+// @Produce json
+// @Param name path string true "Context name"
+// @Success 200 {object} fsdb.Context
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /contexts/{name} [get]
+func (h *ContextsHandler) Get(c *gin.Context) {
+	name := c.Param("name")
+	if !h.contexts.Exists(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "context not found: " + name})
+		return
+	}
+
+	context, err := h.contexts.Get(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, context)
+}
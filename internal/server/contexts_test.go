@@ -0,0 +1,84 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+func setupContextsRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Contexts.Dir, 0755); err != nil {
+		t.Fatalf("failed to create contexts dir: %v", err)
+	}
+	for name, content := range map[string]string{"writer": "Write clearly.", "reviewer": "Review carefully."} {
+		if err := os.WriteFile(filepath.Join(db.Contexts.Dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write context: %v", err)
+		}
+	}
+
+	r := gin.New()
+	NewContextsHandler(r, db.Contexts)
+	return r
+}
+
+func TestContextsHandler_List(t *testing.T) {
+	r := setupContextsRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/contexts", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var names []string
+	if err := json.Unmarshal(w.Body.Bytes(), &names); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected 2 context names, got %v", names)
+	}
+	for _, want := range []string{"reviewer", "writer"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("expected %q in context names %v", want, names)
+		}
+	}
+}
+
+func TestContextsHandler_Get(t *testing.T) {
+	r := setupContextsRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/contexts/writer", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var context fsdb.Context
+	if err := json.Unmarshal(w.Body.Bytes(), &context); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if context.Name != "writer" || context.Content != "Write clearly." {
+		t.Errorf("unexpected context %+v", context)
+	}
+}
+
+func TestContextsHandler_GetMissing(t *testing.T) {
+	r := setupContextsRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/contexts/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,93 @@
+package restapi
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID that ties a request to its log lines. A
+// well-formed client-supplied value is reused; otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// maxLoggedBodySize caps how much of a request or response body is logged.
+const maxLoggedBodySize = 2048
+
+// requestIDPattern keeps client-supplied request IDs from injecting log lines.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// sensitiveFieldPattern matches JSON string fields that hold credentials.
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)("[^"]*(?:api[_-]?key|subscription[_-]?key|token|secret|password)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// bodyLogWriter copies the first maxLoggedBodySize bytes of a response.
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	if remaining := maxLoggedBodySize + 1 - w.body.Len(); remaining > 0 {
+		w.body.Write(data[:min(len(data), remaining)])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// RequestLoggingMiddleware logs the method, path, status, latency and request
+// ID of every call. With logBodies set and debug level Detailed or higher, the
+// truncated request and response bodies of the pattern endpoints are logged as
+// well, with credential fields redacted.
+func RequestLoggingMiddleware(logBodies bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = rand.Text()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		path := c.Request.URL.Path
+		captureBodies := logBodies && debuglog.GetLevel() >= debuglog.Detailed && strings.HasPrefix(path, "/patterns/")
+		var requestBody []byte
+		var writer *bodyLogWriter
+		if captureBodies {
+			if c.Request.Body != nil {
+				requestBody, _ = io.ReadAll(c.Request.Body)
+				c.Request.Body = io.NopCloser(bytes.NewReader(requestBody))
+			}
+			writer = &bodyLogWriter{ResponseWriter: c.Writer}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		debuglog.Log("[REST] request_id=%s method=%s path=%s status=%d latency=%s\n",
+			requestID, c.Request.Method, path, c.Writer.Status(), time.Since(start))
+		if captureBodies {
+			debuglog.Debug(debuglog.Detailed, "[REST] request_id=%s request_body=%s\n", requestID, loggableBody(requestBody))
+			debuglog.Debug(debuglog.Detailed, "[REST] request_id=%s response_body=%s\n", requestID, loggableBody(writer.body.Bytes()))
+		}
+	}
+}
+
+// loggableBody redacts credential fields and truncates body for logging.
+func loggableBody(body []byte) string {
+	truncated := len(body) > maxLoggedBodySize
+	if truncated {
+		body = body[:maxLoggedBodySize]
+	}
+	ret := sensitiveFieldPattern.ReplaceAllString(string(body), `$1"[REDACTED]"`)
+	if truncated {
+		ret += "...(truncated)"
+	}
+	return ret
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLoggingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	debuglog.SetOutput(&output)
+	defer debuglog.SetOutput(os.Stderr)
+
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(false))
+	r.GET("/models/names", func(c *gin.Context) {
+		c.JSON(http.StatusTeapot, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/models/names", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("%s = %q, want %q", RequestIDHeader, got, "req-123")
+	}
+	line := regexp.MustCompile(`\[REST\] request_id=req-123 method=GET path=/models/names status=418 latency=\S+\n`)
+	if !line.MatchString(output.String()) {
+		t.Errorf("log output %q does not contain the expected request line", output.String())
+	}
+
+	output.Reset()
+	req = httptest.NewRequest(http.MethodGet, "/models/names", nil)
+	req.Header.Set(RequestIDHeader, "bad id\ninjected")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIDHeader); got == "" || strings.Contains(got, "injected") {
+		t.Errorf("expected a generated request ID, got %q", got)
+	}
+}
+
+func TestRequestLoggingMiddlewareLogsRedactedPatternBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	debuglog.SetOutput(&output)
+	debuglog.SetLevel(debuglog.Detailed)
+	defer func() {
+		debuglog.SetOutput(os.Stderr)
+		debuglog.SetLevel(debuglog.Off)
+	}()
+
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(true))
+	r.POST("/patterns/:name", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("x", maxLoggedBodySize+10))
+	})
+
+	body := `{"pattern":"summarize","api_key":"sk-secret","SubscriptionKey":"sub-secret"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/patterns/test", strings.NewReader(body)))
+
+	logged := output.String()
+	if strings.Contains(logged, "sk-secret") || strings.Contains(logged, "sub-secret") {
+		t.Errorf("log output leaked a key: %q", logged)
+	}
+	if !strings.Contains(logged, `request_body={"pattern":"summarize","api_key":"[REDACTED]","SubscriptionKey":"[REDACTED]"}`) {
+		t.Errorf("log output %q does not contain the redacted request body", logged)
+	}
+	if !strings.Contains(logged, "...(truncated)") {
+		t.Errorf("expected the response body to be truncated in %q", logged)
+	}
+	if w.Body.Len() != maxLoggedBodySize+10 {
+		t.Errorf("response body length = %d, want %d", w.Body.Len(), maxLoggedBodySize+10)
+	}
+}
@@ -188,12 +188,18 @@ func parseOllamaNumCtx(options map[string]any) (int, error) {
 	return contextLength, nil
 }
 
-func ServeOllama(registry *core.PluginRegistry, address string, version string) (err error) {
+func ServeOllama(registry *core.PluginRegistry, address string, version string, rateLimit int) (err error) {
 	r := gin.New()
+	// Forwarding headers can be forged by any client, so only the TCP peer
+	// address identifies a client
+	if err = r.SetTrustedProxies(nil); err != nil {
+		return
+	}
 
 	// Middleware
-	r.Use(gin.Logger())
+	r.Use(RequestLoggingMiddleware(false))
 	r.Use(gin.Recovery())
+	r.Use(RateLimitMiddleware(rateLimit))
 
 	// Register routes
 	fabricDb := registry.Db
@@ -1,10 +1,12 @@
 package restapi
 
 import (
+	"errors"
 	"maps"
 	"net/http"
 
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/plugins/template"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,6 +31,7 @@ func NewPatternsHandler(r *gin.Engine, patterns *fsdb.PatternsEntity) (ret *Patt
 	r.POST("/patterns/:name", ret.Save)                     // From StorageHandler
 	// Add POST route for patterns with variables in request body
 	r.POST("/patterns/:name/apply", ret.ApplyPattern)
+	r.POST("/patterns/:name/render", ret.RenderPattern)
 	return
 }
 
@@ -82,19 +85,65 @@ func (h *PatternsHandler) ApplyPattern(c *gin.Context) {
 		return
 	}
 
-	// Merge query parameters with request body variables (body takes precedence)
+	pattern, err := h.patterns.GetApplyVariables(name, requestVariables(c, request.Variables), request.Input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, pattern)
+}
+
+// PatternRenderResponse holds a pattern's system prompt with its variables and
+// input applied
+type PatternRenderResponse struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+// RenderPattern handles the POST /patterns/:name/render route
+// @Summary Render a pattern
+// @Description Preview the system prompt a pattern produces with the given variables and input, without calling a model
+// @Tags patterns
+// @Accept json
+// @Produce json
+// @Param name path string true "Pattern name"
+// @Param request body PatternApplyRequest true "Pattern variables and input"
+// @Success 200 {object} PatternRenderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /patterns/{name}/render [post]
+func (h *PatternsHandler) RenderPattern(c *gin.Context) {
+	name := c.Param("name")
+
+	var request PatternApplyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pattern, err := h.patterns.GetApplyVariables(name, requestVariables(c, request.Variables), request.Input)
+	if err != nil {
+		var missing *template.MissingVariableError
+		if errors.As(err, &missing) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "variable": missing.Name})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, PatternRenderResponse{Name: name, Prompt: pattern.Pattern})
+}
+
+// requestVariables merges query parameters with request body variables (body
+// takes precedence)
+func requestVariables(c *gin.Context, bodyVariables map[string]string) map[string]string {
 	variables := make(map[string]string)
 	for key, values := range c.Request.URL.Query() {
 		if len(values) > 0 {
 			variables[key] = values[0]
 		}
 	}
-	maps.Copy(variables, request.Variables)
-
-	pattern, err := h.patterns.GetApplyVariables(name, variables, request.Input)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
-		return
-	}
-	c.JSON(http.StatusOK, pattern)
+	maps.Copy(variables, bodyVariables)
+	return variables
 }
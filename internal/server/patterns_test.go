@@ -0,0 +1,128 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPatternsHandler_ServesEditedPatternWithoutRestart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := fsdb.NewDb(t.TempDir())
+	patternFile := filepath.Join(db.Patterns.Dir, "summarize", db.Patterns.SystemPatternFile)
+	if err := os.MkdirAll(filepath.Dir(patternFile), 0755); err != nil {
+		t.Fatalf("failed to create pattern dir: %v", err)
+	}
+	writePattern := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(patternFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write pattern: %v", err)
+		}
+	}
+
+	r := gin.New()
+	NewPatternsHandler(r, db.Patterns)
+
+	getPattern := func() string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/patterns/summarize", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var pattern fsdb.Pattern
+		if err := json.Unmarshal(w.Body.Bytes(), &pattern); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return pattern.Pattern
+	}
+
+	applyPattern := func() string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/patterns/summarize/apply", strings.NewReader(`{"input":"text"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var pattern fsdb.Pattern
+		if err := json.Unmarshal(w.Body.Bytes(), &pattern); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return pattern.Pattern
+	}
+
+	writePattern("original instructions")
+	if got := getPattern(); got != "original instructions" {
+		t.Fatalf("expected original pattern, got %q", got)
+	}
+	if got := applyPattern(); !strings.Contains(got, "original instructions") {
+		t.Fatalf("expected applied pattern to contain original instructions, got %q", got)
+	}
+
+	writePattern("edited instructions")
+	if got := getPattern(); got != "edited instructions" {
+		t.Fatalf("expected edited pattern after change on disk, got %q", got)
+	}
+	if got := applyPattern(); !strings.Contains(got, "edited instructions") || strings.Contains(got, "original") {
+		t.Fatalf("expected applied pattern to reflect edit, got %q", got)
+	}
+}
+
+func TestPatternsHandler_RenderPattern(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := fsdb.NewDb(t.TempDir())
+	patternFile := filepath.Join(db.Patterns.Dir, "greet", db.Patterns.SystemPatternFile)
+	if err := os.MkdirAll(filepath.Dir(patternFile), 0755); err != nil {
+		t.Fatalf("failed to create pattern dir: %v", err)
+	}
+	if err := os.WriteFile(patternFile, []byte("Greet {{name}} in a {{tone:friendly}} tone.\n{{input}}"), 0644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+
+	r := gin.New()
+	NewPatternsHandler(r, db.Patterns)
+
+	render := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/patterns/greet/render", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := render(`{"input":"Hello there","variables":{"name":"Ada"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rendered PatternRenderResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &rendered); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if want := "Greet Ada in a friendly tone.\nHello there"; rendered.Prompt != want || rendered.Name != "greet" {
+		t.Errorf("rendered = %+v, want prompt %q", rendered, want)
+	}
+
+	w = render(`{"input":"Hello there"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var failure map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &failure); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if failure["variable"] != "name" || !strings.Contains(failure["error"], "name") {
+		t.Errorf("expected missing variable 'name', got %v", failure)
+	}
+}
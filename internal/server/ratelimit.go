@@ -0,0 +1,90 @@
+package restapi
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiterIdleTTL is how long an idle client's bucket is kept.
+const rateLimiterIdleTTL = 10 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipRateLimiter keeps one token bucket per client IP.
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	limit     rate.Limit
+	burst     int
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newIPRateLimiter(requestsPerMinute int) *ipRateLimiter {
+	return &ipRateLimiter{
+		clients: make(map[string]*clientLimiter),
+		limit:   rate.Limit(float64(requestsPerMinute) / 60),
+		burst:   requestsPerMinute,
+		now:     time.Now,
+	}
+}
+
+// reserve takes a token for ip and returns how long the client has to wait
+// before the request would be allowed; zero means it is allowed now.
+func (l *ipRateLimiter) reserve(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > rateLimiterIdleTTL {
+		for key, client := range l.clients {
+			if now.Sub(client.lastSeen) > rateLimiterIdleTTL {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+
+	reservation := client.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay
+	}
+	return 0
+}
+
+// RateLimitMiddleware allows each client IP requestsPerMinute requests per
+// minute, with bursts of the same size. Requests over the limit get a 429 with
+// a Retry-After header. A limit of zero or less disables rate limiting.
+// Clients are keyed on the connection's remote address rather than
+// forwarding headers, which any client can forge to get a fresh bucket.
+func RateLimitMiddleware(requestsPerMinute int) gin.HandlerFunc {
+	if requestsPerMinute <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limiter := newIPRateLimiter(requestsPerMinute)
+	return func(c *gin.Context) {
+		if delay := limiter.reserve(c.RemoteIP()); delay > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
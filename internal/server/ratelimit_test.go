@@ -0,0 +1,103 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitMiddlewareRejectsRapidRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimitMiddleware(2))
+	r.GET("/patterns/names", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/patterns/names", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := range 2 {
+		if w := request("192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+	w := request("192.0.2.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want %q", got, "30")
+	}
+
+	// Other clients have their own bucket
+	if w := request("192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("status for another client = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitMiddlewareIgnoresForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimitMiddleware(1))
+	r.GET("/patterns/names", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/patterns/names", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("198.51.100.1"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := request("198.51.100.2"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status with a spoofed X-Forwarded-For = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestIPRateLimiterAllowsSlowRequests(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newIPRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 10 {
+		if delay := limiter.reserve("192.0.2.1"); delay != 0 {
+			t.Fatalf("request %d: delay = %v, want 0", i+1, delay)
+		}
+		now = now.Add(30 * time.Second)
+	}
+}
+
+func TestRateLimitMiddlewareDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimitMiddleware(0))
+	r.GET("/patterns/names", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := range 10 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/patterns/names", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+}
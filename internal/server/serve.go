@@ -26,12 +26,18 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name X-API-Key
-func Serve(registry *core.PluginRegistry, address string, apiKey string) (err error) {
+func Serve(registry *core.PluginRegistry, address string, apiKey string, logBodies bool, rateLimit int) (err error) {
 	r := gin.New()
+	// Forwarding headers can be forged by any client, so only the TCP peer
+	// address identifies a client
+	if err = r.SetTrustedProxies(nil); err != nil {
+		return
+	}
 
 	// Middleware
-	r.Use(gin.Logger())
+	r.Use(RequestLoggingMiddleware(logBodies))
 	r.Use(gin.Recovery())
+	r.Use(RateLimitMiddleware(rateLimit))
 
 	if apiKey != "" {
 		r.Use(APIKeyMiddleware(apiKey))
@@ -66,6 +72,7 @@ func Serve(registry *core.PluginRegistry, address string, apiKey string) (err er
 	})
 
 	// Register routes
+	r.GET(HealthPath, Health)
 	fabricDb := registry.Db
 	NewPatternsHandler(r, fabricDb.Patterns)
 	NewContextsHandler(r, fabricDb.Contexts)
@@ -74,6 +81,7 @@ func Serve(registry *core.PluginRegistry, address string, apiKey string) (err er
 	NewYouTubeHandler(r, registry)
 	NewConfigHandler(r, fabricDb)
 	NewModelsHandler(r, registry.VendorManager)
+	NewVendorsHandler(r, registry)
 	NewStrategiesHandler(r)
 
 	// Start server
@@ -84,3 +92,17 @@ func Serve(registry *core.PluginRegistry, address string, apiKey string) (err er
 
 	return
 }
+
+// HealthPath is the liveness endpoint, which never requires an API key.
+const HealthPath = "/health"
+
+// Health godoc
+// @Summary Health check
+// @Description Report that the server is running. This endpoint does not require an API key.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /health [get]
+func Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,136 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultVendorHealthTimeout bounds each vendor check unless the request
+	// asks for a different timeout.
+	defaultVendorHealthTimeout = 5 * time.Second
+	maxVendorHealthTimeout     = 30 * time.Second
+)
+
+const (
+	VendorStatusOK           = "ok"
+	VendorStatusUnconfigured = "unconfigured"
+	VendorStatusError        = "error"
+)
+
+type VendorsHandler struct {
+	registry *core.PluginRegistry
+}
+
+// VendorHealth reports whether a vendor answered a model listing request.
+type VendorHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // "ok", "unconfigured", "error"
+	Error     string `json:"error,omitempty"`
+	ElapsedMs int64  `json:"elapsedMs"`
+}
+
+type VendorsHealthResponse struct {
+	Vendors []VendorHealth `json:"vendors"`
+}
+
+func NewVendorsHandler(r *gin.Engine, registry *core.PluginRegistry) {
+	handler := &VendorsHandler{
+		registry: registry,
+	}
+
+	r.GET("/vendors/health", handler.GetVendorsHealth)
+}
+
+// GetVendorsHealth godoc
+// @Summary Check AI vendor health
+// @Description List models from every configured vendor concurrently and report per-vendor status (ok, unconfigured or error) with the elapsed time
+// @Tags vendors
+// @Produce json
+// @Param timeout query string false "Per-vendor timeout as a Go duration (default 5s, max 30s)"
+// @Success 200 {object} VendorsHealthResponse
+// @Failure 400 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /vendors/health [get]
+func (h *VendorsHandler) GetVendorsHealth(c *gin.Context) {
+	timeout := defaultVendorHealthTimeout
+	if value := c.Query("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxVendorHealthTimeout {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_invalid_vendor_health_timeout"), value, maxVendorHealthTimeout)})
+			return
+		}
+		timeout = parsed
+	}
+
+	c.JSON(http.StatusOK, VendorsHealthResponse{Vendors: h.checkVendors(c.Request.Context(), timeout)})
+}
+
+// checkVendors runs the checks concurrently. Vendors known to the registry but
+// not configured are reported without being contacted.
+func (h *VendorsHandler) checkVendors(ctx context.Context, timeout time.Duration) []VendorHealth {
+	vendors := h.registry.VendorManager.Vendors
+	if h.registry.VendorsAll != nil {
+		vendors = h.registry.VendorsAll.Vendors
+	}
+
+	results := make([]VendorHealth, len(vendors))
+	var wg sync.WaitGroup
+	for i, vendor := range vendors {
+		if h.registry.VendorManager.FindByName(vendor.GetName()) == nil || !vendor.IsConfigured() {
+			results[i] = VendorHealth{Name: vendor.GetName(), Status: VendorStatusUnconfigured}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, vendor ai.Vendor) {
+			defer wg.Done()
+			results[i] = checkVendorHealth(ctx, vendor, timeout)
+		}(i, vendor)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// checkVendorHealth lists the vendor's models, giving up after timeout even if
+// the vendor ignores the context.
+func checkVendorHealth(ctx context.Context, vendor ai.Vendor, timeout time.Duration) VendorHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		_, err := vendor.ListModels(ctx)
+		done <- err
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	health := VendorHealth{Name: vendor.GetName(), Status: VendorStatusOK, ElapsedMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		health.Status = VendorStatusError
+		if errors.Is(err, context.DeadlineExceeded) {
+			health.Error = fmt.Sprintf(i18n.T("server_vendor_health_timeout"), timeout)
+		} else {
+			health.Error = err.Error()
+		}
+	}
+	return health
+}
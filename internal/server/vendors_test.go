@@ -0,0 +1,115 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/gin-gonic/gin"
+)
+
+// healthVendor answers ListModels with err, or blocks until the context ends
+// when block is set.
+type healthVendor struct {
+	name       string
+	configured bool
+	err        error
+	block      bool
+}
+
+func (v *healthVendor) GetName() string                       { return v.name }
+func (v *healthVendor) GetSetupDescription() string           { return v.name }
+func (v *healthVendor) IsConfigured() bool                    { return v.configured }
+func (v *healthVendor) Configure() error                      { return nil }
+func (v *healthVendor) Setup() error                          { return nil }
+func (v *healthVendor) SetupFillEnvFileContent(*bytes.Buffer) {}
+func (v *healthVendor) ListModels(ctx context.Context) ([]string, error) {
+	if v.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return []string{"model"}, v.err
+}
+func (v *healthVendor) SendStream(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions, chan domain.StreamUpdate) error {
+	return nil
+}
+func (v *healthVendor) Send(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+	return "", nil
+}
+func (v *healthVendor) NeedsRawMode(string) bool { return false }
+
+func TestGetVendorsHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	healthy := &healthVendor{name: "Healthy", configured: true}
+	failing := &healthVendor{name: "Failing", configured: true, err: errors.New("invalid API key")}
+	slow := &healthVendor{name: "Slow", configured: true, block: true}
+	unconfigured := &healthVendor{name: "Unconfigured"}
+
+	registry := &core.PluginRegistry{VendorManager: ai.NewVendorsManager(), VendorsAll: ai.NewVendorsManager()}
+	registry.VendorsAll.AddVendors(healthy, failing, slow, unconfigured)
+	registry.VendorManager.AddVendors(healthy, failing, slow)
+
+	r := gin.New()
+	NewVendorsHandler(r, registry)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vendors/health?timeout=50ms", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the timeout to bound the checks, took %s", elapsed)
+	}
+
+	var response VendorsHealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	got := map[string]VendorHealth{}
+	for _, health := range response.Vendors {
+		got[health.Name] = health
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 vendors, got %+v", response.Vendors)
+	}
+
+	if got["Healthy"].Status != VendorStatusOK || got["Healthy"].Error != "" {
+		t.Errorf("Healthy = %+v, want ok", got["Healthy"])
+	}
+	if got["Failing"].Status != VendorStatusError || got["Failing"].Error != "invalid API key" {
+		t.Errorf("Failing = %+v, want error with the vendor message", got["Failing"])
+	}
+	if got["Slow"].Status != VendorStatusError || got["Slow"].ElapsedMs < 50 {
+		t.Errorf("Slow = %+v, want error after the timeout", got["Slow"])
+	}
+	if got["Unconfigured"].Status != VendorStatusUnconfigured {
+		t.Errorf("Unconfigured = %+v, want unconfigured", got["Unconfigured"])
+	}
+}
+
+func TestGetVendorsHealth_InvalidTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := &core.PluginRegistry{VendorManager: ai.NewVendorsManager()}
+	r := gin.New()
+	NewVendorsHandler(r, registry)
+
+	for _, timeout := range []string{"soon", "-1s", "1h"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vendors/health?timeout="+timeout, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("timeout=%s: expected status 400, got %d", timeout, w.Code)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
@@ -304,32 +305,14 @@ func (o *YouTube) readAndCleanVTTFile(filename string) (ret string, err error) {
 		return
 	}
 
-	// Convert VTT to plain text
-	lines := strings.Split(string(content), "\n")
-	var textBuilder strings.Builder
-	seenSegments := make(map[string]struct{})
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Skip WEBVTT header, timestamps, and empty lines
-		if line == "" || line == "WEBVTT" || strings.Contains(line, "-->") ||
-			strings.HasPrefix(line, "NOTE") || strings.HasPrefix(line, "STYLE") ||
-			strings.HasPrefix(line, "Kind:") || strings.HasPrefix(line, "Language:") ||
-			isTimeStamp(line) {
-			continue
-		}
-		// Remove VTT formatting tags
-		line = removeVTTTags(line)
-		if line != "" {
-			if _, exists := seenSegments[line]; !exists {
-				textBuilder.WriteString(line)
-				textBuilder.WriteString(" ")
-				seenSegments[line] = struct{}{}
-			}
-		}
+	// Auto-generated captions roll: each cue repeats the tail of the previous
+	// one, so merge the cues instead of concatenating their lines
+	var cues []domain.SubtitleCue
+	if cues, err = domain.ParseSubtitles(string(content)); err != nil {
+		return
 	}
-
-	ret = strings.TrimSpace(textBuilder.String())
+	ret = domain.CollapseRepeatedFragments(
+		domain.MergeSubtitleCues(cues, domain.DefaultTranscriptOverlapWords), domain.DefaultTranscriptOverlapWords)
 	if ret == "" {
 		err = errors.New(i18n.T("youtube_no_transcript_content"))
 	}
@@ -350,6 +333,7 @@ func (o *YouTube) readAndFormatVTTWithTimestamps(filename string) (ret string, e
 	// This preserves legitimate repeated content (choruses, recurring phrases, etc.)
 	// while still filtering out immediate duplicates from VTT formatting issues
 	seenSegments := make(map[string]string) // text -> last timestamp seen
+	var previousText string
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -380,6 +364,10 @@ func (o *YouTube) readAndFormatVTTWithTimestamps(filename string) (ret string, e
 		if line != "" {
 			// Remove VTT formatting tags
 			cleanText := removeVTTTags(line)
+			// Drop words repeated from the end of the previous caption window
+			trimmedText := domain.TrimCaptionOverlap(previousText, cleanText, domain.DefaultTranscriptOverlapWords)
+			previousText = cleanText
+			cleanText = trimmedText
 			if cleanText != "" && currentTimestamp != "" {
 				// Check if we should include this segment
 				shouldInclude := true
@@ -1,6 +1,8 @@
 package youtube
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -166,3 +168,48 @@ func TestExtractAndValidateVideoId(t *testing.T) {
 		})
 	}
 }
+
+// overlappingCaptionsVTT mimics auto-generated captions, where each cue repeats
+// the end of the previous one while the next words are spoken.
+const overlappingCaptionsVTT = `WEBVTT
+Kind: captions
+Language: en
+
+00:00:00.000 --> 00:00:02.000 align:start position:0%
+Every day you should be in
+
+00:00:02.000 --> 00:00:04.000 align:start position:0%
+Every day you should be in<00:00:02.500><c> the</c><00:00:03.000><c> gym</c>
+
+00:00:04.000 --> 00:00:06.000 align:start position:0%
+should be in the gym working on your form
+
+00:00:06.000 --> 00:00:08.000 align:start position:0%
+no, no, no, not like that
+`
+
+func TestReadVTTCollapsesOverlappingCaptions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "captions.en.vtt")
+	if err := os.WriteFile(filename, []byte(overlappingCaptionsVTT), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yt := NewYouTube()
+
+	got, err := yt.readAndCleanVTTFile(filename)
+	if err != nil {
+		t.Fatalf("readAndCleanVTTFile() error = %v", err)
+	}
+	want := "Every day you should be in the gym working on your form no, no, no, not like that"
+	if got != want {
+		t.Errorf("readAndCleanVTTFile() = %q, want %q", got, want)
+	}
+
+	got, err = yt.readAndFormatVTTWithTimestamps(filename)
+	if err != nil {
+		t.Fatalf("readAndFormatVTTWithTimestamps() error = %v", err)
+	}
+	want = "[00:00:00] Every day you should be in\n[00:00:02] the gym\n[00:00:04] working on your form\n[00:00:06] no, no, no, not like that"
+	if got != want {
+		t.Errorf("readAndFormatVTTWithTimestamps() = %q, want %q", got, want)
+	}
+}